@@ -13,17 +13,23 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/k8sinfoanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/noopanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/processanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpconnectanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpmetricanalyzer"
-	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/cameraexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/kafkaexporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/logexporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/otelexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/remotewriteexporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/aggregateprocessor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/processmetadataprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/relabelprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/tailsamplingprocessor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/controller"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/cgoreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/filereceiver"
 )
 
 type Application struct {
@@ -33,6 +39,7 @@ type Application struct {
 	telemetry         *component.TelemetryManager
 	receiver          receiver.Receiver
 	analyzerManager   *analyzer.Manager
+	pipelines         *Pipelines
 }
 
 func New() (*Application, error) {
@@ -72,11 +79,13 @@ func (a *Application) Run() error {
 }
 
 func (a *Application) Shutdown() error {
-	return multierr.Combine(a.receiver.Shutdown(), a.analyzerManager.ShutdownAll(a.telemetry.GetGlobalTelemetryTools().Logger))
+	logger := a.telemetry.GetGlobalTelemetryTools().Logger
+	return multierr.Combine(a.receiver.Shutdown(), a.analyzerManager.ShutdownAll(logger), a.pipelines.ShutdownConsumers(logger))
 }
 
 func (a *Application) registerFactory() {
 	a.componentsFactory.RegisterReceiver(cgoreceiver.Cgo, cgoreceiver.NewCgoReceiver, cgoreceiver.NewDefaultConfig())
+	a.componentsFactory.RegisterReceiver(filereceiver.Type, filereceiver.New, filereceiver.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(network.Network.String(), network.NewNetworkAnalyzer, network.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(cpuanalyzer.CpuProfile.String(), cpuanalyzer.NewCpuAnalyzer, cpuanalyzer.NewDefaultConfig())
 	a.componentsFactory.RegisterProcessor(k8sprocessor.K8sMetadata, k8sprocessor.NewKubernetesProcessor, &k8sprocessor.DefaultConfig)
@@ -88,6 +97,12 @@ func (a *Application) registerFactory() {
 	a.componentsFactory.RegisterProcessor(aggregateprocessor.Type, aggregateprocessor.New, aggregateprocessor.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(tcpconnectanalyzer.Type.String(), tcpconnectanalyzer.New, tcpconnectanalyzer.NewDefaultConfig())
 	a.componentsFactory.RegisterExporter(cameraexporter.Type, cameraexporter.New, cameraexporter.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(tailsamplingprocessor.Type, tailsamplingprocessor.New, tailsamplingprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(relabelprocessor.Type, relabelprocessor.New, relabelprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterExporter(kafkaexporter.Type, kafkaexporter.New, kafkaexporter.NewDefaultConfig())
+	a.componentsFactory.RegisterExporter(remotewriteexporter.Type, remotewriteexporter.New, remotewriteexporter.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(processmetadataprocessor.Type, processmetadataprocessor.New, processmetadataprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterAnalyzer(processanalyzer.Type.String(), processanalyzer.New, &processanalyzer.Config{})
 }
 
 func (a *Application) readInConfig(path string) error {
@@ -105,53 +120,36 @@ func (a *Application) readInConfig(path string) error {
 	return nil
 }
 
-// buildPipeline builds a event processing pipeline based on hard-code.
+// buildPipeline builds the event processing pipelines declared in the configuration.
 func (a *Application) buildPipeline() error {
-	// TODO: Build pipeline via configuration to implement dependency injection
-	// Initialize exporters
-	otelExporterFactory := a.componentsFactory.Exporters[otelexporter.Otel]
-	otelExporter := otelExporterFactory.NewFunc(otelExporterFactory.Config, a.telemetry.GetTelemetryTools(otelexporter.Otel))
-	cameraExporterFactory := a.componentsFactory.Exporters[cameraexporter.Type]
-	cameraExporter := cameraExporterFactory.NewFunc(cameraExporterFactory.Config, a.telemetry.GetTelemetryTools(cameraexporter.Type))
-	// Initialize all processors
-	// 1. DataGroup Aggregator
-	aggregateProcessorFactory := a.componentsFactory.Processors[aggregateprocessor.Type]
-	aggregateProcessor := aggregateProcessorFactory.NewFunc(aggregateProcessorFactory.Config, a.telemetry.GetTelemetryTools(aggregateprocessor.Type), otelExporter)
-	// 2. Kubernetes metadata processor
-	k8sProcessorFactory := a.componentsFactory.Processors[k8sprocessor.K8sMetadata]
-	k8sMetadataProcessor := k8sProcessorFactory.NewFunc(k8sProcessorFactory.Config, a.telemetry.GetTelemetryTools(k8sprocessor.K8sMetadata), aggregateProcessor)
-	// Initialize all analyzers
-	// 1. Common network request analyzer
-	networkAnalyzerFactory := a.componentsFactory.Analyzers[network.Network.String()]
-	// Now NetworkAnalyzer must be initialized before any other analyzers, because it will
-	// use its configuration to initialize the conntracker module which is also used by others.
-	networkAnalyzer := networkAnalyzerFactory.NewFunc(networkAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(network.Network.String()), []consumer.Consumer{k8sMetadataProcessor})
-	// 2. Layer 4 TCP events analyzer
-	aggregateProcessorForTcp := aggregateProcessorFactory.NewFunc(aggregateProcessorFactory.Config, a.telemetry.GetTelemetryTools(aggregateprocessor.Type), otelExporter)
-	k8sMetadataProcessor2 := k8sProcessorFactory.NewFunc(k8sProcessorFactory.Config, a.telemetry.GetTelemetryTools(aggregateprocessor.Type), aggregateProcessorForTcp)
-	tcpAnalyzerFactory := a.componentsFactory.Analyzers[tcpmetricanalyzer.TcpMetric.String()]
-	tcpAnalyzer := tcpAnalyzerFactory.NewFunc(tcpAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpmetricanalyzer.TcpMetric.String()), []consumer.Consumer{k8sMetadataProcessor2})
-	tcpConnectAnalyzerFactory := a.componentsFactory.Analyzers[tcpconnectanalyzer.Type.String()]
-	tcpConnectAnalyzer := tcpConnectAnalyzerFactory.NewFunc(tcpConnectAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpconnectanalyzer.Type.String()), []consumer.Consumer{k8sMetadataProcessor})
-
-	cpuAnalyzerFactory := a.componentsFactory.Analyzers[cpuanalyzer.CpuProfile.String()]
-	cpuAnalyzer := cpuAnalyzerFactory.NewFunc(cpuAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(cpuanalyzer.CpuProfile.String()), []consumer.Consumer{cameraExporter})
-	k8sInfoAnalyzerFactory := a.componentsFactory.Analyzers[k8sinfoanalyzer.Type.String()]
-	k8sInfoAnalyzer := k8sInfoAnalyzerFactory.NewFunc(k8sInfoAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(k8sinfoanalyzer.Type.String()), []consumer.Consumer{otelExporter})
-	// Initialize receiver packaged with multiple analyzers
-	analyzerManager, err := analyzer.NewManager(networkAnalyzer, tcpAnalyzer, tcpConnectAnalyzer, cpuAnalyzer, k8sInfoAnalyzer)
+	pipelines, err := a.componentsFactory.BuildPipelines(a.telemetry)
 	if err != nil {
-		return fmt.Errorf("error happened while creating analyzer manager: %w", err)
+		return err
 	}
-	a.analyzerManager = analyzerManager
-
-	cgoReceiverFactory := a.componentsFactory.Receivers[cgoreceiver.Cgo]
-	cgoReceiver := cgoReceiverFactory.NewFunc(cgoReceiverFactory.Config, a.telemetry.GetTelemetryTools(cgoreceiver.Cgo), analyzerManager)
-	a.receiver = cgoReceiver
+	a.analyzerManager = pipelines.AnalyzerManager
+	a.receiver = pipelines.Receiver
+	a.pipelines = pipelines
 
-	a.controllerFactory.RegistModule("profile",
-		cpuAnalyzer.(*cpuanalyzer.CpuAnalyzer).ProfileModule,
-		cgoReceiver.(*cgoreceiver.CgoReceiver).ProfileModule,
+	if !a.controllerFactory.HasModule(controller.ProfileModule) {
+		return nil
+	}
+	// The profile module can't be started without these components, so it is disabled.
+	logger := a.telemetry.GetGlobalTelemetryTools().Logger
+	cpuAnalyzer, ok := pipelines.Analyzers[cpuanalyzer.CpuProfile.String()].(*cpuanalyzer.CpuAnalyzer)
+	if !ok {
+		logger.Warnf("The controller module %q is disabled because %s is not used by any pipeline",
+			controller.ProfileModule, cpuanalyzer.CpuProfile.String())
+		return nil
+	}
+	cgoReceiver, ok := pipelines.Receiver.(*cgoreceiver.CgoReceiver)
+	if !ok {
+		logger.Warnf("The controller module %q is disabled because the receiver is not %s",
+			controller.ProfileModule, cgoreceiver.Cgo)
+		return nil
+	}
+	a.controllerFactory.RegistModule(controller.ProfileModule,
+		cpuAnalyzer.ProfileModule,
+		cgoReceiver.ProfileModule,
 	)
 
 	return nil
@@ -8,6 +8,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/queuedretry"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
 )
@@ -17,6 +18,8 @@ const (
 	AnalyzersKey  = "analyzers"
 	ProcessorsKey = "processors"
 	ExportersKey  = "exporters"
+	// RetryQueueKey is the section of each exporter that configures the persistent retry queue.
+	RetryQueueKey = "retry_queue"
 )
 
 var ComponentsKeyMap = []string{ReceiversKey, AnalyzersKey, ProcessorsKey, ExportersKey}
@@ -26,6 +29,7 @@ type ComponentsFactory struct {
 	Analyzers  map[string]AnalyzerFactory
 	Processors map[string]ProcessorFactory
 	Exporters  map[string]ExporterFactory
+	Pipelines  map[string]*PipelineConfig
 }
 
 type NewReceiverFunc func(cfg interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzer.Manager) receiver.Receiver
@@ -51,6 +55,8 @@ type ProcessorFactory struct {
 type ExporterFactory struct {
 	NewFunc NewExporterFunc
 	Config  interface{}
+	// RetryQueueConfig wraps the exporter with a persistent retry queue if it is enabled.
+	RetryQueueConfig *queuedretry.Config
 }
 
 func NewComponentsFactory() *ComponentsFactory {
@@ -59,6 +65,7 @@ func NewComponentsFactory() *ComponentsFactory {
 		Analyzers:  make(map[string]AnalyzerFactory),
 		Processors: make(map[string]ProcessorFactory),
 		Exporters:  make(map[string]ExporterFactory),
+		Pipelines:  NewDefaultPipelinesConfig(),
 	}
 }
 func (c *ComponentsFactory) RegisterReceiver(
@@ -100,8 +107,9 @@ func (c *ComponentsFactory) RegisterExporter(
 	config interface{},
 ) {
 	c.Exporters[name] = ExporterFactory{
-		NewFunc: f,
-		Config:  config,
+		NewFunc:          f,
+		Config:           config,
+		RetryQueueConfig: queuedretry.NewDefaultConfig(),
 	}
 }
 
@@ -146,8 +154,21 @@ func (c *ComponentsFactory) ConstructConfig(viper *viper.Viper) error {
 				if err != nil {
 					return err
 				}
+				err = viper.UnmarshalKey(key+"."+RetryQueueKey, factory.RetryQueueConfig, mapStructureDecoderConfigFunc)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
+	// The default pipelines are used if there is no pipelines section.
+	if viper.IsSet(PipelinesKey) {
+		pipelines := make(map[string]*PipelineConfig)
+		err := viper.UnmarshalKey(PipelinesKey, &pipelines, mapStructureDecoderConfigFunc)
+		if err != nil {
+			return err
+		}
+		c.Pipelines = pipelines
+	}
 	return nil
 }
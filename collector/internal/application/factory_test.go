@@ -8,6 +8,7 @@ import (
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/container"
 )
 
 func TestConstructConfig(t *testing.T) {
@@ -34,6 +35,7 @@ func TestConstructConfig(t *testing.T) {
 		KubeConfigDir:         "/opt/.kube/config",
 		GraceDeletePeriod:     30,
 		EnableFetchReplicaSet: true,
+		ContainerRuntime:      container.DefaultConfig,
 	}
 	assert.Equal(t, expectedCfg, k8sCfg)
 
@@ -0,0 +1,243 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/multierr"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/cpuanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/k8sinfoanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpconnectanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpmetricanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/cameraexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/otelexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/queuedretry"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/aggregateprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/cgoreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
+)
+
+const PipelinesKey = "pipelines"
+
+// PipelineConfig declares a chain of components. Events flow from the receiver
+// into the analyzers, and the DataGroups produced by the analyzers are passed through
+// the processors in order and finally sent to all the exporters.
+type PipelineConfig struct {
+	Receiver   string   `mapstructure:"receiver"`
+	Analyzers  []string `mapstructure:"analyzers"`
+	Processors []string `mapstructure:"processors"`
+	Exporters  []string `mapstructure:"exporters"`
+}
+
+// NewDefaultPipelinesConfig returns the pipelines used when there is no "pipelines"
+// section in the configuration file.
+func NewDefaultPipelinesConfig() map[string]*PipelineConfig {
+	return map[string]*PipelineConfig{
+		"network": {
+			Receiver:   cgoreceiver.Cgo,
+			Analyzers:  []string{network.Network.String(), tcpconnectanalyzer.Type.String()},
+			Processors: []string{k8sprocessor.K8sMetadata, aggregateprocessor.Type},
+			Exporters:  []string{otelexporter.Otel},
+		},
+		"tcp": {
+			Receiver:   cgoreceiver.Cgo,
+			Analyzers:  []string{tcpmetricanalyzer.TcpMetric.String()},
+			Processors: []string{k8sprocessor.K8sMetadata, aggregateprocessor.Type},
+			Exporters:  []string{otelexporter.Otel},
+		},
+		"profile": {
+			Receiver:  cgoreceiver.Cgo,
+			Analyzers: []string{cpuanalyzer.CpuProfile.String()},
+			Exporters: []string{cameraexporter.Type},
+		},
+		"k8sinfo": {
+			Receiver:  cgoreceiver.Cgo,
+			Analyzers: []string{k8sinfoanalyzer.Type.String()},
+			Exporters: []string{otelexporter.Otel},
+		},
+	}
+}
+
+// Pipelines contains the components instantiated according to the pipelines configuration.
+// The processors of each pipeline are listed in their order.
+type Pipelines struct {
+	Receiver        receiver.Receiver
+	AnalyzerManager *analyzer.Manager
+	Analyzers       map[string]analyzer.Analyzer
+	Processors      []processor.Processor
+	Exporters       map[string]exporter.Exporter
+}
+
+// ShutdownConsumers shuts down the processors and then the exporters which hold resources,
+// so the data flushed by the processors could still be exported.
+func (p *Pipelines) ShutdownConsumers(logger *component.TelemetryLogger) error {
+	var retErr error
+	for _, proc := range p.Processors {
+		if s, ok := proc.(consumer.Shutdowner); ok {
+			retErr = multierr.Append(retErr, s.Shutdown())
+		}
+	}
+	exporterNames := make([]string, 0, len(p.Exporters))
+	for name := range p.Exporters {
+		exporterNames = append(exporterNames, name)
+	}
+	sort.Strings(exporterNames)
+	for _, name := range exporterNames {
+		if s, ok := p.Exporters[name].(consumer.Shutdowner); ok {
+			logger.Infof("Shutdown exporter [%s]", name)
+			retErr = multierr.Append(retErr, s.Shutdown())
+		}
+	}
+	return retErr
+}
+
+// BuildPipelines instantiates the components declared in the pipelines and connects them.
+// Exporters and analyzers are shared by all the pipelines that declare them, while
+// processors are created for each pipeline because they may hold per-pipeline state.
+func (c *ComponentsFactory) BuildPipelines(telemetry *component.TelemetryManager) (*Pipelines, error) {
+	if len(c.Pipelines) == 0 {
+		return nil, fmt.Errorf("no pipelines found, but must provide at least one pipeline")
+	}
+	// Iterate the pipelines in a fixed order to make the result deterministic.
+	pipelineNames := make([]string, 0, len(c.Pipelines))
+	for name := range c.Pipelines {
+		pipelineNames = append(pipelineNames, name)
+	}
+	sort.Strings(pipelineNames)
+
+	// The pod dimensions attached by the k8sprocessor are read by the processors and exporters
+	// after it when they are created, but they are built before it.
+	if factory, ok := c.Processors[k8sprocessor.K8sMetadata]; ok && c.usesProcessor(k8sprocessor.K8sMetadata) {
+		if cfg, ok := factory.Config.(*k8sprocessor.Config); ok {
+			k8sprocessor.RegisterPodDimensions(cfg)
+		}
+	}
+
+	var receiverName string
+	exporters := make(map[string]exporter.Exporter)
+	processors := make([]processor.Processor, 0)
+	analyzerNames := make([]string, 0)
+	analyzerConsumers := make(map[string][]consumer.Consumer)
+	for _, pipelineName := range pipelineNames {
+		pipeline := c.Pipelines[pipelineName]
+		if pipeline.Receiver != "" {
+			if receiverName != "" && receiverName != pipeline.Receiver {
+				return nil, fmt.Errorf("pipeline [%s] uses receiver [%s], but only one receiver is supported and [%s] has been declared",
+					pipelineName, pipeline.Receiver, receiverName)
+			}
+			receiverName = pipeline.Receiver
+		}
+		if len(pipeline.Analyzers) == 0 {
+			return nil, fmt.Errorf("pipeline [%s] has no analyzers", pipelineName)
+		}
+		if len(pipeline.Exporters) == 0 {
+			return nil, fmt.Errorf("pipeline [%s] has no exporters", pipelineName)
+		}
+
+		// Build the chain backwards, starting from the exporters.
+		pipelineExporters := make([]consumer.Consumer, 0, len(pipeline.Exporters))
+		for _, exporterName := range pipeline.Exporters {
+			exp, ok := exporters[exporterName]
+			if !ok {
+				factory, ok := c.Exporters[exporterName]
+				if !ok {
+					return nil, fmt.Errorf("pipeline [%s] uses unknown exporter [%s]", pipelineName, exporterName)
+				}
+				exp = factory.NewFunc(factory.Config, telemetry.GetTelemetryTools(exporterName))
+				if factory.RetryQueueConfig != nil && factory.RetryQueueConfig.Enabled {
+					if unsupported, ok := exp.(queuedretry.UnsupportedExporter); ok {
+						return nil, fmt.Errorf("exporter [%s] doesn't support retry_queue: %s", exporterName, unsupported.RetryQueueUnsupported())
+					}
+					queued, err := queuedretry.New(exporterName, factory.RetryQueueConfig, exp, telemetry.GetTelemetryTools(exporterName))
+					if err != nil {
+						return nil, fmt.Errorf("failed to create the retry queue of exporter [%s]: %w", exporterName, err)
+					}
+					exp = queued
+				}
+				exporters[exporterName] = exp
+			}
+			pipelineExporters = append(pipelineExporters, exp)
+		}
+		nextConsumers := pipelineExporters
+		pipelineProcessors := make([]processor.Processor, len(pipeline.Processors))
+		for i := len(pipeline.Processors) - 1; i >= 0; i-- {
+			processorName := pipeline.Processors[i]
+			factory, ok := c.Processors[processorName]
+			if !ok {
+				return nil, fmt.Errorf("pipeline [%s] uses unknown processor [%s]", pipelineName, processorName)
+			}
+			p := factory.NewFunc(factory.Config, telemetry.GetTelemetryTools(processorName), consumer.NewFanOut(nextConsumers...))
+			pipelineProcessors[i] = p
+			nextConsumers = []consumer.Consumer{p}
+		}
+		processors = append(processors, pipelineProcessors...)
+
+		for _, analyzerName := range pipeline.Analyzers {
+			if _, ok := c.Analyzers[analyzerName]; !ok {
+				return nil, fmt.Errorf("pipeline [%s] uses unknown analyzer [%s]", pipelineName, analyzerName)
+			}
+			if _, ok := analyzerConsumers[analyzerName]; !ok {
+				analyzerNames = append(analyzerNames, analyzerName)
+			}
+			analyzerConsumers[analyzerName] = append(analyzerConsumers[analyzerName], consumer.NewFanOut(nextConsumers...))
+		}
+	}
+	if receiverName == "" {
+		return nil, fmt.Errorf("no receiver is declared in the pipelines")
+	}
+	receiverFactory, ok := c.Receivers[receiverName]
+	if !ok {
+		return nil, fmt.Errorf("unknown receiver [%s]", receiverName)
+	}
+
+	// NetworkAnalyzer must be initialized before any other analyzers, because it will
+	// use its configuration to initialize the conntracker module which is also used by others.
+	sort.SliceStable(analyzerNames, func(i, j int) bool {
+		return analyzerNames[i] == network.Network.String() && analyzerNames[j] != network.Network.String()
+	})
+	analyzers := make(map[string]analyzer.Analyzer, len(analyzerNames))
+	analyzerList := make([]analyzer.Analyzer, 0, len(analyzerNames))
+	// The data groups produced by the analyzers are also streamed to the "/debug/tap" endpoint.
+	// The tap goes first to see them before the processors modify them.
+	tapConsumer := tap.NewDataGroupConsumer(tap.GetHub())
+	for _, analyzerName := range analyzerNames {
+		// The processors modify the DataGroups in place, so each pipeline sharing the
+		// analyzer receives its own copy.
+		consumers := []consumer.Consumer{tapConsumer, consumer.NewCloningFanOut(analyzerConsumers[analyzerName]...)}
+		factory := c.Analyzers[analyzerName]
+		a := factory.NewFunc(factory.Config, telemetry.GetTelemetryTools(analyzerName), consumers)
+		analyzers[analyzerName] = a
+		analyzerList = append(analyzerList, a)
+	}
+	analyzerManager, err := analyzer.NewManager(analyzerList...)
+	if err != nil {
+		return nil, fmt.Errorf("error happened while creating analyzer manager: %w", err)
+	}
+	return &Pipelines{
+		Receiver:        receiverFactory.NewFunc(receiverFactory.Config, telemetry.GetTelemetryTools(receiverName), analyzerManager),
+		AnalyzerManager: analyzerManager,
+		Analyzers:       analyzers,
+		Processors:      processors,
+		Exporters:       exporters,
+	}, nil
+}
+
+func (c *ComponentsFactory) usesProcessor(name string) bool {
+	for _, pipeline := range c.Pipelines {
+		for _, processorName := range pipeline.Processors {
+			if processorName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/queuedretry"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+type mockReceiver struct {
+	analyzerManager *analyzer.Manager
+}
+
+func (r *mockReceiver) Start() error    { return nil }
+func (r *mockReceiver) Shutdown() error { return nil }
+
+type mockAnalyzer struct {
+	name      string
+	consumers []consumer.Consumer
+}
+
+func (a *mockAnalyzer) Start() error    { return nil }
+func (a *mockAnalyzer) Shutdown() error { return nil }
+func (a *mockAnalyzer) Type() analyzer.Type {
+	return analyzer.Type(a.name)
+}
+func (a *mockAnalyzer) ConsumableEvents() []string {
+	return []string{analyzer.ConsumeAllEvents}
+}
+func (a *mockAnalyzer) ConsumeEvent(*model.KindlingEvent) error {
+	dataGroup := model.NewDataGroup(a.name, model.NewAttributeMap(), 0)
+	for _, c := range a.consumers {
+		_ = c.Consume(dataGroup)
+	}
+	return nil
+}
+
+type mockProcessor struct {
+	next consumer.Consumer
+}
+
+func (p *mockProcessor) Consume(dataGroup *model.DataGroup) error {
+	dataGroup.Labels.AddStringValue("processor", "mockprocessor")
+	return p.next.Consume(dataGroup)
+}
+
+type mockExporter struct {
+	received   []string
+	dataGroups []*model.DataGroup
+	shutdown   bool
+}
+
+func (e *mockExporter) Shutdown() error {
+	e.shutdown = true
+	return nil
+}
+
+func (e *mockExporter) Consume(dataGroup *model.DataGroup) error {
+	e.received = append(e.received, dataGroup.Name)
+	e.dataGroups = append(e.dataGroups, dataGroup)
+	return nil
+}
+
+// asyncExporter is an exporter that doesn't support the retry queue.
+type asyncExporter struct {
+	mockExporter
+}
+
+func (e *asyncExporter) RetryQueueUnsupported() string {
+	return "async"
+}
+
+func newMockFactory() *ComponentsFactory {
+	factory := NewComponentsFactory()
+	factory.RegisterReceiver("mockreceiver", func(cfg interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzer.Manager) receiver.Receiver {
+		return &mockReceiver{analyzerManager: analyzerManager}
+	}, &struct{}{})
+	for _, name := range []string{"mockanalyzer", "anotheranalyzer"} {
+		analyzerName := name
+		factory.RegisterAnalyzer(analyzerName, func(cfg interface{}, telemetry *component.TelemetryTools, consumers []consumer.Consumer) analyzer.Analyzer {
+			return &mockAnalyzer{name: analyzerName, consumers: consumers}
+		}, &struct{}{})
+	}
+	factory.RegisterProcessor("mockprocessor", func(cfg interface{}, telemetry *component.TelemetryTools, consumer consumer.Consumer) processor.Processor {
+		return &mockProcessor{next: consumer}
+	}, &struct{}{})
+	for _, name := range []string{"mockexporter", "anotherexporter"} {
+		factory.RegisterExporter(name, func(cfg interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+			return &mockExporter{}
+		}, &struct{}{})
+	}
+	return factory
+}
+
+func TestBuildPipelines(t *testing.T) {
+	factory := newMockFactory()
+	v := viper.New()
+	v.SetConfigFile("./testdata/kindling-collector-pipelines.yaml")
+	err := v.ReadInConfig()
+	assert.NoError(t, err)
+	err = factory.ConstructConfig(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(factory.Pipelines))
+
+	pipelines, err := factory.BuildPipelines(component.NewTelemetryManager())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(pipelines.Analyzers))
+	assert.Equal(t, 2, len(pipelines.Exporters))
+	assert.Equal(t, pipelines.AnalyzerManager, pipelines.Receiver.(*mockReceiver).analyzerManager)
+
+	for _, a := range pipelines.AnalyzerManager.GetConsumableAnalyzers("any") {
+		_ = a.ConsumeEvent(&model.KindlingEvent{})
+	}
+	// mockanalyzer is used by both pipelines, so its output is sent to mockexporter twice.
+	firstExporter := pipelines.Exporters["mockexporter"].(*mockExporter)
+	assert.ElementsMatch(t, []string{"mockanalyzer", "mockanalyzer", "anotheranalyzer"}, firstExporter.received)
+	anotherExporter := pipelines.Exporters["anotherexporter"].(*mockExporter)
+	assert.Equal(t, []string{"mockanalyzer"}, anotherExporter.received)
+
+	// The label added by the processor of the "network" pipeline must not leak
+	// into the DataGroup of the "tcp" pipeline.
+	processed := 0
+	for _, dataGroup := range firstExporter.dataGroups {
+		if dataGroup.Labels.HasAttribute("processor") {
+			processed++
+		}
+	}
+	assert.Equal(t, 1, processed)
+
+	assert.Len(t, pipelines.Processors, 1)
+	assert.NoError(t, pipelines.ShutdownConsumers(component.NewDefaultTelemetryTools().Logger))
+	assert.True(t, firstExporter.shutdown)
+	assert.True(t, anotherExporter.shutdown)
+}
+
+func TestBuildPipelinesWithUnknownComponent(t *testing.T) {
+	factory := newMockFactory()
+	factory.Pipelines = map[string]*PipelineConfig{
+		"network": {
+			Receiver:  "mockreceiver",
+			Analyzers: []string{"mockanalyzer"},
+			Exporters: []string{"unknownexporter"},
+		},
+	}
+	_, err := factory.BuildPipelines(component.NewTelemetryManager())
+	assert.Error(t, err)
+}
+
+func TestBuildPipelinesWithRetryQueue(t *testing.T) {
+	factory := newMockFactory()
+	v := viper.New()
+	v.SetConfigFile("./testdata/kindling-collector-pipelines.yaml")
+	err := v.ReadInConfig()
+	assert.NoError(t, err)
+	v.Set("exporters.mockexporter.retry_queue", map[string]interface{}{
+		"enabled":   true,
+		"directory": t.TempDir(),
+	})
+	err = factory.ConstructConfig(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 10000, factory.Exporters["mockexporter"].RetryQueueConfig.MaxQueueSize)
+
+	pipelines, err := factory.BuildPipelines(component.NewTelemetryManager())
+	assert.NoError(t, err)
+	queued, ok := pipelines.Exporters["mockexporter"].(*queuedretry.QueuedRetryExporter)
+	assert.True(t, ok)
+	defer queued.Shutdown()
+	_, ok = pipelines.Exporters["anotherexporter"].(*mockExporter)
+	assert.True(t, ok)
+}
+
+func TestBuildPipelinesWithUnsupportedRetryQueue(t *testing.T) {
+	factory := newMockFactory()
+	factory.RegisterExporter("mockexporter", func(cfg interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+		return &asyncExporter{}
+	}, &struct{}{})
+	v := viper.New()
+	v.SetConfigFile("./testdata/kindling-collector-pipelines.yaml")
+	err := v.ReadInConfig()
+	assert.NoError(t, err)
+	v.Set("exporters.mockexporter.retry_queue", map[string]interface{}{
+		"enabled":   true,
+		"directory": t.TempDir(),
+	})
+	err = factory.ConstructConfig(v)
+	assert.NoError(t, err)
+	_, err = factory.BuildPipelines(component.NewTelemetryManager())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "doesn't support retry_queue")
+	}
+}
+
+func TestBuildPipelinesRegistersPodDimensions(t *testing.T) {
+	defer kubernetes.SetPodDimensions(nil, nil)
+	factory := newMockFactory()
+	// The processor is mocked to avoid connecting to the API-server.
+	factory.RegisterProcessor(k8sprocessor.K8sMetadata, func(cfg interface{}, telemetry *component.TelemetryTools, consumer consumer.Consumer) processor.Processor {
+		return &mockProcessor{next: consumer}
+	}, &k8sprocessor.Config{
+		Enable:         true,
+		PodLabels:      []string{"app.kubernetes.io/version"},
+		PodAnnotations: []string{"team"},
+	})
+	// The exporters are built before the k8sprocessor but they see its pod dimensions.
+	var exporterDimensions []string
+	factory.RegisterExporter("mockexporter", func(cfg interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+		exporterDimensions = kubernetes.PodDimensions()
+		return &mockExporter{}
+	}, &struct{}{})
+	factory.Pipelines = map[string]*PipelineConfig{
+		"network": {
+			Receiver:   "mockreceiver",
+			Analyzers:  []string{"mockanalyzer"},
+			Processors: []string{k8sprocessor.K8sMetadata},
+			Exporters:  []string{"mockexporter"},
+		},
+	}
+	_, err := factory.BuildPipelines(component.NewTelemetryManager())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app.kubernetes.io/version", "team"}, exporterDimensions)
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 )
 
@@ -17,6 +18,7 @@ const (
 	LastKind
 	CountKind
 	HistogramKind
+	DDSketchKind
 )
 
 func (k AggregatorKind) name() string {
@@ -33,6 +35,8 @@ func (k AggregatorKind) name() string {
 		return "count"
 	case HistogramKind:
 		return "histogram"
+	case DDSketchKind:
+		return "ddsketch"
 	default:
 		return ""
 	}
@@ -52,6 +56,8 @@ func GetAggregatorKind(kind string) AggregatorKind {
 		return CountKind
 	case "histogram":
 		return HistogramKind
+	case "ddsketch":
+		return DDSketchKind
 	default:
 		return SumKind
 	}
@@ -60,6 +66,9 @@ func GetAggregatorKind(kind string) AggregatorKind {
 type (
 	AggregatedConfig struct {
 		KindMap map[string][]KindConfig
+		// MaxSeries is the max number of the series of each data group. Once it is reached, the new label
+		// combinations are folded into the overflow series. 0 means no limit.
+		MaxSeries int
 	}
 
 	KindConfig struct {
@@ -67,6 +76,9 @@ type (
 		Kind       AggregatorKind
 		// Only HistogramKind has this value
 		ExplicitBoundaries []int64
+		// Only DDSketchKind has these values
+		RelativeAccuracy float64
+		Quantiles        []float64
 	}
 )
 
@@ -111,6 +123,8 @@ func (m *defaultValuesMap) calculate(metric *model.Metric, timestamp uint64) {
 			v.calculate(metric.GetInt().Value)
 		} else if metric.DataType() == model.HistogramMetricType {
 			v.merge(metric.GetHistogram())
+		} else if metric.DataType() == model.SummaryMetricType {
+			v.mergeSummary(metric.GetSummary())
 		}
 	}
 	atomic.StoreUint64(&m.timestamp, timestamp)
@@ -155,6 +169,8 @@ func newAggValue(cfg KindConfig) aggregatedValues {
 		return &countValue{name: name}
 	case HistogramKind:
 		return &histogramValue{name: name, explicitBoundaries: cfg.ExplicitBoundaries, bucketCounts: make([]uint64, len(cfg.ExplicitBoundaries))}
+	case DDSketchKind:
+		return &sketchValue{name: name, sketch: ddsketch.New(cfg.RelativeAccuracy), quantiles: cfg.Quantiles}
 	default:
 		return &lastValue{name: name}
 	}
@@ -162,6 +178,7 @@ func newAggValue(cfg KindConfig) aggregatedValues {
 
 type aggregatedValues interface {
 	merge(metric *model.Histogram) error
+	mergeSummary(metric *model.Summary) error
 	calculate(value int64) int64
 	// get returns the value
 	get() *model.Metric
@@ -197,6 +214,10 @@ func (v *maxValue) merge(metric *model.Histogram) error {
 	return errors.New("can not use max on a histogram metric")
 }
 
+func (v *maxValue) mergeSummary(metric *model.Summary) error {
+	return errors.New("can not use max on a summary metric")
+}
+
 type sumValue struct {
 	name  string
 	value int64
@@ -215,6 +236,10 @@ func (v *sumValue) merge(metric *model.Histogram) error {
 	atomic.AddInt64(&v.value, metric.Sum)
 	return nil
 }
+func (v *sumValue) mergeSummary(metric *model.Summary) error {
+	atomic.AddInt64(&v.value, metric.Sum)
+	return nil
+}
 
 type avgValue struct {
 	name  string
@@ -249,6 +274,14 @@ func (v *avgValue) merge(metric *model.Histogram) error {
 	return nil
 }
 
+func (v *avgValue) mergeSummary(metric *model.Summary) error {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.count += int64(metric.Count)
+	v.value += metric.Sum
+	return nil
+}
+
 type lastValue struct {
 	name  string
 	value int64
@@ -266,6 +299,9 @@ func (v *lastValue) getName() string {
 func (v *lastValue) merge(metric *model.Histogram) error {
 	return errors.New("can not use lastValue on a histogram metric")
 }
+func (v *lastValue) mergeSummary(metric *model.Summary) error {
+	return errors.New("can not use lastValue on a summary metric")
+}
 
 type countValue struct {
 	name  string
@@ -283,6 +319,11 @@ func (v *countValue) merge(metric *model.Histogram) error {
 	return nil
 }
 
+func (v *countValue) mergeSummary(metric *model.Summary) error {
+	atomic.AddInt64(&v.value, int64(metric.Count))
+	return nil
+}
+
 func (v *countValue) get() *model.Metric {
 	return model.NewIntMetric(v.name, atomic.LoadInt64(&v.value))
 }
@@ -340,3 +381,55 @@ func (v *histogramValue) merge(metric *model.Histogram) error {
 	}
 	return nil
 }
+
+func (v *histogramValue) mergeSummary(metric *model.Summary) error {
+	return errors.New("can not use histogram on a summary metric")
+}
+
+// sketchValue records the values into a DDSketch, so the quantiles could be calculated by the exporters.
+type sketchValue struct {
+	name      string
+	sum       int64
+	sketch    *ddsketch.DDSketch
+	quantiles []float64
+	mut       sync.RWMutex
+}
+
+func (v *sketchValue) calculate(value int64) int64 {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.sum += value
+	v.sketch.Add(float64(value))
+	return int64(v.sketch.Count())
+}
+
+func (v *sketchValue) get() *model.Metric {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+	return model.NewSummaryMetric(v.name, &model.Summary{
+		Sum:       v.sum,
+		Count:     v.sketch.Count(),
+		Sketch:    v.sketch.Copy(),
+		Quantiles: v.quantiles,
+	})
+}
+
+func (v *sketchValue) getName() string {
+	return v.name
+}
+
+func (v *sketchValue) merge(metric *model.Histogram) error {
+	return errors.New("can not use ddsketch on a histogram metric")
+}
+
+func (v *sketchValue) mergeSummary(metric *model.Summary) error {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	v.sum += metric.Sum
+	v.sketch.Merge(metric.Sketch)
+	// The quantiles configured by the upstream aggregation are kept.
+	if len(v.quantiles) == 0 {
+		v.quantiles = metric.Quantiles
+	}
+	return nil
+}
@@ -307,3 +307,31 @@ func Test_defaultValuesMap_histogramValue(t *testing.T) {
 		t.Errorf("lastValue result is %v, expected %v", got[0].GetHistogram(), expected.GetHistogram())
 	}
 }
+
+func Test_defaultValuesMap_sketchValue(t *testing.T) {
+	kindMap := make(map[string][]KindConfig)
+	kindMap["sketch_value"] = []KindConfig{
+		{OutputName: "sketch_value", Kind: DDSketchKind, RelativeAccuracy: 0.01, Quantiles: []float64{0.5, 0.99}},
+		{OutputName: "sketch_value_count", Kind: CountKind},
+	}
+	metrics := []*model.Metric{{Name: "sketch_value"}}
+	m := newAggValuesMap(metrics, kindMap)
+	for i := 10000; i > 0; i-- {
+		m.calculate(model.NewIntMetric("sketch_value", int64(i)), 0)
+	}
+	got := m.get("sketch_value")
+	summary := got[0].GetSummary()
+	assert.Equal(t, int64(10000*5000+5000), summary.Sum)
+	assert.Equal(t, uint64(10000), summary.Count)
+	assert.InEpsilon(t, 9900, summary.Sketch.Quantile(0.99), 0.01)
+	assert.Equal(t, []float64{0.5, 0.99}, summary.GetQuantiles())
+
+	// The summaries are merged without losing the accuracy.
+	m.calculate(got[0], 0)
+	got = m.get("sketch_value")
+	assert.Equal(t, uint64(20000), got[0].GetSummary().Count)
+	assert.InEpsilon(t, 5000, got[0].GetSummary().Sketch.Quantile(0.5), 0.01)
+	assert.Equal(t, int64(20000), got[1].GetInt().Value)
+
+	assert.EqualError(t, (&maxValue{}).mergeSummary(summary), "can not use max on a summary metric")
+}
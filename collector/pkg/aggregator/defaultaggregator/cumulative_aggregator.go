@@ -6,8 +6,13 @@ import (
 
 	"github.com/Kindling-project/kindling/collector/pkg/aggregator"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
 )
 
+// RequestTimeBoundaries are the boundaries of the request time histograms in nanoseconds.
+var RequestTimeBoundaries = []int64{10e6, 20e6, 50e6, 80e6, 130e6, 200e6, 300e6, 400e6, 500e6, 700e6, 1000e6, 2000e6, 5000e6, 30000e6}
+
 // DataGroup
 // Name:
 //   labels:
@@ -26,11 +31,9 @@ func (r *cumulativeValueRecorder) RecordWithDefaultSumKindAgg(key *aggregator.La
 	if key == nil {
 		return
 	}
-	aggValues, ok := r.labelValues.Load(*key)
-	if !ok {
-		// double check to avoid double writing
-		aggValues, _ = r.labelValues.LoadOrStore(*key, newExpiredAggValuesMapWithDefaultSumKind(metricValues, r.aggKindMap))
-	}
+	aggValues := r.loadOrStore(key, func() aggValuesMap {
+		return newExpiredAggValuesMapWithDefaultSumKind(metricValues, r.aggKindMap)
+	})
 	for _, metric := range metricValues {
 		aggValues.(*expiredValuesMap).calculateWithExpired(metric, timestamp, now)
 	}
@@ -43,10 +46,13 @@ func remove(s []aggregatedValues, i int) []aggregatedValues {
 
 func (r *cumulativeValueRecorder) removeExpired(expirationTime time.Time) {
 	expiredLabelValues := make([]interface{}, 0)
+	var seriesSize int64
 	r.labelValues.Range(func(key, value interface{}) bool {
 		valueMap := value.(*expiredValuesMap)
 		if expirationTime.After(valueMap.update) {
 			expiredLabelValues = append(expiredLabelValues, key)
+		} else if k := key.(aggregator.LabelKeys); *k.Overflow() != k {
+			seriesSize++
 		}
 		return true
 	})
@@ -54,6 +60,7 @@ func (r *cumulativeValueRecorder) removeExpired(expirationTime time.Time) {
 		// TODO log debug expired
 		r.labelValues.Delete(expiredLabelValues[i])
 	}
+	atomic.StoreInt64(&r.seriesSize, seriesSize)
 }
 
 func (c *CumulativeAggregator) AggregatorWithAllLabelsAndMetric(g *model.DataGroup, now time.Time) {
@@ -65,7 +72,7 @@ func (c *CumulativeAggregator) AggregatorWithAllLabelsAndMetric(g *model.DataGro
 	// will become stable after running a period of time.
 	if !ok {
 		// double check to avoid double writing
-		recorder, _ = c.recordersMap.LoadOrStore(name, &cumulativeValueRecorder{newValueRecorder(name, c.config.KindMap)})
+		recorder, _ = c.recordersMap.LoadOrStore(name, &cumulativeValueRecorder{c.newValueRecorder(name)})
 	}
 	key := aggregator.GetLabelsKeys(g.Labels)
 	recorder.(*cumulativeValueRecorder).RecordWithDefaultSumKindAgg(key, g.Metrics, g.Timestamp, now)
@@ -86,6 +93,31 @@ func (c *CumulativeAggregator) DumpAndRemoveExpired(now time.Time) []*model.Data
 	return ret
 }
 
+// NewExporterAggregatedConfig returns the config of the exporters aggregating the metrics cumulatively.
+// The RTT and lastValueMetrics keep their last values, and the request time is aggregated into
+// histograms with RequestTimeBoundaries. The other metrics are summed.
+func NewExporterAggregatedConfig(lastValueMetrics ...string) *AggregatedConfig {
+	requestTimeHistogramTopologyMetric := constnames.ToKindlingNetMetricName(constvalues.RequestTimeHistogram, false)
+	requestTimeHistogramEntityMetric := constnames.ToKindlingNetMetricName(constvalues.RequestTimeHistogram, true)
+	kindMap := map[string][]KindConfig{
+		constnames.TcpRttMetricName: {{Kind: LastKind, OutputName: constnames.TcpRttMetricName}},
+		requestTimeHistogramTopologyMetric: {{
+			Kind:               HistogramKind,
+			OutputName:         requestTimeHistogramTopologyMetric,
+			ExplicitBoundaries: RequestTimeBoundaries,
+		}},
+		requestTimeHistogramEntityMetric: {{
+			Kind:               HistogramKind,
+			OutputName:         requestTimeHistogramEntityMetric,
+			ExplicitBoundaries: RequestTimeBoundaries,
+		}},
+	}
+	for _, name := range lastValueMetrics {
+		kindMap[name] = []KindConfig{{Kind: LastKind, OutputName: name}}
+	}
+	return &AggregatedConfig{KindMap: kindMap}
+}
+
 func NewCumulativeAggregator(config *AggregatedConfig, metricExpiration time.Duration) *CumulativeAggregator {
 	ret := &CumulativeAggregator{
 		DefaultAggregator: NewDefaultAggregator(config),
@@ -109,6 +141,8 @@ func (e *expiredValuesMap) calculateWithExpired(metric *model.Metric, timestamp
 			v.calculate(metric.GetInt().Value)
 		} else if metric.DataType() == model.HistogramMetricType {
 			v.merge(metric.GetHistogram())
+		} else if metric.DataType() == model.SummaryMetricType {
+			v.mergeSummary(metric.GetSummary())
 		}
 	}
 	e.update = now
@@ -28,6 +28,12 @@ func NewDefaultAggregator(config *AggregatedConfig) *DefaultAggregator {
 	return ret
 }
 
+func (s *DefaultAggregator) newValueRecorder(name string) *valueRecorder {
+	recorder := newValueRecorder(name, s.config.KindMap)
+	recorder.maxSeries = s.config.MaxSeries
+	return recorder
+}
+
 func (s *DefaultAggregator) Aggregate(g *model.DataGroup, selectors *aggregator.LabelSelectors) {
 	name := g.Name
 	s.mut.RLock()
@@ -37,7 +43,7 @@ func (s *DefaultAggregator) Aggregate(g *model.DataGroup, selectors *aggregator.
 	// will become stable after running a period of time.
 	if !ok {
 		// double check to avoid double writing
-		recorder, _ = s.recordersMap.LoadOrStore(name, newValueRecorder(name, s.config.KindMap))
+		recorder, _ = s.recordersMap.LoadOrStore(name, s.newValueRecorder(name))
 	}
 	key := selectors.GetLabelKeys(g.Labels)
 	recorder.(*valueRecorder).Record(key, g.Metrics, g.Timestamp)
@@ -0,0 +1,83 @@
+package defaultaggregator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	seriesSizeMetric    = "kindling_telemetry_aggregator_series_size"
+	overflowTotalMetric = "kindling_telemetry_aggregator_overflow_datagroups_total"
+)
+
+var (
+	selfMetricsMutex sync.Mutex
+	// registries stores the aggregators whose self metrics are reported by each MeterProvider. The
+	// instruments are created once per MeterProvider, and they observe the aggregators registered.
+	registries = make(map[metric.MeterProvider]*aggregatorRegistry)
+)
+
+// aggregatorRegistry stores the component names of the aggregators whose self metrics are reported.
+type aggregatorRegistry struct {
+	aggregators sync.Map
+}
+
+// RegisterSelfMetrics reports the number of the series of this aggregator and the count of the data groups
+// merged into the overflow series, with the label "component" set to componentName.
+// UnregisterSelfMetrics must be called when the aggregator is no longer used, otherwise it is never released.
+func (s *DefaultAggregator) RegisterSelfMetrics(componentName string, meterProvider metric.MeterProvider) {
+	selfMetricsMutex.Lock()
+	defer selfMetricsMutex.Unlock()
+	registry, ok := registries[meterProvider]
+	if !ok {
+		registry = &aggregatorRegistry{}
+		registries[meterProvider] = registry
+		meter := metric.Must(meterProvider.Meter("kindling"))
+		meter.NewInt64GaugeObserver(seriesSizeMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				registry.rangeRecorders(func(recorder *valueRecorder, attrs ...attribute.KeyValue) {
+					result.Observe(atomic.LoadInt64(&recorder.seriesSize), attrs...)
+				})
+			}, metric.WithDescription("The current number of the series stored in the aggregator, excluding the overflow series"))
+		meter.NewInt64CounterObserver(overflowTotalMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				registry.rangeRecorders(func(recorder *valueRecorder, attrs ...attribute.KeyValue) {
+					result.Observe(atomic.LoadInt64(&recorder.overflowTotal), attrs...)
+				})
+			}, metric.WithDescription("The total count of the data groups merged into the __overflow__ series because the series limit is reached"))
+	}
+	registry.aggregators.Store(s, componentName)
+}
+
+// UnregisterSelfMetrics stops reporting the self metrics of this aggregator.
+func (s *DefaultAggregator) UnregisterSelfMetrics() {
+	selfMetricsMutex.Lock()
+	defer selfMetricsMutex.Unlock()
+	for _, registry := range registries {
+		registry.aggregators.Delete(s)
+	}
+}
+
+func (r *aggregatorRegistry) rangeRecorders(f func(recorder *valueRecorder, attrs ...attribute.KeyValue)) {
+	r.aggregators.Range(func(key, value interface{}) bool {
+		componentName := value.(string)
+		key.(*DefaultAggregator).recordersMap.Range(func(name, recorder interface{}) bool {
+			attrs := []attribute.KeyValue{
+				attribute.String("component", componentName),
+				attribute.String("data_group", name.(string)),
+			}
+			switch r := recorder.(type) {
+			case *valueRecorder:
+				f(r, attrs...)
+			case *cumulativeValueRecorder:
+				f(r.valueRecorder, attrs...)
+			}
+			return true
+		})
+		return true
+	})
+}
@@ -0,0 +1,75 @@
+package defaultaggregator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	otelprocessor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func newTestController() *controller.Controller {
+	// The collect period is 0 so each Collect observes the aggregators again.
+	return controller.New(otelprocessor.NewFactory(selector.NewWithInexpensiveDistribution(),
+		aggregation.DeltaTemporalitySelector()), controller.WithCollectPeriod(0))
+}
+
+// collectSeriesSize returns the series sizes reported by the controller, keyed by the components.
+func collectSeriesSize(t *testing.T, c *controller.Controller) map[string]int64 {
+	require.NoError(t, c.Collect(context.Background()))
+	ret := make(map[string]int64)
+	err := c.ForEach(func(_ instrumentation.Library, reader metric.Reader) error {
+		return reader.ForEach(aggregation.DeltaTemporalitySelector(), func(record metric.Record) error {
+			if record.Descriptor().Name() != seriesSizeMetric {
+				return nil
+			}
+			value, _, err := record.Aggregation().(aggregation.LastValue).LastValue()
+			if err != nil {
+				return err
+			}
+			component, _ := record.Labels().Value(attribute.Key("component"))
+			ret[component.AsString()] = value.AsInt64()
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	return ret
+}
+
+func TestRegisterSelfMetrics(t *testing.T) {
+	selectors := aggregator.NewLabelSelectors(aggregator.LabelSelector{Name: "key", VType: aggregator.StringType})
+	newAggregator := func(series int) *DefaultAggregator {
+		ret := NewDefaultAggregator(&AggregatedConfig{KindMap: map[string][]KindConfig{
+			"duration": {{Kind: SumKind, OutputName: "duration"}},
+		}})
+		for i := 0; i < series; i++ {
+			labels := model.NewAttributeMap()
+			labels.AddStringValue("key", string(rune('a'+i)))
+			ret.Aggregate(model.NewDataGroup("testMetric", labels, 0, model.NewIntMetric("duration", 1)), selectors)
+		}
+		return ret
+	}
+	first, second := newAggregator(1), newAggregator(2)
+	firstController, secondController := newTestController(), newTestController()
+	first.RegisterSelfMetrics("first", firstController)
+	second.RegisterSelfMetrics("second", secondController)
+	defer second.UnregisterSelfMetrics()
+
+	// Each MeterProvider only reports the aggregators registered to it.
+	assert.Equal(t, map[string]int64{"first": 1}, collectSeriesSize(t, firstController))
+	assert.Equal(t, map[string]int64{"second": 2}, collectSeriesSize(t, secondController))
+
+	first.UnregisterSelfMetrics()
+	assert.Empty(t, collectSeriesSize(t, firstController))
+	assert.Equal(t, map[string]int64{"second": 2}, collectSeriesSize(t, secondController))
+}
@@ -2,6 +2,7 @@ package defaultaggregator
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/Kindling-project/kindling/collector/pkg/aggregator"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
@@ -12,6 +13,13 @@ type valueRecorder struct {
 	// aggValuesMap is responsible for its own thread-safe access.
 	labelValues sync.Map
 	aggKindMap  map[string][]KindConfig
+
+	// maxSeries is the max number of the series recorded, 0 means no limit.
+	maxSeries int
+	// seriesSize is the number of the series recorded, excluding the overflow series.
+	seriesSize int64
+	// overflowTotal is the count of the data groups merged into the overflow series.
+	overflowTotal int64
 }
 
 func newValueRecorder(recorderName string, aggKindMap map[string][]KindConfig) *valueRecorder {
@@ -22,6 +30,30 @@ func newValueRecorder(recorderName string, aggKindMap map[string][]KindConfig) *
 	}
 }
 
+// loadOrStore returns the values of the key. Once the series limit is reached, the new label combinations
+// are folded into the overflow series. The limit may be exceeded slightly when recording concurrently.
+func (r *valueRecorder) loadOrStore(key *aggregator.LabelKeys, newValues func() aggValuesMap) aggValuesMap {
+	aggValues, ok := r.labelValues.Load(*key)
+	if ok {
+		return aggValues.(aggValuesMap)
+	}
+	if r.maxSeries > 0 && atomic.LoadInt64(&r.seriesSize) >= int64(r.maxSeries) {
+		atomic.AddInt64(&r.overflowTotal, 1)
+		overflowKey := key.Overflow()
+		if aggValues, ok = r.labelValues.Load(*overflowKey); !ok {
+			// double check to avoid double writing
+			aggValues, _ = r.labelValues.LoadOrStore(*overflowKey, newValues())
+		}
+		return aggValues.(aggValuesMap)
+	}
+	// double check to avoid double writing
+	aggValues, loaded := r.labelValues.LoadOrStore(*key, newValues())
+	if !loaded {
+		atomic.AddInt64(&r.seriesSize, 1)
+	}
+	return aggValues.(aggValuesMap)
+}
+
 // Record is thread-safe, and return the result value.
 // A recorder can record only the metrics that are the same as the initial ones when using the same key.
 // But it can record different metrics with different keys.
@@ -29,13 +61,11 @@ func (r *valueRecorder) Record(key *aggregator.LabelKeys, metricValues []*model.
 	if key == nil {
 		return
 	}
-	aggValues, ok := r.labelValues.Load(*key)
-	if !ok {
-		// double check to avoid double writing
-		aggValues, _ = r.labelValues.LoadOrStore(*key, newAggValuesMap(metricValues, r.aggKindMap))
-	}
+	aggValues := r.loadOrStore(key, func() aggValuesMap {
+		return newAggValuesMap(metricValues, r.aggKindMap)
+	})
 	for _, metric := range metricValues {
-		aggValues.(aggValuesMap).calculate(metric, timestamp)
+		aggValues.calculate(metric, timestamp)
 	}
 }
 
@@ -57,4 +87,5 @@ func (r *valueRecorder) dump() []*model.DataGroup {
 // This method is not thread safe.
 func (r *valueRecorder) reset() {
 	r.labelValues = sync.Map{}
+	atomic.StoreInt64(&r.seriesSize, 0)
 }
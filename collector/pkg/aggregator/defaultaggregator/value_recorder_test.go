@@ -2,6 +2,7 @@ package defaultaggregator
 
 import (
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,3 +116,41 @@ func TestRecordDiffMetricsWithSameKey(t *testing.T) {
 	_, ok := retMetricGroup[0].GetMetric("last")
 	assert.Equal(t, false, ok)
 }
+
+// TestRecordOverflow validates that the new label combinations are folded into the overflow series
+// once the series limit is reached.
+func TestRecordOverflow(t *testing.T) {
+	aggKindMap := AggregatedConfig{KindMap: map[string][]KindConfig{
+		"duration": {{Kind: SumKind, OutputName: "duration_sum"}},
+	}}
+	recorder := newValueRecorder("testRecorder", aggKindMap.KindMap)
+	recorder.maxSeries = 2
+	newKeys := func(url string, port int) *aggregator.LabelKeys {
+		return aggregator.NewLabelKeys([]aggregator.LabelKey{
+			{Name: "url", Value: url, VType: aggregator.StringType},
+			{Name: "port", Value: strconv.Itoa(port), VType: aggregator.IntType},
+			{Name: "is_server", Value: "true", VType: aggregator.BooleanType},
+		}...)
+	}
+	for i := 0; i < 10; i++ {
+		recorder.Record(newKeys("/"+strconv.Itoa(i), 80+i), []*model.Metric{model.NewIntMetric("duration", 100)}, 0)
+	}
+	// The existing series are still recorded.
+	recorder.Record(newKeys("/0", 80), []*model.Metric{model.NewIntMetric("duration", 100)}, 0)
+
+	sums := make(map[string]int64)
+	for _, dataGroup := range recorder.dump() {
+		assert.True(t, dataGroup.Labels.GetBoolValue("is_server"))
+		sum, _ := dataGroup.GetMetric("duration_sum")
+		sums[dataGroup.Labels.GetStringValue("url")] = sum.GetInt().Value
+	}
+	assert.Equal(t, map[string]int64{"/0": 200, "/1": 100, aggregator.OverflowLabelValue: 800}, sums)
+	assert.Equal(t, int64(2), recorder.seriesSize)
+	assert.Equal(t, int64(8), recorder.overflowTotal)
+
+	// The series are counted again after being reset.
+	recorder.reset()
+	recorder.Record(newKeys("/9", 89), []*model.Metric{model.NewIntMetric("duration", 100)}, 0)
+	assert.Len(t, recorder.dump(), 1)
+	assert.Equal(t, int64(1), recorder.seriesSize)
+}
@@ -1,6 +1,7 @@
 package aggregator
 
 import (
+	"fmt"
 	"sort"
 	"strconv"
 
@@ -50,11 +51,26 @@ func (s *LabelSelectors) AppendSelectors(selectors ...LabelSelector) {
 	s.selectors = append(s.selectors, selectors...)
 }
 
-const maxLabelKeySize = 37
+// Len returns the number of the selectors.
+func (s *LabelSelectors) Len() int {
+	return len(s.selectors)
+}
+
+// CheckSize returns an error if there are more selectors than the labels LabelKeys can hold.
+// GetLabelKeys cuts off the selectors after maxLabelKeySize silently.
+func (s *LabelSelectors) CheckSize() error {
+	if len(s.selectors) > maxLabelKeySize {
+		return fmt.Errorf("%d labels are selected, but at most %d labels are supported", len(s.selectors), maxLabelKeySize)
+	}
+	return nil
+}
+
+const maxLabelKeySize = 64
 
 type LabelKeys struct {
 	// LabelKeys will be used as key of map, so it is must be an array instead of a slice.
-	// Now 34 is enough for all cases. If there is more than 34 labels, must increase this value.
+	// The net request metrics select 46 labels now, and the rest are left for the pod labels and
+	// annotations configured. If there are more than 64 labels, must increase this value.
 	keys [maxLabelKeySize]LabelKey
 }
 
@@ -104,6 +120,26 @@ func (k *LabelKeys) GetLabels() *model.AttributeMap {
 	return ret
 }
 
+// OverflowLabelValue is the value of the string labels of the overflow series.
+const OverflowLabelValue = "__overflow__"
+
+// Overflow returns the keys of the overflow series which the new label combinations are folded into
+// once the series limit is reached. The string labels are set to OverflowLabelValue and the int labels
+// are set to 0. The boolean labels are kept because they are low-cardinality and tell the kind of the
+// metrics, e.g. is_server.
+func (k *LabelKeys) Overflow() *LabelKeys {
+	ret := &LabelKeys{keys: k.keys}
+	for i := range ret.keys {
+		switch ret.keys[i].VType {
+		case StringType:
+			ret.keys[i].Value = OverflowLabelValue
+		case IntType:
+			ret.keys[i].Value = "0"
+		}
+	}
+	return ret
+}
+
 // GetLabelsKeys TODO Sort before you real need to use this key
 func GetLabelsKeys(attributeMap *model.AttributeMap) *LabelKeys {
 	keys := &LabelKeys{}
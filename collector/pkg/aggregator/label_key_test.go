@@ -2,6 +2,7 @@ package aggregator
 
 import (
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/Kindling-project/kindling/collector/pkg/model"
@@ -108,3 +109,32 @@ func TestLabelKeysMap(t *testing.T) {
 		t.Errorf("Expected 10, but got %v", value)
 	}
 }
+
+func TestLabelKeys_Overflow(t *testing.T) {
+	labelKeys := createLabelKeys()
+	want := NewLabelKeys([]LabelKey{
+		{Name: "stringKey", Value: OverflowLabelValue, VType: StringType},
+		{Name: "booleanKey", Value: "true", VType: BooleanType},
+		{Name: "intKey", Value: "0", VType: IntType},
+	}...)
+	if got := labelKeys.Overflow(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Overflow() = %v, want %v", got, want)
+	}
+	if labelKeys.keys[0].Value != "stringValue" {
+		t.Errorf("Overflow() must not modify the original keys")
+	}
+}
+
+func TestLabelSelectors_CheckSize(t *testing.T) {
+	selectors := NewLabelSelectors()
+	for i := 0; i < maxLabelKeySize; i++ {
+		selectors.AppendSelectors(LabelSelector{Name: "key" + strconv.Itoa(i), VType: StringType})
+	}
+	if err := selectors.CheckSize(); err != nil {
+		t.Errorf("CheckSize() = %v, want nil", err)
+	}
+	selectors.AppendSelectors(LabelSelector{Name: "overflow", VType: StringType})
+	if err := selectors.CheckSize(); err == nil {
+		t.Errorf("CheckSize() = nil, want an error")
+	}
+}
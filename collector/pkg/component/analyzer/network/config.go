@@ -1,5 +1,10 @@
 package network
 
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/custom"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
 const (
 	defaultFdReuseTimeout        = 15
 	defaultNoResponseThreshold   = 120
@@ -24,6 +29,12 @@ type Config struct {
 	ProtocolParser      []string         `mapstructure:"protocol_parser"`
 	ProtocolConfigs     []ProtocolConfig `mapstructure:"protocol_config,omitempty"`
 	UrlClusteringMethod string           `mapstructure:"url_clustering_method"`
+	// CustomProtocols are the protocols declared in the configuration. Their names could be used
+	// in ProtocolParser and ProtocolConfigs like the built-in ones.
+	CustomProtocols []custom.Spec `mapstructure:"custom_protocols"`
+	// UrlRules are the per-service rules to cluster the HTTP URLs. The content_key of the HTTP requests
+	// matched by them overrides the result of UrlClusteringMethod.
+	UrlRules []UrlRuleConfig `mapstructure:"url_rules"`
 }
 
 func NewDefaultConfig() *Config {
@@ -85,6 +96,17 @@ type ProtocolConfig struct {
 	Threshold      int      `mapstructure:"slow_threshold,omitempty"`
 }
 
+type UrlRuleConfig struct {
+	// WorkloadName matches the dst_workload_name of the requests. It works only when the Kubernetes
+	// metadata is enabled.
+	WorkloadName string   `mapstructure:"workload_name"`
+	Ports        []uint32 `mapstructure:"ports"`
+	// Rewrites are tried in order before the paths of the OpenAPI document.
+	Rewrites []urlclustering.RewriteRule `mapstructure:"rewrites"`
+	// OpenAPI is the path of an OpenAPI or Swagger document in YAML or JSON format.
+	OpenAPI string `mapstructure:"openapi"`
+}
+
 func (cfg *Config) GetConnectTimeout() int {
 	if cfg.ConnectTimeout > 0 {
 		return cfg.ConnectTimeout
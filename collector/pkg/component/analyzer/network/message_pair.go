@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 )
@@ -157,6 +158,21 @@ type messagePairs struct {
 	isSend           int32
 	mutex            sync.RWMutex // only for update latency and resval now
 	maxPayloadLength int
+
+	// The fields below are used only by the protocols multiplexing streams on one connection.
+	// They are passed to the next messagePairs until the connection is closed.
+	streamParser  *protocol.ProtocolParser
+	streamSession protocol.StreamSession
+	streams       map[uint32]*stream
+
+	// onewayParser is the parser which found one-way messages on the connection. It is passed to
+	// the next messagePairs so that the following one-way messages are distributed without waiting.
+	onewayParser *protocol.ProtocolParser
+	// noResponseChecked is the number of the request events known to expect no responses, and
+	// responseExpected is set once a request expecting the response is found. They are cached
+	// to avoid parsing the same requests again when the next request event arrives.
+	noResponseChecked int
+	responseExpected  bool
 }
 
 func (mps *messagePairs) checkSend() bool {
@@ -17,6 +17,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 
 	"go.uber.org/zap/zapcore"
@@ -43,9 +44,11 @@ type NetworkAnalyzer struct {
 	protocolMap      map[string]*protocol.ProtocolParser
 	parserFactory    *factory.ParserFactory
 	parsers          []*protocol.ProtocolParser
+	urlRules         *urlRules
 
 	dataGroupPool      DataGroupPool
 	requestMonitor     sync.Map
+	idleSessions       sync.Map
 	tcpMessagePairSize int64
 	udpMessagePairSize int64
 	telemetry          *component.TelemetryTools
@@ -127,6 +130,20 @@ func (na *NetworkAnalyzer) Start() error {
 		disableDisernProtocols[config.Key] = config.DisableDiscern
 	}
 
+	for _, spec := range na.cfg.CustomProtocols {
+		if err := na.parserFactory.AddCustomParser(spec); err != nil {
+			return err
+		}
+	}
+
+	if len(na.cfg.UrlRules) > 0 {
+		urlRules, err := newUrlRules(na.cfg.UrlRules, kubernetes.MetaDataCache)
+		if err != nil {
+			return err
+		}
+		na.urlRules = urlRules
+	}
+
 	na.protocolMap = map[string]*protocol.ProtocolParser{}
 	parsers := make([]*protocol.ProtocolParser, 0)
 	for _, protocolName := range na.cfg.ProtocolParser {
@@ -231,6 +248,7 @@ func (na *NetworkAnalyzer) consumerFdNoReusingTrace() {
 				}
 				return true
 			})
+			na.removeExpiredIdleSessions()
 		}
 	}
 }
@@ -243,6 +261,8 @@ func (na *NetworkAnalyzer) analyseConnect(evt *model.KindlingEvent) error {
 		mutex:            sync.RWMutex{},
 		maxPayloadLength: na.snaplen,
 	}
+	// A new connection is established on the fd.
+	na.idleSessions.Delete(mps.getKey())
 	if pairInterface, exist := na.requestMonitor.LoadOrStore(mps.getKey(), mps); exist {
 		// There is an old message pair
 		var oldPairs = pairInterface.(*messagePairs)
@@ -295,7 +315,7 @@ func (na *NetworkAnalyzer) analyseRequest(evt *model.KindlingEvent) error {
 			}
 		}
 
-		if oldPairs.responses != nil || oldPairs.requests.IsSportChanged(evt) {
+		if oldPairs.responses != nil || oldPairs.requests.IsSportChanged(evt) || na.isNextOnewayMessage(oldPairs, evt) {
 			_ = na.distributeTraceMetric(oldPairs, mps)
 		} else {
 			oldPairs.mergeRequest(evt)
@@ -306,6 +326,51 @@ func (na *NetworkAnalyzer) analyseRequest(evt *model.KindlingEvent) error {
 	return nil
 }
 
+// isNextOnewayMessage returns true if the old requests are one-way or ignored messages and the new event
+// starts another message. The old requests expect no responses, so they are distributed instead of being merged.
+func (na *NetworkAnalyzer) isNextOnewayMessage(oldPairs *messagePairs, evt *model.KindlingEvent) bool {
+	parser := oldPairs.onewayParser
+	if parser == nil {
+		if staticProtocol, found := na.staticPortMap[oldPairs.getPort()]; found {
+			parser = na.protocolMap[staticProtocol]
+		}
+	}
+	if parser == nil || parser.MultiStreams() {
+		return false
+	}
+
+	if oldPairs.responseExpected {
+		return false
+	}
+	l4Protocol := evt.GetCtx().GetFdInfo().GetProtocol()
+	if parser.MultiRequests() {
+		// Every request is parsed alone, so all of them must be one-way.
+		// The requests checked before are not parsed again.
+		for i := oldPairs.noResponseChecked; i < oldPairs.requests.size(); i++ {
+			requestMsg := protocol.NewRequestMessage(oldPairs.requests.getEvent(i).GetData(), l4Protocol)
+			if !parser.ParseRequest(requestMsg) || !expectsNoResponse(requestMsg) {
+				oldPairs.responseExpected = true
+				return false
+			}
+			oldPairs.noResponseChecked++
+		}
+	} else if oldPairs.noResponseChecked == 0 {
+		// The events merged later are the rest of the message, which don't change its type.
+		requestMsg := protocol.NewRequestMessage(oldPairs.requests.getData(), l4Protocol)
+		if !parser.ParseRequest(requestMsg) || !expectsNoResponse(requestMsg) {
+			oldPairs.responseExpected = true
+			return false
+		}
+		oldPairs.noResponseChecked = oldPairs.requests.size()
+	}
+	// The new event may be the rest of the old message if it can't be parsed alone.
+	return parser.ParseRequest(protocol.NewRequestMessage(evt.GetData(), l4Protocol))
+}
+
+func expectsNoResponse(message *protocol.PayloadMessage) bool {
+	return message.IsOneway() || message.IsIgnored()
+}
+
 func (na *NetworkAnalyzer) analyseResponse(evt *model.KindlingEvent) error {
 	pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(evt))
 	if !ok {
@@ -362,7 +427,25 @@ func (na *NetworkAnalyzer) distributeTraceMetric(oldPairs *messagePairs, newPair
 	// Case 2 Request 498   Connect/Request                         Request
 	// Case 3 Normal             Connect/Request/Response   Request/Response
 	records := na.parseProtocols(oldPairs)
+	if oldPairs.streams != nil {
+		if newPairs != nil && newPairs.connects == nil {
+			// The connection is still in use, so the unfinished streams wait for their responses.
+			newPairs.inheritStreams(oldPairs)
+		} else {
+			records = append(records, na.getUnfinishedStreamRecords(oldPairs)...)
+		}
+	}
+	if newPairs == nil {
+		// The connection is idle but not closed, so the session is kept for its next messages.
+		na.keepIdleSession(oldPairs)
+	}
+	if oldPairs.onewayParser != nil && newPairs != nil && newPairs.onewayParser == nil {
+		newPairs.onewayParser = oldPairs.onewayParser
+	}
 	for _, record := range records {
+		if na.urlRules != nil {
+			na.urlRules.apply(record)
+		}
 		if ce := na.telemetry.Logger.Check(zapcore.DebugLevel, ""); ce != nil {
 			na.telemetry.Logger.Debug("NetworkAnalyzer To NextProcess:\n" + record.String())
 		}
@@ -376,6 +459,12 @@ func (na *NetworkAnalyzer) distributeTraceMetric(oldPairs *messagePairs, newPair
 }
 
 func (na *NetworkAnalyzer) parseProtocols(mps *messagePairs) []*model.DataGroup {
+	na.restoreIdleSession(mps)
+	// Step 0: The connection has been parsed by a protocol multiplexing streams
+	if mps.streamParser != nil && mps.requests != nil {
+		return na.parseMultipleStreams(mps, mps.streamParser)
+	}
+
 	// Step 1:  Static Config for port and protocol set in config file
 	port := mps.getPort()
 	staticProtocol, found := na.staticPortMap[port]
@@ -436,6 +525,9 @@ func (na *NetworkAnalyzer) parseProtocols(mps *messagePairs) []*model.DataGroup
 }
 
 func (na *NetworkAnalyzer) parseProtocol(mps *messagePairs, parser *protocol.ProtocolParser) []*model.DataGroup {
+	if parser.MultiStreams() {
+		return na.parseMultipleStreams(mps, parser)
+	}
 	if parser.MultiRequests() {
 		// Not mergable requests
 		return na.parseMultipleRequests(mps, parser)
@@ -449,9 +541,12 @@ func (na *NetworkAnalyzer) parseProtocol(mps *messagePairs, parser *protocol.Pro
 	}
 
 	if mps.responses == nil {
-		if requestMsg.GetAttributes().GetBoolValue(constlabels.Oneway) {
+		if requestMsg.IsIgnored() {
 			return []*model.DataGroup{}
 		}
+		if requestMsg.IsOneway() {
+			mps.onewayParser = parser
+		}
 		return na.getRecords(mps, parser.GetProtocol(), requestMsg.GetAttributes())
 	}
 
@@ -464,7 +559,7 @@ func (na *NetworkAnalyzer) parseProtocol(mps *messagePairs, parser *protocol.Pro
 }
 
 // parseMultipleRequests parses the messagePairs when we know there could be multiple read requests.
-// This is used only when the protocol is DNS now.
+// This is used when the protocol is DNS or AMQP now.
 func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *protocol.ProtocolParser) []*model.DataGroup {
 	// Match with key when disordering.
 	size := mps.requests.size()
@@ -476,6 +571,9 @@ func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *prot
 			// Parse failure
 			return nil
 		}
+		if requestMsg.IsOneway() {
+			mps.onewayParser = parser
+		}
 		parsedReqMsgs[i] = requestMsg
 	}
 
@@ -483,6 +581,9 @@ func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *prot
 	if mps.responses == nil {
 		size := mps.requests.size()
 		for i := 0; i < size; i++ {
+			if parsedReqMsgs[i].IsIgnored() {
+				continue
+			}
 			req := mps.requests.getEvent(i)
 			mp := &messagePair{
 				request:  req,
@@ -502,11 +603,25 @@ func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *prot
 				// Parse failure
 				return nil
 			}
+			if responseMsg.IsIgnored() {
+				continue
+			}
 			// Match Request with response
 			matchIdx := parser.PairMatch(parsedReqMsgs, responseMsg)
-			if matchIdx == -1 {
+			if matchIdx == protocol.PairMatchFail {
 				return nil
 			}
+			if matchIdx == protocol.PairMatchOneway {
+				// The one-way response is reported as a message without response.
+				responseMsg.MarkOneway()
+				mp := &messagePair{
+					request:  resp,
+					response: nil,
+					natTuple: mps.natTuple,
+				}
+				records = append(records, na.getRecordWithSinglePair(mp, parser.GetProtocol(), responseMsg.GetAttributes()))
+				continue
+			}
 			matchedRequestIdx[matchIdx] = true
 
 			mp := &messagePair{
@@ -514,13 +629,16 @@ func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *prot
 				response: resp,
 				natTuple: mps.natTuple,
 			}
-			records = append(records, na.getRecordWithSinglePair(mp, parser.GetProtocol(), responseMsg.GetAttributes()))
+			// The attributes of the request describe the operation, and the response adds the result.
+			attributes := responseMsg.GetAttributes()
+			attributes.Merge(parsedReqMsgs[matchIdx].GetAttributes())
+			records = append(records, na.getRecordWithSinglePair(mp, parser.GetProtocol(), attributes))
 		}
 		// 498 Case
 		reqSize := mps.requests.size()
 		for i := 0; i < reqSize; i++ {
 			req := mps.requests.getEvent(i)
-			if _, matched := matchedRequestIdx[i]; !matched {
+			if _, matched := matchedRequestIdx[i]; !matched && !parsedReqMsgs[i].IsIgnored() {
 				mp := &messagePair{
 					request:  req,
 					response: nil,
@@ -604,8 +722,10 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 		addProtocolPayload(protocol, labels, mps.requests.getData(), mps.responses.getData())
 	}
 
+	// The one-way message is completed once it is sent.
+	oneway := mps.responses == nil && labels.GetBoolValue(constlabels.Oneway)
 	// If no protocol error found, we check other errors
-	if !labels.GetBoolValue(constlabels.IsError) && mps.responses == nil {
+	if !labels.GetBoolValue(constlabels.IsError) && mps.responses == nil && !oneway {
 		labels.AddBoolValue(constlabels.IsError, true)
 		labels.AddIntValue(constlabels.ErrorType, int64(constlabels.NoResponse))
 	}
@@ -617,9 +737,15 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 
 	ret.UpdateAddIntMetric(constvalues.ConnectTime, int64(mps.getConnectDuration()))
 	ret.UpdateAddIntMetric(constvalues.RequestSentTime, mps.getSentTime())
-	ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, mps.getWaitingTime())
-	ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, mps.getDownloadTime())
-	ret.UpdateAddIntMetric(constvalues.RequestTotalTime, int64(mps.getConnectDuration()+mps.getDuration()))
+	if oneway {
+		ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, 0)
+		ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, 0)
+		ret.UpdateAddIntMetric(constvalues.RequestTotalTime, int64(mps.getConnectDuration())+mps.getSentTime())
+	} else {
+		ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, mps.getWaitingTime())
+		ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, mps.getDownloadTime())
+		ret.UpdateAddIntMetric(constvalues.RequestTotalTime, int64(mps.getConnectDuration()+mps.getDuration()))
+	}
 	ret.UpdateAddIntMetric(constvalues.RequestIo, int64(mps.getRquestSize()))
 	ret.UpdateAddIntMetric(constvalues.ResponseIo, int64(mps.getResponseSize()))
 
@@ -630,7 +756,7 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 
 // getRecordWithSinglePair generates a record whose metrics are copied from the input messagePair,
 // instead of messagePairs. This is used only when there could be multiple real requests in messagePairs.
-// For now, only messagePairs with DNS protocol would run into this method.
+// For now, only messagePairs with DNS or AMQP protocol would run into this method.
 func (na *NetworkAnalyzer) getRecordWithSinglePair(mp *messagePair, protocol string, attributes *model.AttributeMap) *model.DataGroup {
 	evt := mp.request
 
@@ -663,8 +789,10 @@ func (na *NetworkAnalyzer) getRecordWithSinglePair(mp *messagePair, protocol str
 		addProtocolPayload(protocol, labels, evt.GetData(), mp.response.GetData())
 	}
 
+	// The one-way message is completed once it is sent.
+	oneway := mp.response == nil && labels.GetBoolValue(constlabels.Oneway)
 	// If no protocol error found, we check other errors
-	if !labels.GetBoolValue(constlabels.IsError) && mp.response == nil {
+	if !labels.GetBoolValue(constlabels.IsError) && mp.response == nil && !oneway {
 		labels.AddBoolValue(constlabels.IsError, true)
 		labels.AddIntValue(constlabels.ErrorType, int64(constlabels.NoResponse))
 	}
@@ -676,9 +804,15 @@ func (na *NetworkAnalyzer) getRecordWithSinglePair(mp *messagePair, protocol str
 
 	ret.UpdateAddIntMetric(constvalues.ConnectTime, 0)
 	ret.UpdateAddIntMetric(constvalues.RequestSentTime, mp.getSentTime())
-	ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, mp.getWaitingTime())
-	ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, mp.getDownloadTime())
-	ret.UpdateAddIntMetric(constvalues.RequestTotalTime, int64(mp.getDuration()))
+	if oneway {
+		ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, 0)
+		ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, 0)
+		ret.UpdateAddIntMetric(constvalues.RequestTotalTime, mp.getSentTime())
+	} else {
+		ret.UpdateAddIntMetric(constvalues.WaitingTtfbTime, mp.getWaitingTime())
+		ret.UpdateAddIntMetric(constvalues.ContentDownloadTime, mp.getDownloadTime())
+		ret.UpdateAddIntMetric(constvalues.RequestTotalTime, int64(mp.getDuration()))
+	}
 	ret.UpdateAddIntMetric(constvalues.RequestIo, int64(mp.getRquestSize()))
 	ret.UpdateAddIntMetric(constvalues.ResponseIo, int64(mp.getResponseSize()))
 
@@ -1,9 +1,11 @@
 package network
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sort"
 	"strings"
@@ -11,12 +13,16 @@ import (
 	"testing"
 
 	viperpackage "github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2/hpack"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/amqp"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
 
 func TestHttpProtocol(t *testing.T) {
@@ -39,6 +45,198 @@ func TestMySqlProtocol(t *testing.T) {
 	)
 }
 
+func TestHttp2Protocol(t *testing.T) {
+	testProtocol(t, "http2/server-event.yml",
+		"http2/server-trace-grpc-unary.yml",
+		"http2/server-trace-grpc-concurrent.yml",
+		"http2/server-trace-grpc-interleaved.yml",
+		"http2/server-trace-http2.yml",
+	)
+}
+
+func TestHttp2SessionOfIdleConnection(t *testing.T) {
+	na := prepareNetworkAnalyzer()
+	eventCommon := getEventCommon("protocol/testdata/http2/server-event.yml")
+	requestEncoder := newHeadersEncoder()
+	responseEncoder := newHeadersEncoder()
+	request := func(streamId uint32) []byte {
+		return http2Frame(0x1, 0x5, streamId, requestEncoder.encode(":method", "GET", ":scheme", "http",
+			":path", "/api/orders/42", ":authority", "localhost:50051"))
+	}
+	response := func(streamId uint32) []byte {
+		return http2Frame(0x1, 0x5, streamId, responseEncoder.encode(":status", "200", "server", "greeter"))
+	}
+	exchange := func(common *EventCommon, request []byte, response []byte, timestamp uint64) []*model.DataGroup {
+		results = []*model.DataGroup{}
+		requestEvent := newTestEvent(common, "read", timestamp, request)
+		_ = na.ConsumeEvent(requestEvent)
+		_ = na.ConsumeEvent(newTestEvent(common, "write", timestamp+1000000, response))
+		// The connection is idle longer than fd_reuse_timeout.
+		if pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(requestEvent)); ok {
+			_ = na.distributeTraceMetric(pairInterface.(*messagePairs), nil)
+		}
+		return results
+	}
+
+	preface := append([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"), http2Frame(0x4, 0, 0, nil)...)
+	records := exchange(eventCommon, append(preface, request(1)...), response(1), 100000000)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "/api/orders/42", records[0].Labels.GetStringValue(constlabels.HttpUrl))
+	}
+	// The fields of the next request refer to the dynamic table built by the first request.
+	records = exchange(eventCommon, request(3), response(3), 200000000)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "/api/orders/42", records[0].Labels.GetStringValue(constlabels.HttpUrl))
+		assert.Equal(t, int64(200), records[0].Labels.GetIntValue(constlabels.HttpStatusCode))
+	}
+
+	// The fd is reused by another connection whose dynamic table is unknown, so the request
+	// is reported without the headers.
+	anotherCommon := *eventCommon
+	anotherCommon.Ctx.Fd.Sport++
+	records = exchange(&anotherCommon, request(5), response(5), 300000000)
+	if assert.Len(t, records, 1) {
+		assert.False(t, records[0].Labels.HasAttribute(constlabels.HttpUrl))
+	}
+}
+
+func TestHttp2UnknownFrameTypes(t *testing.T) {
+	na := prepareNetworkAnalyzer()
+	eventCommon := getEventCommon("protocol/testdata/http2/server-event.yml")
+	// PRIORITY_UPDATE (0x10) is unknown to the parser, so it is skipped by its length.
+	priorityUpdate := http2Frame(0x10, 0, 0, []byte{0, 0, 0, 1, 'u', '=', '0'})
+	request := append(priorityUpdate, http2Frame(0x1, 0x5, 1, newHeadersEncoder().encode(":method", "GET",
+		":scheme", "http", ":path", "/api/orders/42", ":authority", "localhost:50051"))...)
+	response := append(http2Frame(0x1, 0x4, 1, newHeadersEncoder().encode(":status", "200")),
+		append(http2Frame(0xa, 0, 1, nil), http2Frame(0x0, 0x1, 1, []byte("ok"))...)...)
+
+	results = []*model.DataGroup{}
+	requestEvent := newTestEvent(eventCommon, "read", 100000000, request)
+	_ = na.ConsumeEvent(requestEvent)
+	_ = na.ConsumeEvent(newTestEvent(eventCommon, "write", 101000000, response))
+	if pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(requestEvent)); ok {
+		_ = na.distributeTraceMetric(pairInterface.(*messagePairs), nil)
+	}
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "/api/orders/42", results[0].Labels.GetStringValue(constlabels.HttpUrl))
+		assert.Equal(t, int64(200), results[0].Labels.GetIntValue(constlabels.HttpStatusCode))
+	}
+}
+
+type headersEncoder struct {
+	buf     *bytes.Buffer
+	encoder *hpack.Encoder
+}
+
+func newHeadersEncoder() *headersEncoder {
+	buf := &bytes.Buffer{}
+	return &headersEncoder{buf: buf, encoder: hpack.NewEncoder(buf)}
+}
+
+func (e *headersEncoder) encode(fields ...string) []byte {
+	e.buf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		_ = e.encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte{}, e.buf.Bytes()...)
+}
+
+func http2Frame(typ byte, flags byte, streamId uint32, payload []byte) []byte {
+	frame := []byte{byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload)), typ, flags}
+	frame = binary.BigEndian.AppendUint32(frame, streamId)
+	return append(frame, payload...)
+}
+
+func newTestEvent(common *EventCommon, name string, timestamp uint64, data []byte) *model.KindlingEvent {
+	evt := &TraceEvent{
+		Name:      name,
+		Timestamp: timestamp,
+		UserAttributes: UserAttributes{
+			Latency: 1000,
+			Res:     int64(len(data)),
+			Data:    []string{"hex|" + hex.EncodeToString(data)},
+		},
+	}
+	return evt.exchange(common)
+}
+
+func TestPostgresqlProtocol(t *testing.T) {
+	testProtocol(t, "postgresql/server-event.yml",
+		"postgresql/server-trace-query.yml",
+		"postgresql/server-trace-extended-query.yml",
+		"postgresql/server-trace-error.yml",
+		"postgresql/server-trace-terminate.yml",
+	)
+}
+
+func TestMongodbProtocol(t *testing.T) {
+	testProtocol(t, "mongodb/server-event.yml",
+		"mongodb/server-trace-find.yml",
+		"mongodb/server-trace-error.yml",
+		"mongodb/server-trace-write-error.yml",
+		"mongodb/server-trace-op-query.yml",
+	)
+}
+
+func TestMemcachedProtocol(t *testing.T) {
+	testProtocol(t, "memcached/server-event.yml",
+		"memcached/server-trace-get-multi.yml",
+		"memcached/server-trace-set.yml",
+		"memcached/server-trace-server-error.yml",
+		"memcached/server-trace-binary-get-multi.yml",
+	)
+}
+
+func TestAmqpProtocol(t *testing.T) {
+	testProtocol(t, "amqp/server-event.yml",
+		"amqp/server-trace-publish.yml",
+		"amqp/server-trace-get.yml",
+		"amqp/server-trace-consume-deliver.yml",
+		"amqp/server-trace-declare-error.yml",
+		"amqp/server-trace-heartbeat.yml",
+	)
+}
+
+func TestIsNextOnewayMessage(t *testing.T) {
+	na := prepareNetworkAnalyzer()
+	eventCommon := getEventCommon("protocol/testdata/amqp/server-event.yml")
+	publish, _ := hex.DecodeString("0100010000001c003c00280000066f72646572730d6f726465722e6372656174656400ce0200010000000f003c00000000000000000011100002ce030001000000117b226f726465725f6964223a313030317dce")
+	basicGet, _ := hex.DecodeString("0100010000000d003c00460000057461736b7300ce")
+
+	mps := &messagePairs{requests: newEvents(newTestEvent(eventCommon, "read", 100000000, publish), na.snaplen)}
+	assert.True(t, na.isNextOnewayMessage(mps, newTestEvent(eventCommon, "read", 200000000, publish)))
+	assert.Equal(t, 1, mps.noResponseChecked)
+
+	// The result is kept, so the request is not parsed again for the following events.
+	mps = &messagePairs{requests: newEvents(newTestEvent(eventCommon, "read", 100000000, basicGet), na.snaplen)}
+	assert.False(t, na.isNextOnewayMessage(mps, newTestEvent(eventCommon, "read", 200000000, publish)))
+	assert.True(t, mps.responseExpected)
+	mps.requests.mergeEvent(newTestEvent(eventCommon, "read", 200000000, publish))
+	assert.False(t, na.isNextOnewayMessage(mps, newTestEvent(eventCommon, "read", 300000000, publish)))
+	assert.Equal(t, 0, mps.noResponseChecked)
+}
+
+func TestAmqpRandomBytes(t *testing.T) {
+	parser := amqp.NewAmqpParser()
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		// The content header and body frames start with 0x02 and 0x03.
+		data := make([]byte, 8+random.Intn(256))
+		random.Read(data)
+		data[0] = byte(2 + i%2)
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data, model.L4Proto_TCP)), "request %x", data)
+		assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(data, model.NewAttributeMap(), model.L4Proto_TCP)), "response %x", data)
+	}
+	// The content frames without the method are not recognized even if they are well-formed.
+	body, _ := hex.DecodeString("03000100000005776f726c64ce")
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(body, model.L4Proto_TCP)))
+}
+
+func TestCustomProtocol(t *testing.T) {
+	testProtocol(t, "custom/server-event.yml",
+		"custom/server-trace-myrpc.yml")
+}
+
 func TestRedisProtocol(t *testing.T) {
 	testProtocol(t, "redis/server-event.yml",
 		"redis/server-trace-get.yml")
@@ -55,7 +253,8 @@ func TestDnsProtocol(t *testing.T) {
 
 func TestKafkaProtocol(t *testing.T) {
 	testProtocol(t, "kafka/provider-event.yml",
-		"kafka/provider-trace-produce-split.yml")
+		"kafka/provider-trace-produce-split.yml",
+		"kafka/provider-trace-produce-acks0.yml")
 
 	testProtocol(t, "kafka/consumer-event.yml",
 		"kafka/consumer-trace-fetch-split.yml",
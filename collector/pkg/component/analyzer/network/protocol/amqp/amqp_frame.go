@@ -0,0 +1,152 @@
+package amqp
+
+import (
+	"encoding/binary"
+)
+
+/*
+https://www.rabbitmq.com/resources/specs/amqp0-9-1.pdf
+
+===== Frame =====
+uint8	type		1 for method, 2 for content header, 3 for content body, 8 for heartbeat
+uint16	channel
+uint32	size
+byte[]	payload
+uint8	frame-end	0xCE
+
+===== Method Payload =====
+uint16	class-id
+uint16	method-id
+byte[]	arguments
+*/
+const (
+	frameHeaderLength = 7
+
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+
+	frameEnd = 0xCE
+
+	// The frame_max negotiated is 128KB by default, and it could not exceed 2^32-1.
+	// The larger one is considered invalid to avoid misrecognition.
+	maxFrameSize = 1 << 27
+)
+
+// protocolHeader is sent by the client at the beginning of the connection.
+var protocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}
+
+type frame struct {
+	typ     byte
+	channel uint16
+	// payload could be truncated.
+	payload []byte
+	// end is the offset of the next frame.
+	end int
+}
+
+// readFrame reads the frame starting from offset. Only the payload of a method frame could be truncated
+// at the end of the data, because the other frames are too loose to be told from random bytes
+// without checking their frame-end octets.
+func readFrame(data []byte, offset int) (*frame, bool) {
+	if offset+frameHeaderLength > len(data) {
+		return nil, false
+	}
+	f := &frame{
+		typ:     data[offset],
+		channel: binary.BigEndian.Uint16(data[offset+1:]),
+	}
+	size := int(binary.BigEndian.Uint32(data[offset+3:]))
+	switch f.typ {
+	case frameMethod:
+		if size < 4 {
+			return nil, false
+		}
+	case frameHeader, frameBody:
+	case frameHeartbeat:
+		if f.channel != 0 || size != 0 {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	if size > maxFrameSize {
+		return nil, false
+	}
+	payloadStart := offset + frameHeaderLength
+	f.end = payloadStart + size + 1
+	if f.end <= len(data) {
+		if data[f.end-1] != frameEnd {
+			return nil, false
+		}
+		f.payload = data[payloadStart : f.end-1]
+	} else if f.typ == frameMethod {
+		f.payload = data[payloadStart:]
+	} else {
+		return nil, false
+	}
+	return f, true
+}
+
+// argumentReader reads the arguments of the method. All the reads fail after the arguments are truncated.
+type argumentReader struct {
+	data   []byte
+	offset int
+	ok     bool
+}
+
+func newArgumentReader(data []byte) *argumentReader {
+	return &argumentReader{data: data, ok: true}
+}
+
+func (r *argumentReader) has(length int) bool {
+	r.ok = r.ok && r.offset+length <= len(r.data)
+	return r.ok
+}
+
+func (r *argumentReader) readOctet() byte {
+	if !r.has(1) {
+		return 0
+	}
+	value := r.data[r.offset]
+	r.offset++
+	return value
+}
+
+func (r *argumentReader) readShort() uint16 {
+	if !r.has(2) {
+		return 0
+	}
+	value := binary.BigEndian.Uint16(r.data[r.offset:])
+	r.offset += 2
+	return value
+}
+
+func (r *argumentReader) readLong() uint32 {
+	if !r.has(4) {
+		return 0
+	}
+	value := binary.BigEndian.Uint32(r.data[r.offset:])
+	r.offset += 4
+	return value
+}
+
+func (r *argumentReader) readLongLong() uint64 {
+	if !r.has(8) {
+		return 0
+	}
+	value := binary.BigEndian.Uint64(r.data[r.offset:])
+	r.offset += 8
+	return value
+}
+
+func (r *argumentReader) readShortString() string {
+	length := int(r.readOctet())
+	if !r.has(length) {
+		return ""
+	}
+	value := string(r.data[r.offset : r.offset+length])
+	r.offset += length
+	return value
+}
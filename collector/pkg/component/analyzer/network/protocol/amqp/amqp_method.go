@@ -0,0 +1,198 @@
+package amqp
+
+type methodId uint32
+
+func newMethodId(classId uint16, id uint16) methodId {
+	return methodId(uint32(classId)<<16 | uint32(id))
+}
+
+var (
+	// protocolHeaderId is a pseudo id of the protocol header which is replied with connection.start.
+	protocolHeaderId = newMethodId(0, 0)
+
+	connectionStart     = newMethodId(10, 10)
+	connectionStartOk   = newMethodId(10, 11)
+	connectionSecure    = newMethodId(10, 20)
+	connectionSecureOk  = newMethodId(10, 21)
+	connectionTune      = newMethodId(10, 30)
+	connectionTuneOk    = newMethodId(10, 31)
+	connectionOpen      = newMethodId(10, 40)
+	connectionOpenOk    = newMethodId(10, 41)
+	connectionClose     = newMethodId(10, 50)
+	connectionCloseOk   = newMethodId(10, 51)
+	connectionBlocked   = newMethodId(10, 60)
+	connectionUnblocked = newMethodId(10, 61)
+
+	channelOpen    = newMethodId(20, 10)
+	channelOpenOk  = newMethodId(20, 11)
+	channelFlow    = newMethodId(20, 20)
+	channelFlowOk  = newMethodId(20, 21)
+	channelClose   = newMethodId(20, 40)
+	channelCloseOk = newMethodId(20, 41)
+
+	exchangeDeclare   = newMethodId(40, 10)
+	exchangeDeclareOk = newMethodId(40, 11)
+	exchangeDelete    = newMethodId(40, 20)
+	exchangeDeleteOk  = newMethodId(40, 21)
+	exchangeBind      = newMethodId(40, 30)
+	exchangeBindOk    = newMethodId(40, 31)
+	exchangeUnbind    = newMethodId(40, 40)
+	exchangeUnbindOk  = newMethodId(40, 51)
+
+	queueDeclare   = newMethodId(50, 10)
+	queueDeclareOk = newMethodId(50, 11)
+	queueBind      = newMethodId(50, 20)
+	queueBindOk    = newMethodId(50, 21)
+	queuePurge     = newMethodId(50, 30)
+	queuePurgeOk   = newMethodId(50, 31)
+	queueDelete    = newMethodId(50, 40)
+	queueDeleteOk  = newMethodId(50, 41)
+	queueUnbind    = newMethodId(50, 50)
+	queueUnbindOk  = newMethodId(50, 51)
+
+	basicQos          = newMethodId(60, 10)
+	basicQosOk        = newMethodId(60, 11)
+	basicConsume      = newMethodId(60, 20)
+	basicConsumeOk    = newMethodId(60, 21)
+	basicCancel       = newMethodId(60, 30)
+	basicCancelOk     = newMethodId(60, 31)
+	basicPublish      = newMethodId(60, 40)
+	basicReturn       = newMethodId(60, 50)
+	basicDeliver      = newMethodId(60, 60)
+	basicGet          = newMethodId(60, 70)
+	basicGetOk        = newMethodId(60, 71)
+	basicGetEmpty     = newMethodId(60, 72)
+	basicAck          = newMethodId(60, 80)
+	basicReject       = newMethodId(60, 90)
+	basicRecoverAsync = newMethodId(60, 100)
+	basicRecover      = newMethodId(60, 110)
+	basicRecoverOk    = newMethodId(60, 111)
+	basicNack         = newMethodId(60, 120)
+
+	confirmSelect   = newMethodId(85, 10)
+	confirmSelectOk = newMethodId(85, 11)
+
+	txSelect     = newMethodId(90, 10)
+	txSelectOk   = newMethodId(90, 11)
+	txCommit     = newMethodId(90, 20)
+	txCommitOk   = newMethodId(90, 21)
+	txRollback   = newMethodId(90, 30)
+	txRollbackOk = newMethodId(90, 31)
+)
+
+var methodNames = map[methodId]string{
+	protocolHeaderId:    "protocol-header",
+	connectionStart:     "connection.start",
+	connectionStartOk:   "connection.start-ok",
+	connectionSecure:    "connection.secure",
+	connectionSecureOk:  "connection.secure-ok",
+	connectionTune:      "connection.tune",
+	connectionTuneOk:    "connection.tune-ok",
+	connectionOpen:      "connection.open",
+	connectionOpenOk:    "connection.open-ok",
+	connectionClose:     "connection.close",
+	connectionCloseOk:   "connection.close-ok",
+	connectionBlocked:   "connection.blocked",
+	connectionUnblocked: "connection.unblocked",
+	channelOpen:         "channel.open",
+	channelOpenOk:       "channel.open-ok",
+	channelFlow:         "channel.flow",
+	channelFlowOk:       "channel.flow-ok",
+	channelClose:        "channel.close",
+	channelCloseOk:      "channel.close-ok",
+	exchangeDeclare:     "exchange.declare",
+	exchangeDeclareOk:   "exchange.declare-ok",
+	exchangeDelete:      "exchange.delete",
+	exchangeDeleteOk:    "exchange.delete-ok",
+	exchangeBind:        "exchange.bind",
+	exchangeBindOk:      "exchange.bind-ok",
+	exchangeUnbind:      "exchange.unbind",
+	exchangeUnbindOk:    "exchange.unbind-ok",
+	queueDeclare:        "queue.declare",
+	queueDeclareOk:      "queue.declare-ok",
+	queueBind:           "queue.bind",
+	queueBindOk:         "queue.bind-ok",
+	queuePurge:          "queue.purge",
+	queuePurgeOk:        "queue.purge-ok",
+	queueDelete:         "queue.delete",
+	queueDeleteOk:       "queue.delete-ok",
+	queueUnbind:         "queue.unbind",
+	queueUnbindOk:       "queue.unbind-ok",
+	basicQos:            "basic.qos",
+	basicQosOk:          "basic.qos-ok",
+	basicConsume:        "basic.consume",
+	basicConsumeOk:      "basic.consume-ok",
+	basicCancel:         "basic.cancel",
+	basicCancelOk:       "basic.cancel-ok",
+	basicPublish:        "basic.publish",
+	basicReturn:         "basic.return",
+	basicDeliver:        "basic.deliver",
+	basicGet:            "basic.get",
+	basicGetOk:          "basic.get-ok",
+	basicGetEmpty:       "basic.get-empty",
+	basicAck:            "basic.ack",
+	basicReject:         "basic.reject",
+	basicRecoverAsync:   "basic.recover-async",
+	basicRecover:        "basic.recover",
+	basicRecoverOk:      "basic.recover-ok",
+	basicNack:           "basic.nack",
+	confirmSelect:       "confirm.select",
+	confirmSelectOk:     "confirm.select-ok",
+	txSelect:            "tx.select",
+	txSelectOk:          "tx.select-ok",
+	txCommit:            "tx.commit",
+	txCommitOk:          "tx.commit-ok",
+	txRollback:          "tx.rollback",
+	txRollbackOk:        "tx.rollback-ok",
+}
+
+var methodIds = make(map[string]methodId, len(methodNames))
+
+func init() {
+	for id, name := range methodNames {
+		methodIds[name] = id
+	}
+}
+
+// synchronousReplies are the replies of the synchronous methods sent by the client.
+// The other methods sent by the client are one-way, and so are the ones with the no-wait bit set.
+// The server could also reply any synchronous method with channel.close if the method fails.
+var synchronousReplies = map[methodId][]methodId{
+	protocolHeaderId: {connectionStart},
+	// The server closes the connection if the virtual host is not accessible.
+	connectionOpen:  {connectionOpenOk, connectionClose},
+	connectionClose: {connectionCloseOk},
+	channelOpen:     {channelOpenOk},
+	channelFlow:     {channelFlowOk},
+	channelClose:    {channelCloseOk},
+	exchangeDeclare: {exchangeDeclareOk},
+	exchangeDelete:  {exchangeDeleteOk},
+	exchangeBind:    {exchangeBindOk},
+	exchangeUnbind:  {exchangeUnbindOk},
+	queueDeclare:    {queueDeclareOk},
+	queueBind:       {queueBindOk},
+	queuePurge:      {queuePurgeOk},
+	queueDelete:     {queueDeleteOk},
+	queueUnbind:     {queueUnbindOk},
+	basicQos:        {basicQosOk},
+	basicConsume:    {basicConsumeOk},
+	basicCancel:     {basicCancelOk},
+	basicGet:        {basicGetOk, basicGetEmpty},
+	basicRecover:    {basicRecoverOk},
+	confirmSelect:   {confirmSelectOk},
+	txSelect:        {txSelectOk},
+	txCommit:        {txCommitOk},
+	txRollback:      {txRollbackOk},
+}
+
+func isReplyOf(request methodId, response methodId) bool {
+	if response == channelClose && request != channelClose {
+		return true
+	}
+	for _, reply := range synchronousReplies[request] {
+		if reply == response {
+			return true
+		}
+	}
+	return false
+}
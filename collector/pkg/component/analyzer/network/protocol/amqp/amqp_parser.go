@@ -0,0 +1,251 @@
+package amqp
+
+import (
+	"bytes"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+/*
+The messages of AMQP 0-9-1 are not always paired. The publishes and acks sent by the client and the deliveries
+sent by the server are one-way, while the other methods like queue.declare and basic.get are replied
+synchronously on the same channel. So each message is parsed separately and the replies are paired with
+the requests by their channels and methods.
+
+	    Request             Response
+	   /       \               |
+	Header   Frames          Frames
+*/
+func NewAmqpParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailAmqp(), parseAmqp())
+	requestParser.Add(fastfailProtocolHeader(), parseProtocolHeader())
+	requestParser.Add(fastfailFrames(), parseFrames(true))
+
+	responseParser := protocol.CreatePkgParser(fastfailAmqp(), parseAmqp())
+	responseParser.Add(fastfailFrames(), parseFrames(false))
+
+	return protocol.NewProtocolParser(protocol.AMQP, requestParser, responseParser, amqpPair())
+}
+
+func amqpPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		responseMethod, ok := methodIds[response.GetStringAttribute(constlabels.AmqpMethod)]
+		if !ok {
+			return protocol.PairMatchOneway
+		}
+		for i, request := range requests {
+			if request.IsOneway() ||
+				request.GetIntAttribute(constlabels.AmqpChannel) != response.GetIntAttribute(constlabels.AmqpChannel) {
+				continue
+			}
+			if isReplyOf(methodIds[request.GetStringAttribute(constlabels.AmqpMethod)], responseMethod) {
+				return i
+			}
+		}
+		// The messages sent by the server actively, e.g. basic.deliver.
+		return protocol.PairMatchOneway
+	}
+}
+
+func fastfailAmqp() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < frameHeaderLength
+	}
+}
+
+func parseAmqp() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		return true, false
+	}
+}
+
+func fastfailProtocolHeader() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !bytes.HasPrefix(message.Data, protocolHeader)
+	}
+}
+
+func parseProtocolHeader() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		addMethodAttributes(message, 0, protocolHeaderId)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, methodNames[protocolHeaderId])
+		return true, true
+	}
+}
+
+func fastfailFrames() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		_, ok := readFrame(message.Data, 0)
+		return !ok
+	}
+}
+
+// parseFrames parses the first method in the message. The heartbeat and content frames around the
+// method are skipped. The message which contains no method is not recognized as AMQP, because the
+// heartbeat and content frames alone are too loose to be told from the other protocols.
+func parseFrames(isRequest bool) protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		for offset := 0; offset < len(message.Data); {
+			f, ok := readFrame(message.Data, offset)
+			if !ok {
+				break
+			}
+			if f.typ == frameMethod {
+				return parseMethod(message, f, isRequest), true
+			}
+			offset = f.end
+		}
+		return false, true
+	}
+}
+
+func parseMethod(message *protocol.PayloadMessage, f *frame, isRequest bool) bool {
+	reader := newArgumentReader(f.payload)
+	classId := reader.readShort()
+	id := newMethodId(classId, reader.readShort())
+	name, ok := methodNames[id]
+	if !ok {
+		return false
+	}
+	addMethodAttributes(message, f.channel, id)
+
+	var (
+		target string
+		noWait bool
+	)
+	switch id {
+	case basicPublish:
+		// reserved-1, exchange, routing-key, mandatory, immediate
+		reader.readShort()
+		target = readRoutingAttributes(message, reader)
+	case basicDeliver:
+		// consumer-tag, delivery-tag, redelivered, exchange, routing-key
+		reader.readShortString()
+		reader.readLongLong()
+		reader.readOctet()
+		target = readRoutingAttributes(message, reader)
+	case basicGetOk:
+		// delivery-tag, redelivered, exchange, routing-key, message-count
+		reader.readLongLong()
+		reader.readOctet()
+		target = readRoutingAttributes(message, reader)
+	case basicReturn:
+		// reply-code, reply-text, exchange, routing-key
+		readReplyAttributes(message, reader)
+		target = readRoutingAttributes(message, reader)
+	case basicGet, basicConsume, queueDeclare, queuePurge, queueDelete:
+		// reserved-1, queue, ...
+		reader.readShort()
+		target = readQueueAttributes(message, reader)
+		switch id {
+		case basicConsume:
+			// consumer-tag, no-local, no-ack, exclusive, no-wait
+			reader.readShortString()
+			noWait = reader.readOctet()&(1<<3) != 0
+		case queueDeclare:
+			// passive, durable, exclusive, auto-delete, no-wait
+			noWait = reader.readOctet()&(1<<4) != 0
+		case queuePurge:
+			// no-wait
+			noWait = reader.readOctet()&1 != 0
+		case queueDelete:
+			// if-unused, if-empty, no-wait
+			noWait = reader.readOctet()&(1<<2) != 0
+		}
+	case queueDeclareOk:
+		// The queue name could be generated by the server.
+		target = readQueueAttributes(message, reader)
+	case queueBind, queueUnbind:
+		// reserved-1, queue, exchange, routing-key, no-wait
+		reader.readShort()
+		target = readQueueAttributes(message, reader)
+		readRoutingAttributes(message, reader)
+		if id == queueBind {
+			noWait = reader.readOctet()&1 != 0
+		}
+	case exchangeDeclare, exchangeDelete:
+		// reserved-1, exchange, type, passive, durable, auto-delete, internal, no-wait
+		// reserved-1, exchange, if-unused, no-wait
+		reader.readShort()
+		exchange := reader.readShortString()
+		if reader.ok {
+			message.AddUtf8StringAttribute(constlabels.AmqpExchange, exchange)
+			target = exchange
+		}
+		if id == exchangeDeclare {
+			reader.readShortString()
+			noWait = reader.readOctet()&(1<<4) != 0
+		} else {
+			noWait = reader.readOctet()&(1<<1) != 0
+		}
+	case basicNack:
+		// The server could not handle the published message in the confirm mode.
+		if !isRequest {
+			addErrorAttributes(message, name)
+		}
+	case channelClose, connectionClose:
+		// reply-code, reply-text, class-id, method-id
+		readReplyAttributes(message, reader)
+	}
+
+	if target != "" {
+		message.AddUtf8StringAttribute(constlabels.ContentKey, name+" "+target)
+	} else {
+		message.AddUtf8StringAttribute(constlabels.ContentKey, name)
+	}
+	if _, ok := synchronousReplies[id]; isRequest && (!ok || noWait) {
+		message.MarkOneway()
+	}
+	return true
+}
+
+func addMethodAttributes(message *protocol.PayloadMessage, channel uint16, id methodId) {
+	message.AddUtf8StringAttribute(constlabels.AmqpMethod, methodNames[id])
+	message.AddIntAttribute(constlabels.AmqpChannel, int64(channel))
+}
+
+// readRoutingAttributes returns "exchange/routing-key" as the target of the message.
+// The default exchange is named "amq.default".
+func readRoutingAttributes(message *protocol.PayloadMessage, reader *argumentReader) string {
+	exchange := reader.readShortString()
+	routingKey := reader.readShortString()
+	if !reader.ok {
+		return ""
+	}
+	if exchange == "" {
+		exchange = "amq.default"
+	}
+	message.AddUtf8StringAttribute(constlabels.AmqpExchange, exchange)
+	message.AddUtf8StringAttribute(constlabels.AmqpRoutingKey, routingKey)
+	return exchange + "/" + routingKey
+}
+
+func readQueueAttributes(message *protocol.PayloadMessage, reader *argumentReader) string {
+	queue := reader.readShortString()
+	if !reader.ok {
+		return ""
+	}
+	message.AddUtf8StringAttribute(constlabels.AmqpQueue, queue)
+	return queue
+}
+
+const replySuccess = 200
+
+func readReplyAttributes(message *protocol.PayloadMessage, reader *argumentReader) {
+	replyCode := reader.readShort()
+	replyText := reader.readShortString()
+	if replyCode == 0 {
+		return
+	}
+	message.AddIntAttribute(constlabels.AmqpReplyCode, int64(replyCode))
+	if replyCode != replySuccess {
+		addErrorAttributes(message, replyText)
+	}
+}
+
+func addErrorAttributes(message *protocol.PayloadMessage, errMsg string) {
+	message.AddUtf8StringAttribute(constlabels.AmqpErrMsg, errMsg)
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
@@ -0,0 +1,252 @@
+package custom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+/*
+The custom protocols are declared in the configuration instead of code. Each spec is compiled into a
+parser whose request and response are recognized by their magic bytes or patterns, and the declared
+fields are extracted into attributes.
+
+If the request id is declared, the messages on one connection are parsed one by one and paired by the
+request id. Otherwise they are paired in order like the other request/response protocols.
+*/
+func NewCustomParser(spec Spec) (*protocol.ProtocolParser, error) {
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("invalid custom protocol %q: %w", spec.Name, err)
+	}
+	request := compileMessage(&spec, &spec.Request, true)
+	response := compileMessage(&spec, &spec.Response, false)
+
+	requestParser := protocol.CreatePkgParser(request.fastfail(), request.parse())
+	responseParser := protocol.CreatePkgParser(response.fastfail(), response.parse())
+
+	var pairMatch protocol.PairMatch
+	if spec.Request.RequestId != nil {
+		pairMatch = pairByRequestId(request.requestIdKey(), response.requestIdKey())
+	}
+	return protocol.NewProtocolParser(spec.Name, requestParser, responseParser, pairMatch), nil
+}
+
+func pairByRequestId(requestKey string, responseKey string) protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		id := response.GetStringAttribute(responseKey)
+		for i, request := range requests {
+			if !request.IsOneway() && request.GetStringAttribute(requestKey) == id {
+				return i
+			}
+		}
+		return protocol.PairMatchFail
+	}
+}
+
+type message struct {
+	*MessageSpec
+	text      bool
+	byteOrder binary.ByteOrder
+	magic     []byte
+	pattern   *regexp.Regexp
+	fields    []*FieldSpec
+	isRequest bool
+}
+
+func compileMessage(spec *Spec, msg *MessageSpec, isRequest bool) *message {
+	m := &message{
+		MessageSpec: msg,
+		text:        spec.Type == TypeText,
+		byteOrder:   binary.BigEndian,
+		isRequest:   isRequest,
+	}
+	if spec.ByteOrder == ByteOrderLittle {
+		m.byteOrder = binary.LittleEndian
+	}
+	// The spec has been validated.
+	m.magic, _ = msg.magicBytes(spec.Type)
+	if msg.Pattern != "" {
+		m.pattern = regexp.MustCompile(msg.Pattern)
+	}
+	m.fields = msg.allFields()
+	if headerLength := m.headerLength(); m.MinLength < headerLength {
+		m.MinLength = headerLength
+	}
+	return m
+}
+
+// headerLength returns the end of the last fixed part declared in the message, so the fields
+// could be read without checking the length of the data once it is longer than that.
+func (m *message) headerLength() int {
+	length := m.MagicOffset + len(m.magic)
+	if m.text {
+		return length
+	}
+	if m.Length != nil && m.Length.Offset+m.Length.Width > length {
+		length = m.Length.Offset + m.Length.Width
+	}
+	for _, field := range m.fields {
+		if field.Offset+field.Width > length {
+			length = field.Offset + field.Width
+		}
+	}
+	return length
+}
+
+func (m *message) requestIdKey() string {
+	if m.RequestId == nil || m.RequestId.Name == "" {
+		return constlabels.CustomRequestId
+	}
+	return m.RequestId.Name
+}
+
+func (m *message) fastfail() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		if len(message.Data) < m.MinLength {
+			return true
+		}
+		return !bytes.Equal(message.Data[m.MagicOffset:m.MagicOffset+len(m.magic)], m.magic)
+	}
+}
+
+func (m *message) parse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var values map[*FieldSpec]string
+		if m.text {
+			values = m.readTextFields(message.Data)
+		} else {
+			values = m.readBinaryFields(message.Data)
+		}
+		if values == nil {
+			return false, true
+		}
+
+		contents := make([]string, 0)
+		for i := range m.Fields {
+			field := &m.Fields[i]
+			value := values[field]
+			if field.Type == FieldString {
+				message.AddUtf8StringAttribute(field.Name, value)
+			} else {
+				intValue, _ := strconv.ParseInt(value, 10, 64)
+				message.AddIntAttribute(field.Name, intValue)
+			}
+			if field.Content {
+				contents = append(contents, value)
+			}
+			if m.isRequest && containsValue(field.OnewayValues, value) {
+				message.MarkOneway()
+			}
+		}
+		if len(contents) > 0 {
+			message.AddUtf8StringAttribute(constlabels.ContentKey, strings.Join(contents, " "))
+		}
+		if m.RequestId != nil {
+			message.AddUtf8StringAttribute(m.requestIdKey(), values[m.RequestId])
+		}
+		if m.Status != nil {
+			status := values[&m.Status.FieldSpec]
+			message.AddUtf8StringAttribute(constlabels.CustomStatus, status)
+			if m.Status.Name != "" {
+				message.AddUtf8StringAttribute(m.Status.Name, status)
+			}
+			if len(m.Status.Success) > 0 && !containsValue(m.Status.Success, status) {
+				message.AddBoolAttribute(constlabels.IsError, true)
+				message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+			}
+		}
+		return true, true
+	}
+}
+
+// readBinaryFields returns the values of the fields, or nil if the length field is invalid.
+func (m *message) readBinaryFields(data []byte) map[*FieldSpec]string {
+	if m.Length != nil {
+		length := int64(m.readUint(data[m.Length.Offset:], m.Length.Width)) + int64(m.Length.Adjustment)
+		if length < int64(m.MinLength) || length > int64(m.Length.MaxLength) {
+			return nil
+		}
+	}
+
+	values := make(map[*FieldSpec]string)
+	for _, field := range m.fields {
+		fieldData := data[field.Offset:]
+		switch field.Type {
+		case FieldString:
+			values[field] = strings.TrimRight(string(fieldData[:field.Width]), "\x00 ")
+		case FieldInt:
+			values[field] = strconv.FormatInt(m.readInt(fieldData, field.Width), 10)
+		default:
+			values[field] = strconv.FormatUint(m.readUint(fieldData, field.Width), 10)
+		}
+	}
+	return values
+}
+
+// readTextFields returns the values of the fields, or nil if the first line doesn't match the pattern.
+func (m *message) readTextFields(data []byte) map[*FieldSpec]string {
+	values := make(map[*FieldSpec]string)
+	if m.pattern == nil {
+		return values
+	}
+	line := data
+	if index := bytes.IndexByte(data, '\n'); index >= 0 {
+		line = data[:index]
+	}
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	matches := m.pattern.FindSubmatch(line)
+	if matches == nil {
+		return nil
+	}
+	for _, field := range m.fields {
+		value := string(matches[m.pattern.SubexpIndex(field.Group)])
+		if field.Type != FieldString {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return nil
+			}
+		}
+		values[field] = value
+	}
+	return values
+}
+
+func (m *message) readUint(data []byte, width int) uint64 {
+	switch width {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(m.byteOrder.Uint16(data))
+	case 4:
+		return uint64(m.byteOrder.Uint32(data))
+	default:
+		return m.byteOrder.Uint64(data)
+	}
+}
+
+func (m *message) readInt(data []byte, width int) int64 {
+	switch width {
+	case 1:
+		return int64(int8(data[0]))
+	case 2:
+		return int64(int16(m.byteOrder.Uint16(data)))
+	case 4:
+		return int64(int32(m.byteOrder.Uint32(data)))
+	default:
+		return int64(m.byteOrder.Uint64(data))
+	}
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
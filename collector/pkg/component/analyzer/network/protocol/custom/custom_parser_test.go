@@ -0,0 +1,88 @@
+package custom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestNewCustomParserInvalidSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr string
+	}{
+		{"empty name", Spec{}, "the name is empty"},
+		{"no magic", Spec{Name: "p"}, "either magic or pattern is required"},
+		{"bad magic", Spec{Name: "p", Request: MessageSpec{Magic: "cafe"}}, "prefix 0x"},
+		{"bad width", Spec{Name: "p", Request: MessageSpec{Magic: "0xca", Length: &LengthSpec{Offset: 1, Width: 3}}}, "width 3"},
+		{"negative magic offset", Spec{Name: "p", Request: MessageSpec{Magic: "0xca", MagicOffset: -1}}, "magic_offset is negative"},
+		{"negative length offset", Spec{Name: "p", Request: MessageSpec{Magic: "0xca", Length: &LengthSpec{Offset: -2, Width: 4}}}, "length: offset is negative"},
+		{"negative text magic offset", Spec{Name: "p", Type: TypeText, Request: MessageSpec{Magic: "CALL", MagicOffset: -1}}, "magic_offset is negative"},
+		{"missing request id", Spec{
+			Name:     "p",
+			Request:  MessageSpec{Magic: "0xca", RequestId: &FieldSpec{Offset: 1, Width: 4}},
+			Response: MessageSpec{Magic: "0xca"},
+		}, "request_id must be declared"},
+		{"missing group", Spec{
+			Name:     "p",
+			Type:     TypeText,
+			Request:  MessageSpec{Pattern: `^CALL (\S+)`, Fields: []FieldSpec{{Name: "f", Group: "method"}}},
+			Response: MessageSpec{Magic: "OK"},
+		}, `group "method" is not found`},
+		{"builtin field name", Spec{
+			Name:     "p",
+			Request:  MessageSpec{Magic: "0xca", Fields: []FieldSpec{{Name: constlabels.RequestContent, Offset: 1, Width: 4}}},
+			Response: MessageSpec{Magic: "0xca"},
+		}, `field "request_content": the name is used by a built-in label`},
+		{"builtin status name", Spec{
+			Name:     "p",
+			Request:  MessageSpec{Magic: "0xca"},
+			Response: MessageSpec{Magic: "0xca", Status: &StatusSpec{FieldSpec: FieldSpec{Name: constlabels.HttpStatusCode, Offset: 1, Width: 2}}},
+		}, "the name is used by a built-in label"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCustomParser(tt.spec)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTextProtocol(t *testing.T) {
+	parser, err := NewCustomParser(Spec{
+		Name: "textrpc",
+		Type: TypeText,
+		Request: MessageSpec{
+			Pattern: `^CALL (?P<method>\S+) (?P<seq>\d+)`,
+			Fields: []FieldSpec{
+				{Name: "textrpc_method", Group: "method", Content: true},
+				{Name: "textrpc_seq", Group: "seq", Type: FieldUint},
+			},
+		},
+		Response: MessageSpec{
+			Pattern: `^(?P<code>\d{3}) `,
+			Status:  &StatusSpec{FieldSpec: FieldSpec{Group: "code"}, Success: []string{"200"}},
+		},
+	})
+	require.NoError(t, err)
+
+	request := protocol.NewRequestMessage([]byte("CALL user.get 42\r\nbody"), model.L4Proto_TCP)
+	require.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "user.get", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, int64(42), request.GetIntAttribute("textrpc_seq"))
+	// The request of another protocol is not parsed.
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET / HTTP/1.1\r\n"), model.L4Proto_TCP)))
+
+	response := protocol.NewResponseMessage([]byte("503 busy\r\n"), request.GetAttributes(), model.L4Proto_TCP)
+	require.True(t, parser.ParseResponse(response))
+	assert.Equal(t, "503", response.GetStringAttribute(constlabels.CustomStatus))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+}
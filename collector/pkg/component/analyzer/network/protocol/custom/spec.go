@@ -0,0 +1,265 @@
+package custom
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	TypeBinary = "binary"
+	TypeText   = "text"
+
+	ByteOrderBig    = "big"
+	ByteOrderLittle = "little"
+
+	FieldUint   = "uint"
+	FieldInt    = "int"
+	FieldString = "string"
+
+	defaultMaxLength = 1 << 24
+)
+
+// Spec declares a simple binary or text protocol which is compiled into a protocol.ProtocolParser.
+// For example, a binary RPC protocol whose header is "magic(2) length(4) id(8) method(2)" is declared as
+//
+//	name: myrpc
+//	type: binary
+//	request:
+//	  magic: "0xcafe"
+//	  length: { offset: 2, width: 4, adjustment: 6 }
+//	  request_id: { offset: 6, width: 8 }
+//	  fields:
+//	    - { name: myrpc_method, offset: 14, width: 2, content: true }
+//	response:
+//	  magic: "0xcafe"
+//	  length: { offset: 2, width: 4, adjustment: 6 }
+//	  request_id: { offset: 6, width: 8 }
+//	  status: { offset: 14, width: 2, success: [ "0" ] }
+type Spec struct {
+	Name string `mapstructure:"name"`
+	// Type is either "binary" or "text". The default is "binary".
+	Type string `mapstructure:"type"`
+	// ByteOrder of the integer fields in the binary protocol. The default is "big".
+	ByteOrder string      `mapstructure:"byte_order"`
+	Request   MessageSpec `mapstructure:"request"`
+	Response  MessageSpec `mapstructure:"response"`
+}
+
+// MessageSpec declares how to recognize a request or a response and which fields to extract from it.
+type MessageSpec struct {
+	// Magic is the bytes the message starts with at MagicOffset. It is written in hex with the prefix "0x"
+	// for the binary protocol, and literally for the text protocol.
+	Magic       string `mapstructure:"magic"`
+	MagicOffset int    `mapstructure:"magic_offset"`
+	// Pattern is the regular expression matching the first line of the text protocol.
+	// The fields of the text protocol are read from its named groups.
+	Pattern string `mapstructure:"pattern"`
+	// MinLength is the minimum length of the message. It is never less than the end of the last field.
+	MinLength int         `mapstructure:"min_length"`
+	Length    *LengthSpec `mapstructure:"length"`
+	// RequestId pairs the responses with the requests. The messages on one connection are not merged
+	// and parsed one by one if both the request and the response declare it.
+	RequestId *FieldSpec  `mapstructure:"request_id"`
+	Status    *StatusSpec `mapstructure:"status"`
+	Fields    []FieldSpec `mapstructure:"fields"`
+}
+
+// LengthSpec declares the length field of the binary protocol.
+// The length of the whole message is the value of the field plus Adjustment.
+type LengthSpec struct {
+	Offset     int `mapstructure:"offset"`
+	Width      int `mapstructure:"width"`
+	Adjustment int `mapstructure:"adjustment"`
+	// MaxLength is used to discern the protocol. The default is 16MB.
+	MaxLength int `mapstructure:"max_length"`
+}
+
+// FieldSpec declares a field to be extracted into the attribute Name, which must not be the name of
+// a built-in label. The request id is extracted into custom_request_id if its name is empty.
+type FieldSpec struct {
+	Name string `mapstructure:"name"`
+	// Type is one of "uint", "int" and "string". The default is "uint" for the binary protocol
+	// and "string" for the text protocol.
+	Type   string `mapstructure:"type"`
+	Offset int    `mapstructure:"offset"`
+	Width  int    `mapstructure:"width"`
+	// Group is the named group of the pattern in the text protocol.
+	Group string `mapstructure:"group"`
+	// Content means the field is a part of content_key which is used as request_content.
+	Content bool `mapstructure:"content"`
+	// OnewayValues marks the request as one-way if the value of the field is one of them.
+	OnewayValues []string `mapstructure:"oneway_values"`
+}
+
+// StatusSpec declares the status field of the response. It is always reported as custom_status, and
+// also as Name if it is not empty. The response is an error if Success is not empty and the status is
+// not one of them.
+type StatusSpec struct {
+	FieldSpec `mapstructure:",squash"`
+	Success   []string `mapstructure:"success"`
+}
+
+func (spec *Spec) validate() error {
+	if spec.Name == "" {
+		return errors.New("the name is empty")
+	}
+	if spec.Name == protocol.NOSUPPORT {
+		return fmt.Errorf("the name %s is reserved", spec.Name)
+	}
+	switch spec.Type {
+	case "":
+		spec.Type = TypeBinary
+	case TypeBinary, TypeText:
+	default:
+		return fmt.Errorf("unknown type %q", spec.Type)
+	}
+	switch spec.ByteOrder {
+	case "":
+		spec.ByteOrder = ByteOrderBig
+	case ByteOrderBig, ByteOrderLittle:
+	default:
+		return fmt.Errorf("unknown byte_order %q", spec.ByteOrder)
+	}
+	if err := spec.Request.validate(spec.Type); err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	if err := spec.Response.validate(spec.Type); err != nil {
+		return fmt.Errorf("response: %w", err)
+	}
+	if (spec.Request.RequestId == nil) != (spec.Response.RequestId == nil) {
+		return errors.New("request_id must be declared in both the request and the response")
+	}
+	return nil
+}
+
+func (msg *MessageSpec) validate(protocolType string) error {
+	if msg.Magic == "" && msg.Pattern == "" {
+		return errors.New("either magic or pattern is required to discern the protocol")
+	}
+	if msg.MagicOffset < 0 {
+		return errors.New("magic_offset is negative")
+	}
+	if protocolType == TypeBinary {
+		if msg.Pattern != "" {
+			return errors.New("pattern is only supported by the text protocol")
+		}
+		if _, err := msg.magicBytes(protocolType); err != nil {
+			return err
+		}
+		if msg.Length != nil {
+			if msg.Length.Offset < 0 {
+				return errors.New("length: offset is negative")
+			}
+			if err := validateWidth(msg.Length.Width); err != nil {
+				return fmt.Errorf("length: %w", err)
+			}
+			if msg.Length.MaxLength <= 0 {
+				msg.Length.MaxLength = defaultMaxLength
+			}
+		}
+	} else {
+		if msg.Length != nil {
+			return errors.New("length is only supported by the binary protocol")
+		}
+		if msg.Pattern != "" {
+			if _, err := regexp.Compile(msg.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern: %w", err)
+			}
+		}
+	}
+
+	for _, field := range msg.Fields {
+		if field.Name == "" {
+			return errors.New("the name of the field is empty")
+		}
+	}
+	for _, field := range msg.allFields() {
+		if constlabels.IsBuiltin(field.Name) {
+			return fmt.Errorf("field %q: the name is used by a built-in label", field.Name)
+		}
+		if err := field.validate(protocolType, msg.Pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allFields returns the fields including the request id and the status.
+func (msg *MessageSpec) allFields() []*FieldSpec {
+	fields := make([]*FieldSpec, 0, len(msg.Fields)+2)
+	if msg.RequestId != nil {
+		fields = append(fields, msg.RequestId)
+	}
+	if msg.Status != nil {
+		fields = append(fields, &msg.Status.FieldSpec)
+	}
+	for i := range msg.Fields {
+		fields = append(fields, &msg.Fields[i])
+	}
+	return fields
+}
+
+func (msg *MessageSpec) magicBytes(protocolType string) ([]byte, error) {
+	if protocolType == TypeText {
+		return []byte(msg.Magic), nil
+	}
+	if msg.Magic == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(msg.Magic, "0x") {
+		return nil, fmt.Errorf("magic %q should be written in hex with the prefix 0x", msg.Magic)
+	}
+	magic, err := hex.DecodeString(msg.Magic[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid magic %q: %w", msg.Magic, err)
+	}
+	return magic, nil
+}
+
+func (field *FieldSpec) validate(protocolType string, pattern string) error {
+	if protocolType == TypeText {
+		if field.Type == "" {
+			field.Type = FieldString
+		}
+		if field.Group == "" {
+			return fmt.Errorf("field %q: group is required by the text protocol", field.Name)
+		}
+		if !strings.Contains(pattern, "(?P<"+field.Group+">") {
+			return fmt.Errorf("field %q: group %q is not found in the pattern", field.Name, field.Group)
+		}
+	} else {
+		if field.Type == "" {
+			field.Type = FieldUint
+		}
+		if field.Offset < 0 {
+			return fmt.Errorf("field %q: offset is negative", field.Name)
+		}
+		if field.Type == FieldString {
+			if field.Width <= 0 {
+				return fmt.Errorf("field %q: width is required by the string field", field.Name)
+			}
+		} else if err := validateWidth(field.Width); err != nil {
+			return fmt.Errorf("field %q: %w", field.Name, err)
+		}
+	}
+	switch field.Type {
+	case FieldUint, FieldInt, FieldString:
+	default:
+		return fmt.Errorf("field %q: unknown type %q", field.Name, field.Type)
+	}
+	return nil
+}
+
+func validateWidth(width int) error {
+	switch width {
+	case 1, 2, 4, 8:
+		return nil
+	}
+	return fmt.Errorf("width %d is not one of 1, 2, 4 and 8", width)
+}
@@ -1,16 +1,24 @@
 package factory
 
 import (
-	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/rocketmq"
+	"fmt"
 	"sync"
 
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/rocketmq"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/amqp"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/custom"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dns"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dubbo"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/generic"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/http"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/http2"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/kafka"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/memcached"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mongodb"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mysql"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/postgresql"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/redis"
 )
 
@@ -32,17 +40,35 @@ func NewParserFactory(options ...Option) *ParserFactory {
 		option(factory.config)
 	}
 	factory.protocolParsers[protocol.HTTP] = http.NewHttpParser(factory.config.urlClusteringMethod)
+	factory.protocolParsers[protocol.HTTP2] = http2.NewHttp2Parser(factory.config.urlClusteringMethod)
 	factory.protocolParsers[protocol.KAFKA] = kafka.NewKafkaParser()
 	factory.protocolParsers[protocol.MYSQL] = mysql.NewMysqlParser()
 	factory.protocolParsers[protocol.REDIS] = redis.NewRedisParser()
 	factory.protocolParsers[protocol.DUBBO] = dubbo.NewDubboParser()
 	factory.protocolParsers[protocol.DNS] = dns.NewDnsParser()
 	factory.protocolParsers[protocol.ROCKETMQ] = rocketmq.NewRocketMQParser()
+	factory.protocolParsers[protocol.POSTGRESQL] = postgresql.NewPostgresqlParser()
+	factory.protocolParsers[protocol.MONGODB] = mongodb.NewMongodbParser()
+	factory.protocolParsers[protocol.MEMCACHED] = memcached.NewMemcachedParser()
+	factory.protocolParsers[protocol.AMQP] = amqp.NewAmqpParser()
 	factory.protocolParsers[protocol.NOSUPPORT] = generic.NewGenericParser()
 
 	return factory
 }
 
+// AddCustomParser compiles the declarative spec and registers the parser by the name of the spec.
+func (f *ParserFactory) AddCustomParser(spec custom.Spec) error {
+	if _, exist := f.protocolParsers[spec.Name]; exist {
+		return fmt.Errorf("the parser of protocol %q already exists", spec.Name)
+	}
+	parser, err := custom.NewCustomParser(spec)
+	if err != nil {
+		return err
+	}
+	f.protocolParsers[spec.Name] = parser
+	return nil
+}
+
 func (f *ParserFactory) GetParser(key string) *protocol.ProtocolParser {
 	return f.protocolParsers[key]
 }
@@ -52,6 +52,9 @@ func parseHttpRequest(urlClusteringMethod urlclustering.ClusteringMethod) protoc
 		if len(traceType) > 0 && len(traceId) > 0 {
 			message.AddStringAttribute(constlabels.HttpApmTraceType, traceType)
 			message.AddStringAttribute(constlabels.HttpApmTraceId, traceId)
+			if spanId := tools.ParseParentSpanId(traceType, headers); len(spanId) > 0 {
+				message.AddStringAttribute(constlabels.HttpApmParentSpanId, spanId)
+			}
 		}
 
 		message.AddStringAttribute(constlabels.HttpMethod, string(method))
@@ -0,0 +1,124 @@
+package http2
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/*
+https://www.rfc-editor.org/rfc/rfc9113#section-4.1
+
+	+-----------------------------------------------+
+	|                 Length (24)                   |
+	+---------------+---------------+---------------+
+	|   Type (8)    |   Flags (8)   |
+	+-+-------------+---------------+-------------------------------+
+	|R|                 Stream Identifier (31)                      |
+	+=+=============================================================+
+	|                   Frame Payload (0...)                      ...
+	+---------------------------------------------------------------+
+*/
+const frameHeaderLength = 9
+
+const (
+	frameData         = 0x0
+	frameHeaders      = 0x1
+	framePriority     = 0x2
+	frameRstStream    = 0x3
+	frameSettings     = 0x4
+	framePushPromise  = 0x5
+	framePing         = 0x6
+	frameGoAway       = 0x7
+	frameWindowUpdate = 0x8
+	frameContinuation = 0x9
+)
+
+const (
+	flagEndStream  = 0x1
+	flagAck        = 0x1
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+)
+
+// The client connection preface starts with this string, followed by a SETTINGS frame.
+var clientPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+type frameHeader struct {
+	length   int
+	typ      uint8
+	flags    uint8
+	streamId uint32
+}
+
+func readFrameHeader(data []byte) (header frameHeader, ok bool) {
+	if len(data) < frameHeaderLength {
+		return header, false
+	}
+	header = frameHeader{
+		length:   int(data[0])<<16 | int(data[1])<<8 | int(data[2]),
+		typ:      data[3],
+		flags:    data[4],
+		streamId: binary.BigEndian.Uint32(data[5:9]),
+	}
+	return header, header.isValid()
+}
+
+func (h frameHeader) isValid() bool {
+	// The reserved bit must be unset.
+	if h.streamId&(1<<31) != 0 {
+		return false
+	}
+	switch h.typ {
+	case frameData, frameHeaders, framePriority, frameRstStream, framePushPromise, frameContinuation:
+		return h.streamId != 0
+	case frameSettings:
+		return h.streamId == 0 && h.length%6 == 0 && (h.flags&flagAck == 0 || h.length == 0)
+	case framePing:
+		return h.streamId == 0 && h.length == 8
+	case frameGoAway:
+		return h.streamId == 0 && h.length >= 8
+	case frameWindowUpdate:
+		return h.length == 4
+	}
+	// The frames of unknown types must be ignored, see RFC 9113 section 4.1, so they are skipped
+	// by their lengths like ALTSVC, ORIGIN and PRIORITY_UPDATE.
+	return true
+}
+
+func (h frameHeader) isKnown() bool {
+	return h.typ <= frameContinuation
+}
+
+func (h frameHeader) has(flag uint8) bool {
+	return h.flags&flag != 0
+}
+
+// headerBlockFragment removes the padding and the priority fields from the payload of HEADERS.
+func headerBlockFragment(header frameHeader, payload []byte, truncated bool) []byte {
+	var padLength int
+	if header.has(flagPadded) {
+		if len(payload) < 1 {
+			return nil
+		}
+		padLength = int(payload[0])
+		payload = payload[1:]
+	}
+	if header.has(flagPriority) {
+		if len(payload) < 5 {
+			return nil
+		}
+		payload = payload[5:]
+	}
+	if !truncated {
+		if padLength > len(payload) {
+			return nil
+		}
+		payload = payload[:len(payload)-padLength]
+	}
+	return payload
+}
+
+func hasClientPreface(data []byte) bool {
+	return bytes.HasPrefix(data, clientPreface)
+}
@@ -0,0 +1,81 @@
+package http2
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// NewHttp2Parser returns the parser of HTTP/2, which also recognizes gRPC by its content-type.
+// Many concurrent streams are multiplexed on one connection and the headers are compressed
+// with the state of the connection, so the requests and responses are parsed by a StreamSession
+// created for each connection. The request and response parsers here are used to discern the protocol.
+func NewHttp2Parser(urlClusteringMethod string) *protocol.ProtocolParser {
+	method := urlclustering.NewMethod(urlClusteringMethod)
+	requestParser := protocol.CreatePkgParser(fastfailHttp2Request(), parseHttp2Request())
+	responseParser := protocol.CreatePkgParser(fastfailHttp2Response(), parseHttp2Response())
+
+	parser := protocol.NewProtocolParser(protocol.HTTP2, requestParser, responseParser, nil)
+	parser.EnableMultiStreams(func() protocol.StreamSession {
+		return newSession(method)
+	})
+	return parser
+}
+
+func fastfailHttp2Request() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < frameHeaderLength
+	}
+}
+
+// parseHttp2Request accepts the data starting with the client connection preface, or the frames
+// containing a request header block which could be decoded without the dynamic table.
+func parseHttp2Request() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if hasClientPreface(message.Data) {
+			return true, true
+		}
+		foundMethod := false
+		decoder := hpack.NewDecoder(initialHeaderTableSize, func(f hpack.HeaderField) {
+			if f.Name == ":method" {
+				foundMethod = true
+			}
+		})
+		for offset := 0; offset < len(message.Data); {
+			header, ok := readFrameHeader(message.Data[offset:])
+			if !ok {
+				return false, true
+			}
+			start := offset + frameHeaderLength
+			end := start + header.length
+			truncated := end > len(message.Data)
+			if truncated {
+				end = len(message.Data)
+			}
+			if header.typ == frameHeaders {
+				// Errors are expected because the entries of the dynamic table are unknown here.
+				_, _ = decoder.Write(headerBlockFragment(header, message.Data[start:end], truncated))
+				_ = decoder.Close()
+				return foundMethod, true
+			}
+			offset = end
+		}
+		return false, true
+	}
+}
+
+func fastfailHttp2Response() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < frameHeaderLength
+	}
+}
+
+func parseHttp2Response() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		// The unknown types are rejected here because the response starts with a known frame,
+		// and almost any data would be valid otherwise.
+		header, ok := readFrameHeader(message.Data)
+		return ok && header.isKnown(), true
+	}
+}
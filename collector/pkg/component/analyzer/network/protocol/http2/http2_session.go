@@ -0,0 +1,258 @@
+package http2
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tools"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+const (
+	initialHeaderTableSize = 4096
+	// The peer could enlarge the dynamic table with SETTINGS_HEADER_TABLE_SIZE, and we don't
+	// track the settings, so the dynamic table size updates up to this size are accepted.
+	maxHeaderTableSize = 1 << 16
+)
+
+// session parses the frames of one connection. Each direction has its own HPACK dynamic table,
+// so the header blocks must be decoded in the order they are sent.
+type session struct {
+	urlClusteringMethod urlclustering.ClusteringMethod
+	request             *direction
+	response            *direction
+}
+
+func newSession(urlClusteringMethod urlclustering.ClusteringMethod) *session {
+	return &session{
+		urlClusteringMethod: urlClusteringMethod,
+		request:             newDirection(),
+		response:            newDirection(),
+	}
+}
+
+func (s *session) ParseRequest(data []byte, size int) ([]*protocol.StreamFrame, bool) {
+	if hasClientPreface(data) {
+		// A new connection is established, maybe on a reused fd.
+		s.request = newDirection()
+		s.response = newDirection()
+		data = data[len(clientPreface):]
+		size -= len(clientPreface)
+	}
+	return s.request.parse(data, size, s.requestAttributes)
+}
+
+func (s *session) ParseResponse(data []byte, size int) ([]*protocol.StreamFrame, bool) {
+	return s.response.parse(data, size, responseAttributes)
+}
+
+func (s *session) requestAttributes(headers map[string]string) *model.AttributeMap {
+	attributes := model.NewAttributeMap()
+	if method, ok := headers[":method"]; ok {
+		attributes.AddStringValue(constlabels.HttpMethod, method)
+	}
+	isGrpc := strings.HasPrefix(headers["content-type"], "application/grpc")
+	if isGrpc {
+		attributes.AddStringValue(constlabels.Protocol, protocol.GRPC)
+	}
+	if path, ok := headers[":path"]; ok {
+		attributes.AddStringValue(constlabels.HttpUrl, tools.FormatStringToUtf8(path))
+		// The path of gRPC is "/{service}/{method}" which is good enough as the content key.
+		contentKey := path
+		if !isGrpc {
+			contentKey = s.urlClusteringMethod.Clustering(path)
+			if len(contentKey) == 0 {
+				contentKey = "*"
+			}
+		}
+		attributes.AddStringValue(constlabels.ContentKey, tools.FormatStringToUtf8(contentKey))
+	}
+	traceType, traceId := tools.ParseTraceHeader(headers)
+	if len(traceType) > 0 && len(traceId) > 0 {
+		attributes.AddStringValue(constlabels.HttpApmTraceType, traceType)
+		attributes.AddStringValue(constlabels.HttpApmTraceId, traceId)
+		if spanId := tools.ParseParentSpanId(traceType, headers); len(spanId) > 0 {
+			attributes.AddStringValue(constlabels.HttpApmParentSpanId, spanId)
+		}
+	}
+	return attributes
+}
+
+// responseAttributes parses both the response headers and the trailers. The status of gRPC is
+// sent in the trailers, or in the headers if there is no message in the response.
+func responseAttributes(headers map[string]string) *model.AttributeMap {
+	attributes := model.NewAttributeMap()
+	if status, ok := headers[":status"]; ok {
+		statusCode, err := strconv.ParseInt(status, 10, 0)
+		if err == nil {
+			attributes.AddIntValue(constlabels.HttpStatusCode, statusCode)
+			if statusCode >= 400 {
+				addProtocolError(attributes)
+			}
+		}
+	}
+	if status, ok := headers["grpc-status"]; ok {
+		grpcStatus, err := strconv.ParseInt(status, 10, 0)
+		if err == nil {
+			attributes.AddIntValue(constlabels.GrpcStatus, grpcStatus)
+			if grpcStatus != 0 {
+				addProtocolError(attributes)
+			}
+		}
+	}
+	return attributes
+}
+
+func addProtocolError(attributes *model.AttributeMap) {
+	attributes.AddBoolValue(constlabels.IsError, true)
+	attributes.AddIntValue(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
+
+// direction keeps the states of the frames sent in one direction of the connection.
+type direction struct {
+	decoder *hpack.Decoder
+	// desynced is set once a header block could not be decoded completely. The entries it adds
+	// to the dynamic table are unknown, so the following header blocks are not decoded until
+	// the next client connection preface.
+	desynced bool
+	// skip is the length of the frame continuing into the next message.
+	skip int
+	// continuation is the header block waiting for the CONTINUATION frames.
+	continuation *pendingHeaders
+}
+
+type pendingHeaders struct {
+	streamId  uint32
+	endStream bool
+	block     []byte
+	size      int
+}
+
+func newDirection() *direction {
+	decoder := hpack.NewDecoder(initialHeaderTableSize, nil)
+	decoder.SetAllowedMaxDynamicTableSize(maxHeaderTableSize)
+	return &direction{
+		decoder: decoder,
+	}
+}
+
+// parse returns the frames of the streams in the data. The size is the real size of the message,
+// which is larger than the data if the data is truncated.
+func (d *direction) parse(data []byte, size int, toAttributes func(map[string]string) *model.AttributeMap) ([]*protocol.StreamFrame, bool) {
+	if d.skip >= size {
+		d.skip -= size
+		return nil, true
+	}
+	offset := d.skip
+	d.skip = 0
+
+	frames := make([]*protocol.StreamFrame, 0)
+	for offset+frameHeaderLength <= len(data) {
+		header, ok := readFrameHeader(data[offset:])
+		if !ok {
+			// We have lost track of the frame boundary, so the rest of the message is dropped.
+			return frames, len(frames) > 0
+		}
+		start := offset + frameHeaderLength
+		end := start + header.length
+		if end > size {
+			d.skip = end - size
+		}
+		truncated := end > len(data)
+		if truncated {
+			end = len(data)
+		}
+		if frame := d.parseFrame(header, data[start:end], truncated, toAttributes); frame != nil {
+			frames = append(frames, frame)
+		}
+		offset = end
+	}
+	return frames, true
+}
+
+func (d *direction) parseFrame(header frameHeader, payload []byte, truncated bool, toAttributes func(map[string]string) *model.AttributeMap) *protocol.StreamFrame {
+	frameSize := frameHeaderLength + header.length
+	switch header.typ {
+	case frameHeaders:
+		block := headerBlockFragment(header, payload, truncated)
+		if header.has(flagEndHeaders) || truncated {
+			return d.newHeadersFrame(header.streamId, header.has(flagEndStream), frameSize, block, truncated, toAttributes)
+		}
+		d.continuation = &pendingHeaders{
+			streamId:  header.streamId,
+			endStream: header.has(flagEndStream),
+			block:     append([]byte{}, block...),
+			size:      frameSize,
+		}
+	case frameContinuation:
+		pending := d.continuation
+		if pending == nil || pending.streamId != header.streamId {
+			return nil
+		}
+		pending.block = append(pending.block, payload...)
+		pending.size += frameSize
+		if header.has(flagEndHeaders) || truncated {
+			d.continuation = nil
+			return d.newHeadersFrame(pending.streamId, pending.endStream, pending.size, pending.block, truncated, toAttributes)
+		}
+	case frameData:
+		return &protocol.StreamFrame{
+			StreamId:  header.streamId,
+			EndStream: header.has(flagEndStream),
+			Size:      frameSize,
+		}
+	case frameRstStream:
+		frame := &protocol.StreamFrame{
+			StreamId:  header.streamId,
+			EndStream: true,
+			Reset:     true,
+			Size:      frameSize,
+		}
+		// NO_ERROR is used to stop the request after the response is completed.
+		if len(payload) >= 4 && binary.BigEndian.Uint32(payload) != 0 {
+			frame.Attributes = model.NewAttributeMap()
+			addProtocolError(frame.Attributes)
+		}
+		return frame
+	}
+	return nil
+}
+
+func (d *direction) newHeadersFrame(streamId uint32, endStream bool, size int, block []byte, truncated bool, toAttributes func(map[string]string) *model.AttributeMap) *protocol.StreamFrame {
+	frame := &protocol.StreamFrame{
+		StreamId:  streamId,
+		Headers:   true,
+		EndStream: endStream,
+		Size:      size,
+	}
+	if d.desynced {
+		return frame
+	}
+	// A truncated block or a decoding error means the dynamic table has missed some entries,
+	// maybe because they were added before we start to trace the connection. The fields decoded
+	// from such a block may refer to the wrong entries, so they are not reported.
+	if truncated {
+		d.desynced = true
+		return frame
+	}
+	headers := make(map[string]string)
+	d.decoder.SetEmitFunc(func(f hpack.HeaderField) {
+		headers[f.Name] = f.Value
+	})
+	if _, err := d.decoder.Write(block); err != nil {
+		d.desynced = true
+		return frame
+	}
+	if err := d.decoder.Close(); err != nil {
+		d.desynced = true
+		return frame
+	}
+	frame.Attributes = toAttributes(headers)
+	return frame
+}
@@ -16,6 +16,7 @@ func parseRequestProduce() protocol.ParsePkgFn {
 		var (
 			offset    int
 			err       error
+			acks      int16
 			topicNum  int32
 			topicName string
 		)
@@ -29,8 +30,15 @@ func parseRequestProduce() protocol.ParsePkgFn {
 				return false, true
 			}
 		}
-		// acks, timeout_ms
-		offset += 6
+		if offset, err = message.ReadInt16(offset, &acks); err != nil {
+			return false, true
+		}
+		if acks == 0 {
+			// The broker doesn't send the response when acks is 0.
+			message.MarkOneway()
+		}
+		// timeout_ms
+		offset += 4
 		if offset, err = message.ReadArraySize(offset, compact, &topicNum); err != nil {
 			return false, true
 		}
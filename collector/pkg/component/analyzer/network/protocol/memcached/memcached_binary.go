@@ -0,0 +1,244 @@
+package memcached
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+/*
+All the integers are big-endian.
+===== Header =====
+uint8	magic				0x80 for the request and 0x81 for the response
+uint8	opcode
+uint16	keyLength
+uint8	extrasLength
+uint8	dataType
+uint16	vbucketId/status	vbucketId for the request and status for the response
+uint32	totalBodyLength		extrasLength + keyLength + valueLength
+uint32	opaque
+uint64	cas
+===== Body =====
+extras, key, value
+*/
+const (
+	headerLength = 24
+
+	magicRequest  = 0x80
+	magicResponse = 0x81
+
+	// The maximum size of an item is 1MB by default and 1GB at most.
+	maxBodyLength = 1 << 30
+)
+
+type binaryOpcode struct {
+	command string
+	// quiet is true if the server doesn't send the successful response.
+	quiet     bool
+	retrieval bool
+}
+
+var binaryOpcodes = map[byte]binaryOpcode{
+	0x00: {command: "get", retrieval: true},
+	0x01: {command: "set"},
+	0x02: {command: "add"},
+	0x03: {command: "replace"},
+	0x04: {command: "delete"},
+	0x05: {command: "incr"},
+	0x06: {command: "decr"},
+	0x07: {command: "quit"},
+	0x08: {command: "flush_all"},
+	0x09: {command: "get", quiet: true, retrieval: true},
+	0x0a: {command: "noop"},
+	0x0b: {command: "version"},
+	0x0c: {command: "get", retrieval: true},
+	0x0d: {command: "get", quiet: true, retrieval: true},
+	0x0e: {command: "append"},
+	0x0f: {command: "prepend"},
+	0x10: {command: "stats"},
+	0x11: {command: "set", quiet: true},
+	0x12: {command: "add", quiet: true},
+	0x13: {command: "replace", quiet: true},
+	0x14: {command: "delete", quiet: true},
+	0x15: {command: "incr", quiet: true},
+	0x16: {command: "decr", quiet: true},
+	0x17: {command: "quit", quiet: true},
+	0x18: {command: "flush_all", quiet: true},
+	0x19: {command: "append", quiet: true},
+	0x1a: {command: "prepend", quiet: true},
+	0x1b: {command: "verbosity"},
+	0x1c: {command: "touch"},
+	0x1d: {command: "gat", retrieval: true},
+	0x1e: {command: "gat", quiet: true, retrieval: true},
+	0x20: {command: "sasl_list_mechs"},
+	0x21: {command: "sasl_auth"},
+	0x22: {command: "sasl_step"},
+}
+
+// The response status which means the request fails. The others like "Key not found" and "Key exists"
+// are the normal results of the commands.
+var binaryErrorStatus = map[uint16]string{
+	0x0003: "Value too large",
+	0x0004: "Invalid arguments",
+	0x0006: "Incr/Decr on non-numeric value",
+	0x0007: "The vbucket belongs to another server",
+	0x0008: "Authentication error",
+	0x0081: "Unknown command",
+	0x0082: "Out of memory",
+	0x0083: "Not supported",
+	0x0084: "Internal error",
+	0x0085: "Busy",
+	0x0086: "Temporary failure",
+}
+
+const statusNoError = 0x0000
+
+type binaryPacket struct {
+	opcode    byte
+	key       []byte
+	status    uint16
+	value     []byte
+	bodyEnd   int
+	truncated bool
+}
+
+// readBinaryPacket reads the packet starting from offset. The key and value could be truncated.
+func readBinaryPacket(data []byte, offset int, magic byte) (*binaryPacket, bool) {
+	if offset+headerLength > len(data) || data[offset] != magic {
+		return nil, false
+	}
+	keyLength := int(binary.BigEndian.Uint16(data[offset+2:]))
+	extrasLength := int(data[offset+4])
+	bodyLength := int(binary.BigEndian.Uint32(data[offset+8:]))
+	if bodyLength > maxBodyLength || keyLength+extrasLength > bodyLength {
+		return nil, false
+	}
+	packet := &binaryPacket{
+		opcode:  data[offset+1],
+		status:  binary.BigEndian.Uint16(data[offset+6:]),
+		bodyEnd: offset + headerLength + bodyLength,
+	}
+	keyStart := offset + headerLength + extrasLength
+	valueStart := keyStart + keyLength
+	packet.key = subBytes(data, keyStart, valueStart)
+	packet.value = subBytes(data, valueStart, packet.bodyEnd)
+	packet.truncated = packet.bodyEnd > len(data)
+	return packet, true
+}
+
+func subBytes(data []byte, from int, to int) []byte {
+	if from >= len(data) {
+		return nil
+	}
+	if to > len(data) {
+		to = len(data)
+	}
+	return data[from:to]
+}
+
+func fastfailBinaryRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] != magicRequest
+	}
+}
+
+// parseBinaryRequest reads all the pipelined packets, e.g. the multi-get which is sent as GETKQ...GETK or GETKQ...NOOP.
+func parseBinaryRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			command  *binaryOpcode
+			key      string
+			keyCount int64
+			quiet    = true
+		)
+		for offset := 0; offset < len(message.Data); {
+			packet, ok := readBinaryPacket(message.Data, offset, magicRequest)
+			if !ok {
+				if command == nil {
+					return false, true
+				}
+				break
+			}
+			opcode, ok := binaryOpcodes[packet.opcode]
+			if !ok {
+				return false, true
+			}
+			if command == nil {
+				command = &opcode
+				key = string(packet.key)
+			}
+			if opcode.retrieval {
+				keyCount++
+			}
+			quiet = quiet && opcode.quiet
+			if packet.truncated {
+				break
+			}
+			offset = packet.bodyEnd
+		}
+		addCommandAttributes(message, command.command, key)
+		if command.retrieval {
+			message.AddIntAttribute(constlabels.MemcachedKeyCount, keyCount)
+		}
+		if quiet {
+			message.MarkOneway()
+		}
+		return true, true
+	}
+}
+
+func fastfailBinaryResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] != magicResponse
+	}
+}
+
+func parseBinaryResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			parsed   bool
+			hits     int64
+			finished bool
+			errMsg   string
+		)
+		for offset := 0; offset < len(message.Data); {
+			packet, ok := readBinaryPacket(message.Data, offset, magicResponse)
+			if !ok {
+				break
+			}
+			opcode, ok := binaryOpcodes[packet.opcode]
+			if !ok {
+				return false, true
+			}
+			parsed = true
+			if opcode.retrieval && packet.status == statusNoError {
+				hits++
+			}
+			if status, ok := binaryErrorStatus[packet.status]; ok && errMsg == "" {
+				// The value of the error response is the error message.
+				errMsg = status
+				if len(packet.value) > 0 && !packet.truncated {
+					errMsg = string(packet.value)
+				}
+			}
+			// The last response of the pipeline is not quiet.
+			finished = !opcode.quiet
+			if packet.truncated {
+				break
+			}
+			offset = packet.bodyEnd
+		}
+		if !parsed {
+			return false, true
+		}
+		if message.HasAttribute(constlabels.MemcachedKeyCount) &&
+			(finished || hits == message.GetIntAttribute(constlabels.MemcachedKeyCount)) {
+			addHitAttributes(message, hits)
+		}
+		if errMsg != "" {
+			addErrorAttributes(message, errMsg)
+		}
+		return true, true
+	}
+}
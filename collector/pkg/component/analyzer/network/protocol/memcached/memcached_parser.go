@@ -0,0 +1,87 @@
+package memcached
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+/*
+https://github.com/memcached/memcached/blob/master/doc/protocol.txt
+https://github.com/memcached/memcached/wiki/BinaryProtocolRevamped
+
+	    Request              Response
+	   /       \            /        \
+	Text     Binary      Text      Binary
+*/
+func NewMemcachedParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailMemcachedRequest(), parseMemcachedRequest())
+	requestParser.Add(fastfailTextRequest(), parseTextRequest())
+	requestParser.Add(fastfailBinaryRequest(), parseBinaryRequest())
+
+	responseParser := protocol.CreatePkgParser(fastfailMemcachedResponse(), parseMemcachedResponse())
+	responseParser.Add(fastfailTextResponse(), parseTextResponse())
+	responseParser.Add(fastfailBinaryResponse(), parseBinaryResponse())
+
+	return protocol.NewProtocolParser(protocol.MEMCACHED, requestParser, responseParser, nil)
+}
+
+func fastfailMemcachedRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) == 0
+	}
+}
+
+func parseMemcachedRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		return true, false
+	}
+}
+
+func fastfailMemcachedResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) == 0
+	}
+}
+
+func parseMemcachedResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		return true, false
+	}
+}
+
+// addCommandAttributes adds the command and uses the prefix of the first key as the content key,
+// e.g. "get user:*" for "get user:1001 user:1002".
+func addCommandAttributes(message *protocol.PayloadMessage, command string, key string) {
+	message.AddUtf8StringAttribute(constlabels.MemcachedCommand, command)
+	if key == "" {
+		message.AddUtf8StringAttribute(constlabels.ContentKey, command)
+		return
+	}
+	message.AddUtf8StringAttribute(constlabels.ContentKey, command+" "+getKeyPrefix(key))
+}
+
+// getKeyPrefix returns the key until its first delimiter, which is usually the namespace of the keys.
+// The keys without any delimiters are cut before their first digit.
+func getKeyPrefix(key string) string {
+	if index := strings.IndexAny(key, ":_.-/|#"); index >= 0 {
+		return key[:index+1] + "*"
+	}
+	if index := strings.IndexAny(key, "0123456789"); index >= 0 {
+		return key[:index] + "*"
+	}
+	return key
+}
+
+// addHitAttributes counts the hits and misses of the retrieval commands.
+func addHitAttributes(message *protocol.PayloadMessage, hits int64) {
+	message.AddIntAttribute(constlabels.MemcachedHits, hits)
+	message.AddIntAttribute(constlabels.MemcachedMisses, message.GetIntAttribute(constlabels.MemcachedKeyCount)-hits)
+}
+
+func addErrorAttributes(message *protocol.PayloadMessage, errMsg string) {
+	message.AddUtf8StringAttribute(constlabels.MemcachedErrMsg, errMsg)
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
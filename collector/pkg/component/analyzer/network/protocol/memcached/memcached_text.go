@@ -0,0 +1,203 @@
+package memcached
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+type textCommand struct {
+	// minFields is the minimum count of the fields, including the command.
+	minFields int
+	// hasKey is true if the second field is the key.
+	hasKey bool
+	// retrieval is true if the hits and misses are counted.
+	retrieval bool
+	// keyIndex is the index of the first key for the retrieval commands.
+	keyIndex int
+}
+
+var textCommands = map[string]textCommand{
+	// <command name> <key> <flags> <exptime> <bytes> [noreply]\r\n<data block>\r\n
+	"set":     {minFields: 5, hasKey: true},
+	"add":     {minFields: 5, hasKey: true},
+	"replace": {minFields: 5, hasKey: true},
+	"append":  {minFields: 5, hasKey: true},
+	"prepend": {minFields: 5, hasKey: true},
+	// cas <key> <flags> <exptime> <bytes> <cas unique> [noreply]\r\n<data block>\r\n
+	"cas": {minFields: 6, hasKey: true},
+	// get <key>*\r\n
+	"get":  {minFields: 2, hasKey: true, retrieval: true, keyIndex: 1},
+	"gets": {minFields: 2, hasKey: true, retrieval: true, keyIndex: 1},
+	// gat <exptime> <key>*\r\n
+	"gat":  {minFields: 3, retrieval: true, keyIndex: 2},
+	"gats": {minFields: 3, retrieval: true, keyIndex: 2},
+	// delete <key> [noreply]\r\n
+	"delete": {minFields: 2, hasKey: true},
+	// incr <key> <value> [noreply]\r\n
+	"incr": {minFields: 3, hasKey: true},
+	"decr": {minFields: 3, hasKey: true},
+	// touch <key> <exptime> [noreply]\r\n
+	"touch": {minFields: 3, hasKey: true},
+	// The meta commands: <command> <key> <flags>*\r\n
+	"mg": {minFields: 2, hasKey: true, retrieval: true, keyIndex: 1},
+	"ms": {minFields: 3, hasKey: true},
+	"md": {minFields: 2, hasKey: true},
+	"ma": {minFields: 2, hasKey: true},
+	"mn": {minFields: 1},
+	"me": {minFields: 2, hasKey: true},
+	// The commands without keys.
+	"stats":          {minFields: 1},
+	"flush_all":      {minFields: 1},
+	"version":        {minFields: 1},
+	"verbosity":      {minFields: 2},
+	"quit":           {minFields: 1},
+	"shutdown":       {minFields: 1},
+	"cache_memlimit": {minFields: 2},
+}
+
+func fastfailTextRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] < 'a' || message.Data[0] > 'z'
+	}
+}
+
+func parseTextRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		_, line := message.ReadUntilCRLF(0)
+		fields := strings.Fields(string(line))
+		if len(fields) == 0 {
+			return false, true
+		}
+		name := fields[0]
+		command, ok := textCommands[name]
+		if !ok || len(fields) < command.minFields {
+			return false, true
+		}
+		var key string
+		if command.hasKey {
+			key = fields[1]
+		}
+		if command.retrieval {
+			key = fields[command.keyIndex]
+			message.AddIntAttribute(constlabels.MemcachedKeyCount, int64(len(fields)-command.keyIndex))
+		}
+		addCommandAttributes(message, name, key)
+		switch {
+		case name == "quit":
+			message.MarkIgnored()
+		case fields[len(fields)-1] == "noreply" && len(fields) > command.minFields:
+			// The server doesn't send the response.
+			message.MarkOneway()
+		case name == "ms" || name == "md" || name == "ma":
+			// The "q" flag suppresses the successful responses of the meta commands.
+			for _, flag := range fields[2:] {
+				if flag == "q" {
+					message.MarkOneway()
+				}
+			}
+		}
+		return true, true
+	}
+}
+
+func fastfailTextResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		first := message.Data[0]
+		return (first < 'A' || first > 'Z') && (first < '0' || first > '9')
+	}
+}
+
+var textReplies = map[string]struct{}{
+	"STORED":     {},
+	"NOT_STORED": {},
+	"EXISTS":     {},
+	"NOT_FOUND":  {},
+	"DELETED":    {},
+	"TOUCHED":    {},
+	"OK":         {},
+	"VERSION":    {},
+	"STAT":       {},
+	"RESET":      {},
+	// The replies of the meta commands.
+	"HD": {},
+	"NS": {},
+	"EX": {},
+	"NF": {},
+	"MN": {},
+	"ME": {},
+}
+
+func parseTextResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		_, line := message.ReadUntilCRLF(0)
+		if line == nil {
+			return false, true
+		}
+		reply, _, _ := bytes.Cut(line, []byte(" "))
+		switch string(reply) {
+		case "ERROR":
+			addErrorAttributes(message, "ERROR")
+			return true, true
+		case "CLIENT_ERROR", "SERVER_ERROR":
+			addErrorAttributes(message, string(line))
+			return true, true
+		case "VALUE", "END":
+			return parseTextValues(message, 0), true
+		case "VA", "EN":
+			// The replies of the meta get command.
+			if message.HasAttribute(constlabels.MemcachedKeyCount) {
+				if string(reply) == "VA" {
+					addHitAttributes(message, 1)
+				} else {
+					addHitAttributes(message, 0)
+				}
+			}
+			return true, true
+		}
+		if _, ok := textReplies[string(reply)]; ok {
+			return true, true
+		}
+		// The reply of incr/decr is the new value.
+		_, err := strconv.ParseUint(string(line), 10, 64)
+		return err == nil, true
+	}
+}
+
+/*
+VALUE <key> <flags> <bytes> [<cas unique>]\r\n<data block>\r\n
+...
+END\r\n
+*/
+func parseTextValues(message *protocol.PayloadMessage, offset int) bool {
+	var hits int64
+	for {
+		next, line := message.ReadUntilCRLF(offset)
+		if line == nil {
+			// The response is truncated, so the misses are known only if all keys are hit.
+			if hits > 0 && hits == message.GetIntAttribute(constlabels.MemcachedKeyCount) {
+				addHitAttributes(message, hits)
+			}
+			return hits > 0
+		}
+		if string(line) == "END" {
+			if message.HasAttribute(constlabels.MemcachedKeyCount) {
+				addHitAttributes(message, hits)
+			}
+			return true
+		}
+		fields := bytes.Fields(line)
+		if len(fields) < 4 || string(fields[0]) != "VALUE" {
+			return false
+		}
+		size, err := strconv.Atoi(string(fields[3]))
+		if err != nil || size < 0 {
+			return false
+		}
+		hits++
+		offset = next + size + 2
+	}
+}
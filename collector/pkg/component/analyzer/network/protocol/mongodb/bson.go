@@ -0,0 +1,150 @@
+package mongodb
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// BSON element types. See https://bsonspec.org/spec.html.
+const (
+	bsonDouble     = 0x01
+	bsonString     = 0x02
+	bsonDocument   = 0x03
+	bsonArray      = 0x04
+	bsonBinary     = 0x05
+	bsonUndefined  = 0x06
+	bsonObjectId   = 0x07
+	bsonBoolean    = 0x08
+	bsonDateTime   = 0x09
+	bsonNull       = 0x0A
+	bsonRegex      = 0x0B
+	bsonDBPointer  = 0x0C
+	bsonJavaScript = 0x0D
+	bsonSymbol     = 0x0E
+	bsonCodeWScope = 0x0F
+	bsonInt32      = 0x10
+	bsonTimestamp  = 0x11
+	bsonInt64      = 0x12
+	bsonDecimal128 = 0x13
+	bsonMinKey     = 0xFF
+	bsonMaxKey     = 0x7F
+)
+
+type bsonElement struct {
+	key   string
+	typ   byte
+	value []byte
+}
+
+// readBsonDocument calls fn for each element of the document until fn returns false.
+// The document may be truncated, in which case only the complete elements are read.
+func readBsonDocument(data []byte, fn func(element *bsonElement) bool) {
+	if len(data) < 5 {
+		return
+	}
+	length := int(int32(binary.LittleEndian.Uint32(data)))
+	if length < 5 {
+		return
+	}
+	if length < len(data) {
+		data = data[:length]
+	}
+	for offset := 4; offset < len(data) && data[offset] != 0; {
+		typ := data[offset]
+		key, valueOffset, ok := readCString(data, offset+1)
+		if !ok {
+			return
+		}
+		valueLength, ok := bsonValueLength(typ, data[valueOffset:])
+		if !ok || valueOffset+valueLength > len(data) {
+			return
+		}
+		element := &bsonElement{
+			key:   key,
+			typ:   typ,
+			value: data[valueOffset : valueOffset+valueLength],
+		}
+		if !fn(element) {
+			return
+		}
+		offset = valueOffset + valueLength
+	}
+}
+
+func bsonValueLength(typ byte, data []byte) (int, bool) {
+	switch typ {
+	case bsonUndefined, bsonNull, bsonMinKey, bsonMaxKey:
+		return 0, true
+	case bsonBoolean:
+		return 1, true
+	case bsonInt32:
+		return 4, true
+	case bsonDouble, bsonDateTime, bsonTimestamp, bsonInt64:
+		return 8, true
+	case bsonObjectId:
+		return 12, true
+	case bsonDecimal128:
+		return 16, true
+	case bsonString, bsonJavaScript, bsonSymbol:
+		length, ok := readInt32(data, 0)
+		return 4 + int(length), ok && length > 0
+	case bsonDBPointer:
+		length, ok := readInt32(data, 0)
+		return 4 + int(length) + 12, ok && length > 0
+	case bsonBinary:
+		length, ok := readInt32(data, 0)
+		return 5 + int(length), ok && length >= 0
+	case bsonDocument, bsonArray, bsonCodeWScope:
+		length, ok := readInt32(data, 0)
+		return int(length), ok && length >= 5
+	case bsonRegex:
+		_, offset, ok := readCString(data, 0)
+		if !ok {
+			return 0, false
+		}
+		_, offset, ok = readCString(data, offset)
+		return offset, ok
+	}
+	return 0, false
+}
+
+func (e *bsonElement) stringValue() (string, bool) {
+	if e.typ != bsonString || len(e.value) < 5 {
+		return "", false
+	}
+	return string(e.value[4 : len(e.value)-1]), true
+}
+
+func (e *bsonElement) numberValue() (int64, bool) {
+	switch e.typ {
+	case bsonDouble:
+		return int64(math.Float64frombits(binary.LittleEndian.Uint64(e.value))), true
+	case bsonInt32:
+		return int64(int32(binary.LittleEndian.Uint32(e.value))), true
+	case bsonInt64:
+		return int64(binary.LittleEndian.Uint64(e.value)), true
+	case bsonBoolean:
+		if e.value[0] != 0 {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func readInt32(data []byte, offset int) (int32, bool) {
+	if offset < 0 || offset+4 > len(data) {
+		return 0, false
+	}
+	return int32(binary.LittleEndian.Uint32(data[offset:])), true
+}
+
+// readCString reads a null-terminated string.
+func readCString(data []byte, offset int) (value string, toOffset int, ok bool) {
+	for i := offset; i < len(data); i++ {
+		if data[i] == 0 {
+			return string(data[offset:i]), i + 1, true
+		}
+	}
+	return "", len(data), false
+}
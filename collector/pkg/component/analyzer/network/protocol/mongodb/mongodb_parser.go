@@ -0,0 +1,113 @@
+package mongodb
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+/*
+https://www.mongodb.com/docs/manual/reference/mongodb-wire-protocol/
+
+		              Request                            Response
+		/                |           \             /       |         \
+	OP_MSG          OP_QUERY    OP_COMPRESSED   OP_MSG  OP_REPLY  OP_COMPRESSED
+
+The driver sends the next request only after the response of the previous one is received,
+so the requests are merged and the response is paired with the request by requestID/responseTo.
+*/
+func NewMongodbParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailMongodbRequest(), parseMongodbRequest())
+	requestParser.Add(fastfailMongodbOpMsg(), parseMongodbOpMsgRequest())
+	requestParser.Add(fastfailMongodbOpQuery(), parseMongodbOpQuery())
+	requestParser.Add(fastfailMongodbOpCompressed(), parseMongodbOpCompressed())
+
+	responseParser := protocol.CreatePkgParser(fastfailMongodbResponse(), parseMongodbResponse())
+	responseParser.Add(fastfailMongodbOpMsg(), parseMongodbOpMsgResponse())
+	responseParser.Add(fastfailMongodbOpReply(), parseMongodbOpReply())
+	responseParser.Add(fastfailMongodbOpCompressed(), parseMongodbOpCompressed())
+
+	return protocol.NewProtocolParser(protocol.MONGODB, requestParser, responseParser, nil)
+}
+
+/*
+All the integers are little-endian.
+===== MsgHeader =====
+int32	messageLength	total message size, including this
+int32	requestID		identifier for this message
+int32	responseTo		requestID from the original request (used in responses from the database)
+int32	opCode			message type
+*/
+const (
+	headerLength = 16
+	// The maximum size of a message is 48MB.
+	maxMessageLength = 48 * 1000 * 1000
+
+	opReply      = 1
+	opQuery      = 2004
+	opCompressed = 2012
+	opMsg        = 2013
+)
+
+type msgHeader struct {
+	messageLength int32
+	requestId     int32
+	responseTo    int32
+	opCode        int32
+}
+
+func readHeader(data []byte) (header msgHeader, ok bool) {
+	if len(data) < headerLength {
+		return header, false
+	}
+	header.messageLength, _ = readInt32(data, 0)
+	header.requestId, _ = readInt32(data, 4)
+	header.responseTo, _ = readInt32(data, 8)
+	header.opCode, _ = readInt32(data, 12)
+	if header.messageLength < headerLength || header.messageLength > maxMessageLength {
+		return header, false
+	}
+	return header, true
+}
+
+func getOpCode(message *protocol.PayloadMessage) int32 {
+	opCode, _ := readInt32(message.Data, 12)
+	return opCode
+}
+
+func fastfailMongodbOpMsg() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return getOpCode(message) != opMsg
+	}
+}
+
+func fastfailMongodbOpCompressed() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return getOpCode(message) != opCompressed
+	}
+}
+
+/*
+===== OP_COMPRESSED =====
+MsgHeader	header
+int32		originalOpcode
+int32		uncompressedSize
+uint8		compressorId
+char*		compressedMessage
+*/
+func parseMongodbOpCompressed() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		// The compressed message could not be parsed, so only the protocol is recognized.
+		originalOpCode, ok := readInt32(message.Data, headerLength)
+		if !ok {
+			return false, true
+		}
+		return originalOpCode == opMsg || originalOpCode == opQuery || originalOpCode == opReply, true
+	}
+}
+
+func addErrorAttributes(message *protocol.PayloadMessage, code int64, errMsg string) {
+	message.AddIntAttribute(constlabels.MongodbErrCode, code)
+	message.AddUtf8StringAttribute(constlabels.MongodbErrMsg, errMsg)
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
@@ -0,0 +1,151 @@
+package mongodb
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailMongodbRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength
+	}
+}
+
+func parseMongodbRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		header, ok := readHeader(message.Data)
+		if !ok || header.responseTo != 0 {
+			return false, true
+		}
+		message.AddIntAttribute(constlabels.MongodbRequestId, int64(header.requestId))
+		return true, false
+	}
+}
+
+/*
+===== OP_MSG =====
+MsgHeader	header
+uint32		flagBits
+uint8		kind		section kind, 0 for the body and 1 for the document sequence
+document	body		the body whose first element is the command name
+...			sections	the optional document sequences
+uint32		checksum	present if the checksumPresent bit is set
+*/
+const (
+	flagMoreToCome = 1 << 1
+)
+
+func parseMongodbOpMsgRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		body, flagBits, ok := readOpMsgBody(message.Data)
+		if !ok {
+			return false, true
+		}
+		var command, collection, database string
+		readBsonDocument(body, func(element *bsonElement) bool {
+			if command == "" {
+				command = element.key
+				collection, _ = element.stringValue()
+				return true
+			}
+			if element.key == "$db" {
+				database, _ = element.stringValue()
+				return false
+			}
+			return true
+		})
+		if command == "" {
+			return false, true
+		}
+		addCommandAttributes(message, command, database, collection)
+		if flagBits&flagMoreToCome != 0 {
+			// The client doesn't expect a response.
+			message.MarkOneway()
+		}
+		return true, true
+	}
+}
+
+func readOpMsgBody(data []byte) (body []byte, flagBits uint32, ok bool) {
+	flags, ok := readInt32(data, headerLength)
+	if !ok {
+		return nil, 0, false
+	}
+	offset := headerLength + 4
+	// The body section is always sent first in practice.
+	if offset >= len(data) || data[offset] != 0 {
+		return nil, 0, false
+	}
+	return data[offset+1:], uint32(flags), true
+}
+
+/*
+===== OP_QUERY =====
+It is deprecated, but still used by the drivers for the handshake.
+MsgHeader	header
+int32		flags
+cstring		fullCollectionName	"dbname.collectionname", the collection is "$cmd" for commands
+int32		numberToSkip
+int32		numberToReturn
+document	query
+*/
+func fastfailMongodbOpQuery() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return getOpCode(message) != opQuery
+	}
+}
+
+func parseMongodbOpQuery() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		fullCollectionName, offset, ok := readCString(message.Data, headerLength+4)
+		if !ok {
+			return false, true
+		}
+		database, collection, found := strings.Cut(fullCollectionName, ".")
+		if !found || database == "" {
+			return false, true
+		}
+		if collection != "$cmd" {
+			addCommandAttributes(message, "find", database, collection)
+			return true, true
+		}
+		var command string
+		offset += 8
+		if offset < len(message.Data) {
+			readBsonDocument(message.Data[offset:], func(element *bsonElement) bool {
+				command = element.key
+				collection, _ = element.stringValue()
+				return false
+			})
+		}
+		if command == "" {
+			return false, true
+		}
+		addCommandAttributes(message, command, database, collection)
+		return true, true
+	}
+}
+
+// addCommandAttributes adds the command and its target. The content key is formatted as
+// "command db.collection". The collection is empty for the commands like "ping" and "hello".
+func addCommandAttributes(message *protocol.PayloadMessage, command string, database string, collection string) {
+	message.AddUtf8StringAttribute(constlabels.MongodbCommand, command)
+	contentKey := command
+	if database != "" {
+		message.AddUtf8StringAttribute(constlabels.MongodbDatabase, database)
+	}
+	if collection != "" {
+		message.AddUtf8StringAttribute(constlabels.MongodbCollection, collection)
+	}
+	switch {
+	case database != "" && collection != "":
+		contentKey += " " + database + "." + collection
+	case database != "":
+		contentKey += " " + database
+	case collection != "":
+		contentKey += " " + collection
+	}
+	message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+}
@@ -0,0 +1,136 @@
+package mongodb
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailMongodbResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength
+	}
+}
+
+func parseMongodbResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		header, ok := readHeader(message.Data)
+		if !ok {
+			return false, true
+		}
+		// Pair the response with the request.
+		if message.HasAttribute(constlabels.MongodbRequestId) &&
+			message.GetIntAttribute(constlabels.MongodbRequestId) != int64(header.responseTo) {
+			return false, true
+		}
+		return true, false
+	}
+}
+
+func parseMongodbOpMsgResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		body, _, ok := readOpMsgBody(message.Data)
+		if !ok {
+			return false, true
+		}
+		parseReplyDocument(message, body)
+		return true, true
+	}
+}
+
+/*
+===== OP_REPLY =====
+MsgHeader	header
+int32		responseFlags	bit 1 is set when the query failed
+int64		cursorID
+int32		startingFrom
+int32		numberReturned
+document*	documents
+*/
+const (
+	flagQueryFailure = 1 << 1
+)
+
+func fastfailMongodbOpReply() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return getOpCode(message) != opReply
+	}
+}
+
+func parseMongodbOpReply() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		responseFlags, ok := readInt32(message.Data, headerLength)
+		if !ok {
+			return false, true
+		}
+		offset := headerLength + 20
+		if offset >= len(message.Data) {
+			return true, true
+		}
+		if responseFlags&flagQueryFailure != 0 {
+			// The document contains "$err" and "code".
+			var code int64
+			var errMsg string
+			readBsonDocument(message.Data[offset:], func(element *bsonElement) bool {
+				switch element.key {
+				case "$err":
+					errMsg, _ = element.stringValue()
+				case "code":
+					code, _ = element.numberValue()
+				}
+				return true
+			})
+			addErrorAttributes(message, code, errMsg)
+			return true, true
+		}
+		parseReplyDocument(message, message.Data[offset:])
+		return true, true
+	}
+}
+
+// parseReplyDocument reads the error information of the command reply. The command fails if "ok" is 0,
+// and the write commands may also report the errors in "writeErrors" with "ok" being 1.
+// "ok" could be missing if the reply is truncated, in which case the command is considered successful.
+func parseReplyDocument(message *protocol.PayloadMessage, document []byte) {
+	var (
+		code     int64
+		errMsg   string
+		hasError bool
+	)
+	readBsonDocument(document, func(element *bsonElement) bool {
+		switch element.key {
+		case "ok":
+			if okValue, ok := element.numberValue(); ok && okValue == 0 {
+				hasError = true
+			}
+		case "errmsg":
+			errMsg, _ = element.stringValue()
+		case "code":
+			code, _ = element.numberValue()
+		case "writeErrors":
+			if element.typ != bsonArray || hasError {
+				return true
+			}
+			// Only the first error is reported.
+			readBsonDocument(element.value, func(writeError *bsonElement) bool {
+				if writeError.typ != bsonDocument {
+					return false
+				}
+				readBsonDocument(writeError.value, func(field *bsonElement) bool {
+					switch field.key {
+					case "code":
+						code, _ = field.numberValue()
+					case "errmsg":
+						errMsg, _ = field.stringValue()
+					}
+					return true
+				})
+				hasError = true
+				return false
+			})
+		}
+		return true
+	})
+	if hasError {
+		addErrorAttributes(message, code, errMsg)
+	}
+}
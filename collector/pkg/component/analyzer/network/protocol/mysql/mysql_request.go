@@ -95,7 +95,7 @@ func fastfailMysqlQuit() protocol.FastFailFn {
 
 func parseMysqlQuit() protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
-		message.AddBoolAttribute(constlabels.Oneway, true)
+		message.MarkIgnored()
 		return true, true
 	}
 }
@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+/*
+	            Request                                 Response
+	/              |            \                          |
+
+simple query   extended query   terminate            error / others
+*/
+func NewPostgresqlParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailPostgresqlRequest(), parsePostgresqlRequest())
+	requestParser.Add(fastfailPostgresqlSimpleQuery(), parsePostgresqlSimpleQuery())
+	requestParser.Add(fastfailPostgresqlExtendedQuery(), parsePostgresqlExtendedQuery())
+	requestParser.Add(fastfailPostgresqlTerminate(), parsePostgresqlTerminate())
+
+	responseParser := protocol.CreatePkgParser(fastfailPostgresqlResponse(), parsePostgresqlResponse())
+
+	return protocol.NewProtocolParser(protocol.POSTGRESQL, requestParser, responseParser, nil)
+}
+
+/*
+All messages except the startup ones have the same format.
+byte1	message type
+int32	length of message contents in bytes, including self
+payload
+*/
+const headerLength = 5
+
+// readMessage returns the type and the payload of the message starting at the offset.
+// The payload may be truncated if the data is not fully captured.
+func readMessage(message *protocol.PayloadMessage, offset int) (msgType byte, payload []byte, toOffset int, ok bool) {
+	if offset+headerLength > len(message.Data) {
+		return 0, nil, protocol.EOF, false
+	}
+	var length int32
+	if _, err := message.ReadInt32(offset+1, &length); err != nil || length < 4 {
+		return 0, nil, protocol.EOF, false
+	}
+	start := offset + headerLength
+	end := offset + 1 + int(length)
+	if end > len(message.Data) {
+		end = len(message.Data)
+	}
+	return message.Data[offset], message.Data[start:end], end, true
+}
+
+// readCString reads a null-terminated string from the data.
+func readCString(data []byte, offset int) (value string, toOffset int) {
+	for i := offset; i < len(data); i++ {
+		if data[i] == 0 {
+			return string(data[offset:i]), i + 1
+		}
+	}
+	if offset >= len(data) {
+		return "", len(data)
+	}
+	return string(data[offset:]), len(data)
+}
@@ -0,0 +1,192 @@
+package postgresql
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mysql/tools"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	msgQuery     = 'Q'
+	msgParse     = 'P'
+	msgBind      = 'B'
+	msgDescribe  = 'D'
+	msgExecute   = 'E'
+	msgSync      = 'S'
+	msgFlush     = 'H'
+	msgClose     = 'C'
+	msgTerminate = 'X'
+)
+
+func fastfailPostgresqlRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength
+	}
+}
+
+func parsePostgresqlRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		return true, false
+	}
+}
+
+/*
+===== Query =====
+byte1('Q')	Identifies the message as a simple query.
+int32		Length of message contents in bytes, including self.
+string		The query string itself.
+*/
+func fastfailPostgresqlSimpleQuery() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] != msgQuery
+	}
+}
+
+func parsePostgresqlSimpleQuery() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		_, payload, _, ok := readMessage(message, 0)
+		if !ok {
+			return false, true
+		}
+		sql, _ := readCString(payload, 0)
+		if !isSql(sql) {
+			return false, true
+		}
+		addSqlAttributes(message, sql)
+		return true, true
+	}
+}
+
+/*
+The extended query is sent as a batch of messages, e.g. Parse/Bind/Describe/Execute/Sync.
+Parse and Describe could be omitted if the statement has been prepared before.
+===== Parse =====
+byte1('P')	Identifies the message as a Parse command.
+int32		Length of message contents in bytes, including self.
+string		The name of the destination prepared statement.
+string		The query string to be parsed.
+...
+===== Bind =====
+byte1('B')	Identifies the message as a Bind command.
+int32		Length of message contents in bytes, including self.
+string		The name of the destination portal.
+string		The name of the source prepared statement.
+...
+*/
+func fastfailPostgresqlExtendedQuery() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] != msgParse && message.Data[0] != msgBind
+	}
+}
+
+func parsePostgresqlExtendedQuery() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			sql       string
+			statement string
+			executed  bool
+		)
+		for offset := 0; offset < len(message.Data); {
+			msgType, payload, toOffset, ok := readMessage(message, offset)
+			if !ok {
+				// The data may be truncated.
+				break
+			}
+			switch msgType {
+			case msgParse:
+				var next int
+				statement, next = readCString(payload, 0)
+				sql, _ = readCString(payload, next)
+				if !isSql(sql) {
+					return false, true
+				}
+			case msgBind:
+				_, next := readCString(payload, 0)
+				if sql == "" {
+					statement, _ = readCString(payload, next)
+				}
+			case msgExecute:
+				executed = true
+			case msgDescribe, msgSync, msgFlush, msgClose:
+			default:
+				return false, true
+			}
+			offset = toOffset
+		}
+		if sql != "" {
+			addSqlAttributes(message, sql)
+			return true, true
+		}
+		// The statement has been prepared before, so the query string is unknown here.
+		if !executed {
+			return false, true
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, "execute "+statement)
+		return true, true
+	}
+}
+
+/*
+===== Terminate =====
+byte1('X')	Identifies the message as a termination.
+int32(4)	Length of message contents in bytes, including self.
+*/
+func fastfailPostgresqlTerminate() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.Data[0] != msgTerminate || len(message.Data) != headerLength
+	}
+}
+
+func parsePostgresqlTerminate() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		message.MarkIgnored()
+		return true, true
+	}
+}
+
+func addSqlAttributes(message *protocol.PayloadMessage, sql string) {
+	message.AddUtf8StringAttribute(constlabels.Sql, sql)
+	message.AddUtf8StringAttribute(constlabels.ContentKey, tools.SQL_MERGER.ParseStatement(sql))
+}
+
+var sqlPrefixs = []string{
+	"select",
+	"insert",
+	"update",
+	"delete",
+	"with",
+	"drop",
+	"create",
+	"alter",
+	"truncate",
+	"set",
+	"show",
+	"begin",
+	"start",
+	"commit",
+	"rollback",
+	"savepoint",
+	"release",
+	"copy",
+	"explain",
+	"lock",
+	"listen",
+	"notify",
+	"vacuum",
+	"analyze",
+	"call",
+	"do",
+}
+
+func isSql(sql string) bool {
+	lowerSql := strings.ToLower(strings.TrimSpace(sql))
+
+	for _, prefix := range sqlPrefixs {
+		if strings.HasPrefix(lowerSql, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	msgParseComplete     = '1'
+	msgBindComplete      = '2'
+	msgCloseComplete     = '3'
+	msgCommandComplete   = 'C'
+	msgDataRow           = 'D'
+	msgErrorResponse     = 'E'
+	msgEmptyQuery        = 'I'
+	msgNoData            = 'n'
+	msgNoticeResponse    = 'N'
+	msgParameterDesc     = 't'
+	msgPortalSuspended   = 's'
+	msgReadyForQuery     = 'Z'
+	msgRowDescription    = 'T'
+	msgParameterStatus   = 'S'
+	msgNotificationReply = 'A'
+)
+
+func isBackendMessage(msgType byte) bool {
+	switch msgType {
+	case msgParseComplete, msgBindComplete, msgCloseComplete, msgCommandComplete, msgDataRow,
+		msgErrorResponse, msgEmptyQuery, msgNoData, msgNoticeResponse, msgParameterDesc,
+		msgPortalSuspended, msgReadyForQuery, msgRowDescription, msgParameterStatus, msgNotificationReply:
+		return true
+	}
+	return false
+}
+
+func fastfailPostgresqlResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength || !isBackendMessage(message.Data[0])
+	}
+}
+
+/*
+The response is a batch of messages ended with ReadyForQuery.
+===== ErrorResponse =====
+byte1('E')	Identifies the message as an error.
+int32		Length of message contents in bytes, including self.
+The message body consists of one or more identified fields, followed by a zero byte as a terminator.
+
+	byte1	A code identifying the field type, e.g. 'S'(severity), 'C'(SQLSTATE code), 'M'(message).
+	string	The field value.
+*/
+func parsePostgresqlResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		for offset := 0; offset < len(message.Data); {
+			msgType, payload, toOffset, ok := readMessage(message, offset)
+			if !ok {
+				// The data may be truncated.
+				break
+			}
+			if !isBackendMessage(msgType) {
+				return false, true
+			}
+			if msgType == msgErrorResponse {
+				parseErrorFields(message, payload)
+				return true, true
+			}
+			offset = toOffset
+		}
+		return true, true
+	}
+}
+
+func parseErrorFields(message *protocol.PayloadMessage, payload []byte) {
+	var severity, sqlState, errorMessage string
+	for offset := 0; offset < len(payload) && payload[offset] != 0; {
+		fieldType := payload[offset]
+		var value string
+		value, offset = readCString(payload, offset+1)
+		switch fieldType {
+		case 'V':
+			severity = value
+		case 'S':
+			if severity == "" {
+				severity = value
+			}
+		case 'C':
+			sqlState = value
+		case 'M':
+			errorMessage = value
+		}
+	}
+	message.AddStringAttribute(constlabels.SqlState, sqlState)
+	if severity != "" {
+		errorMessage = severity + ": " + errorMessage
+	}
+	message.AddUtf8StringAttribute(constlabels.SqlErrMsg, errorMessage)
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
@@ -1,14 +1,20 @@
 package protocol
 
 const (
-	HTTP      = "http"
-	DNS       = "dns"
-	KAFKA     = "kafka"
-	MYSQL     = "mysql"
-	REDIS     = "redis"
-	DUBBO     = "dubbo"
-	ROCKETMQ  = "rocketmq"
-	NOSUPPORT = "NOSUPPORT"
+	HTTP       = "http"
+	DNS        = "dns"
+	KAFKA      = "kafka"
+	MYSQL      = "mysql"
+	REDIS      = "redis"
+	DUBBO      = "dubbo"
+	ROCKETMQ   = "rocketmq"
+	POSTGRESQL = "postgresql"
+	HTTP2      = "http2"
+	GRPC       = "grpc"
+	MONGODB    = "mongodb"
+	MEMCACHED  = "memcached"
+	AMQP       = "amqp"
+	NOSUPPORT  = "NOSUPPORT"
 )
 
 var payloadLength map[string]int = map[string]int{}
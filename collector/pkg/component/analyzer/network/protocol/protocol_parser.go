@@ -7,6 +7,7 @@ import (
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tools"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 
 	cmap "github.com/orcaman/concurrent-map"
 )
@@ -17,6 +18,12 @@ const (
 	PARSE_COMPLETE = 2
 
 	EOF = -1
+
+	// PairMatchFail means the response doesn't match any request.
+	PairMatchFail = -1
+	// PairMatchOneway means the response is a one-way message which doesn't reply to any request,
+	// e.g. the message delivered by the broker. It is reported without a request.
+	PairMatchOneway = -2
 )
 
 var (
@@ -109,6 +116,25 @@ func (message PayloadMessage) HasAttribute(key string) bool {
 	return message.attributeMap.HasAttribute(key)
 }
 
+// MarkOneway declares the message expects no response, e.g. a Kafka produce request with acks=0.
+// It is reported as a completed message instead of a NoResponse error.
+func (message PayloadMessage) MarkOneway() {
+	message.attributeMap.AddBoolValue(constlabels.Oneway, true)
+}
+
+func (message PayloadMessage) IsOneway() bool {
+	return message.attributeMap.GetBoolValue(constlabels.Oneway)
+}
+
+// MarkIgnored declares the message is not worth reporting, e.g. the command closing the connection.
+func (message PayloadMessage) MarkIgnored() {
+	message.attributeMap.AddBoolValue(constlabels.Ignored, true)
+}
+
+func (message PayloadMessage) IsIgnored() bool {
+	return message.attributeMap.GetBoolValue(constlabels.Ignored)
+}
+
 // =============== PayLoad ===============
 func (message *PayloadMessage) ReadUInt16(offset int) (value uint16, err error) {
 	if offset < 0 {
@@ -374,6 +400,9 @@ func (message *PayloadMessage) ReadUntilCRLF(from int) (offset int, data []byte)
 
 type FastFailFn func(message *PayloadMessage) bool
 type ParsePkgFn func(message *PayloadMessage) (success bool, complete bool)
+
+// PairMatch returns the index of the request which the response replies to, or PairMatchFail/PairMatchOneway.
+// The one-way requests expect no responses, so they should never be matched.
 type PairMatch func(requests []*PayloadMessage, response *PayloadMessage) int
 
 type ProtocolParser struct {
@@ -382,6 +411,7 @@ type ProtocolParser struct {
 	requestParser  PkgParser
 	responseParser PkgParser
 	pairMatch      PairMatch
+	newSession     NewStreamSessionFn
 	portCounter    cmap.ConcurrentMap
 }
 
@@ -399,6 +429,24 @@ func (parser *ProtocolParser) EnableMultiFrame() {
 	parser.multiFrames = true
 }
 
+// EnableMultiStreams marks the protocol as multiplexing concurrent streams on one connection.
+// The request and response parsers are then used to discern the protocol only, and the streams
+// are parsed by the sessions created by newSession.
+func (parser *ProtocolParser) EnableMultiStreams(newSession NewStreamSessionFn) {
+	parser.newSession = newSession
+}
+
+func (parser *ProtocolParser) MultiStreams() bool {
+	return parser.newSession != nil
+}
+
+func (parser *ProtocolParser) NewStreamSession() StreamSession {
+	if parser.newSession == nil {
+		return nil
+	}
+	return parser.newSession()
+}
+
 func (parser *ProtocolParser) GetProtocol() string {
 	return parser.protocol
 }
@@ -409,7 +457,7 @@ func (parser *ProtocolParser) MultiRequests() bool {
 
 func (parser *ProtocolParser) PairMatch(requests []*PayloadMessage, response *PayloadMessage) int {
 	if parser.pairMatch == nil {
-		return -1
+		return PairMatchFail
 	}
 	return parser.pairMatch(requests, response)
 }
@@ -0,0 +1,31 @@
+package protocol
+
+import "github.com/Kindling-project/kindling/collector/pkg/model"
+
+// StreamFrame is the part of a stream carried by one message. Protocols like HTTP/2 multiplex
+// many concurrent streams on one connection, so a message could carry frames of different streams
+// and a stream could be split into many messages.
+type StreamFrame struct {
+	StreamId uint32
+	// Headers is true if the frame carries a header block, which starts a new stream in requests.
+	Headers bool
+	// Reset is true if the stream is terminated immediately in both directions.
+	Reset bool
+	// EndStream is true if this is the last frame of the stream in the current direction.
+	EndStream bool
+	// Size is the number of bytes the frame takes up on the wire.
+	Size int
+	// Attributes contains the labels parsed from the frame, which will be merged into the stream.
+	Attributes *model.AttributeMap
+}
+
+// StreamSession keeps the states shared by all the streams of one connection, e.g. the HPACK
+// dynamic tables of HTTP/2. The messages must be passed to the session in the order they are
+// sent in each direction. The size is the real size of the message, which is larger than the
+// data if the data is truncated.
+type StreamSession interface {
+	ParseRequest(data []byte, size int) (frames []*StreamFrame, ok bool)
+	ParseResponse(data []byte, size int) (frames []*StreamFrame, ok bool)
+}
+
+type NewStreamSessionFn func() StreamSession
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const (
+	// maxUnfinishedStreams limits the streams waiting for their responses on one connection.
+	maxUnfinishedStreams = 1000
+	// idleSessionTimeout is how long the session of an idle connection is kept. gRPC channels
+	// are closed after being idle for 30 minutes by default.
+	idleSessionTimeout = 30 * time.Minute
+)
+
+// stream is a request/response exchange multiplexed on one connection with other streams.
+// The events of a stream may also contain the frames of other streams.
+type stream struct {
+	requests      *events
+	responses     *events
+	lastRequest   *model.KindlingEvent
+	lastResponse  *model.KindlingEvent
+	requestSize   int64
+	responseSize  int64
+	responseEnded bool
+	attributes    *model.AttributeMap
+}
+
+func newStream() *stream {
+	return &stream{
+		attributes: model.NewAttributeMap(),
+	}
+}
+
+func (s *stream) addRequestFrame(evt *model.KindlingEvent, frame *protocol.StreamFrame, maxPayloadLength int) {
+	if s.lastRequest != evt {
+		if s.requests == nil {
+			s.requests = newEvents(evt, maxPayloadLength)
+		} else {
+			s.requests.mergeEvent(evt)
+		}
+		s.lastRequest = evt
+	}
+	s.requestSize += int64(frame.Size)
+	s.attributes.Merge(frame.Attributes)
+	if frame.Reset {
+		s.responseEnded = true
+	}
+}
+
+func (s *stream) addResponseFrame(evt *model.KindlingEvent, frame *protocol.StreamFrame, maxPayloadLength int) {
+	if s.lastResponse != evt {
+		if s.responses == nil {
+			s.responses = newEvents(evt, maxPayloadLength)
+		} else {
+			s.responses.mergeEvent(evt)
+		}
+		s.lastResponse = evt
+	}
+	s.responseSize += int64(frame.Size)
+	s.attributes.Merge(frame.Attributes)
+	if frame.EndStream {
+		s.responseEnded = true
+	}
+}
+
+// parseMultipleStreams parses the messagePairs of the protocols multiplexing streams on one
+// connection. The streams are paired by their ids, and the unfinished ones are kept in the
+// messagePairs and will be passed to the next messagePairs of the same connection.
+func (na *NetworkAnalyzer) parseMultipleStreams(mps *messagePairs, parser *protocol.ProtocolParser) []*model.DataGroup {
+	if mps.streamParser != parser {
+		// This is a new connection, so check whether the protocol matches first.
+		requestMsg := protocol.NewRequestMessage(mps.requests.getData(), mps.requests.event.Ctx.FdInfo.GetProtocol())
+		if !parser.ParseRequest(requestMsg) {
+			return nil
+		}
+		mps.streamParser = parser
+		mps.streamSession = parser.NewStreamSession()
+		mps.streams = make(map[uint32]*stream)
+	}
+
+	size := mps.requests.size()
+	for i := 0; i < size; i++ {
+		req := mps.requests.getEvent(i)
+		frames, _ := mps.streamSession.ParseRequest(req.GetData(), int(req.GetResVal()))
+		for _, frame := range frames {
+			s, ok := mps.streams[frame.StreamId]
+			if !ok {
+				// Only a header block starts a new stream. Other frames belong to the streams
+				// started before we trace the connection.
+				if !frame.Headers || len(mps.streams) >= maxUnfinishedStreams {
+					continue
+				}
+				s = newStream()
+				mps.streams[frame.StreamId] = s
+			}
+			s.addRequestFrame(req, frame, mps.maxPayloadLength)
+		}
+	}
+	if mps.responses != nil {
+		size = mps.responses.size()
+		for i := 0; i < size; i++ {
+			resp := mps.responses.getEvent(i)
+			frames, _ := mps.streamSession.ParseResponse(resp.GetData(), int(resp.GetResVal()))
+			for _, frame := range frames {
+				if s, ok := mps.streams[frame.StreamId]; ok {
+					s.addResponseFrame(resp, frame, mps.maxPayloadLength)
+				}
+			}
+		}
+	}
+
+	records := make([]*model.DataGroup, 0)
+	noResponseThreshold := uint64(na.cfg.getNoResponseThreshold()) * uint64(1e9)
+	lastTimestamp := mps.getTimeoutTs()
+	for _, streamId := range getSortedStreamIds(mps.streams) {
+		s := mps.streams[streamId]
+		requestTimestamp := s.requests.getLastTimestamp()
+		if s.responseEnded || (lastTimestamp > requestTimestamp && lastTimestamp-requestTimestamp >= noResponseThreshold) {
+			records = append(records, na.getStreamRecord(mps, parser, s))
+			delete(mps.streams, streamId)
+		}
+	}
+	return records
+}
+
+// inheritStreams passes the unfinished streams to the next messagePairs of the same connection.
+func (mps *messagePairs) inheritStreams(oldPairs *messagePairs) {
+	mps.streamParser = oldPairs.streamParser
+	mps.streamSession = oldPairs.streamSession
+	mps.streams = oldPairs.streams
+}
+
+// idleSession is the stream session of a connection whose messagePairs have been distributed
+// because no data was sent for a while. The session holds the states of the whole connection,
+// like the HPACK dynamic tables, so it is passed to the next messagePairs of the connection.
+type idleSession struct {
+	// tuple is used to check whether the fd still refers to the same connection.
+	tuple     messagePairKey
+	parser    *protocol.ProtocolParser
+	session   protocol.StreamSession
+	idleSince time.Time
+}
+
+func getConnectionTuple(evt *model.KindlingEvent) messagePairKey {
+	return messagePairKey{
+		pid:   evt.GetPid(),
+		fd:    evt.GetFd(),
+		sip:   evt.GetSip(),
+		dip:   evt.GetDip(),
+		sport: evt.GetSport(),
+		dport: evt.GetDport(),
+	}
+}
+
+// keepIdleSession keeps the session of the connection whose messagePairs are distributed
+// without the next messagePairs.
+func (na *NetworkAnalyzer) keepIdleSession(mps *messagePairs) {
+	if mps.streamSession == nil || mps.requests == nil {
+		return
+	}
+	na.idleSessions.Store(mps.getKey(), &idleSession{
+		tuple:     getConnectionTuple(mps.requests.event),
+		parser:    mps.streamParser,
+		session:   mps.streamSession,
+		idleSince: time.Now(),
+	})
+}
+
+// restoreIdleSession passes the session kept for the connection to the messagePairs.
+func (na *NetworkAnalyzer) restoreIdleSession(mps *messagePairs) {
+	if mps.streamParser != nil || mps.requests == nil {
+		return
+	}
+	value, ok := na.idleSessions.LoadAndDelete(mps.getKey())
+	if !ok {
+		return
+	}
+	idle := value.(*idleSession)
+	if idle.tuple != getConnectionTuple(mps.requests.event) {
+		// The fd has been reused by another connection.
+		return
+	}
+	mps.streamParser = idle.parser
+	mps.streamSession = idle.session
+	mps.streams = make(map[uint32]*stream)
+}
+
+// removeExpiredIdleSessions removes the sessions of the connections which are probably closed.
+func (na *NetworkAnalyzer) removeExpiredIdleSessions() {
+	na.idleSessions.Range(func(k, v interface{}) bool {
+		if time.Since(v.(*idleSession).idleSince) >= idleSessionTimeout {
+			na.idleSessions.Delete(k)
+		}
+		return true
+	})
+}
+
+// getUnfinishedStreamRecords returns the records of the streams without complete responses
+// when the connection is not used anymore.
+func (na *NetworkAnalyzer) getUnfinishedStreamRecords(mps *messagePairs) []*model.DataGroup {
+	records := make([]*model.DataGroup, 0, len(mps.streams))
+	for _, streamId := range getSortedStreamIds(mps.streams) {
+		records = append(records, na.getStreamRecord(mps, mps.streamParser, mps.streams[streamId]))
+	}
+	mps.streams = nil
+	return records
+}
+
+func (na *NetworkAnalyzer) getStreamRecord(mps *messagePairs, parser *protocol.ProtocolParser, s *stream) *model.DataGroup {
+	streamPairs := &messagePairs{
+		requests:         s.requests,
+		responses:        s.responses,
+		natTuple:         mps.natTuple,
+		maxPayloadLength: mps.maxPayloadLength,
+	}
+	protocolName := parser.GetProtocol()
+	if s.attributes.HasAttribute(constlabels.Protocol) {
+		protocolName = s.attributes.GetStringValue(constlabels.Protocol)
+	}
+	record := na.getRecords(streamPairs, protocolName, s.attributes)[0]
+	// The events are shared by the streams, so only the frames of this stream are counted.
+	record.UpdateAddIntMetric(constvalues.RequestIo, s.requestSize)
+	record.UpdateAddIntMetric(constvalues.ResponseIo, s.responseSize)
+	return record
+}
+
+func getSortedStreamIds(streams map[uint32]*stream) []uint32 {
+	ids := make([]uint32, 0, len(streams))
+	for id := range streams {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+// urlRule clusters the URLs of the HTTP requests sent to a workload or some ports.
+type urlRule struct {
+	workloadName string
+	ports        map[uint32]bool
+	ruleSet      *urlclustering.RuleSet
+}
+
+// urlRules overrides the content_key of the HTTP records with the user-defined rules. The first rule
+// that matches both the destination and the URL wins. The content_key is kept if none matches.
+type urlRules struct {
+	rules []*urlRule
+	// metadata is used to find the workload of the destination. It is the global Kubernetes
+	// metadata cache except in tests.
+	metadata *kubernetes.K8sMetaDataCache
+}
+
+func newUrlRules(configs []UrlRuleConfig, metadata *kubernetes.K8sMetaDataCache) (*urlRules, error) {
+	rules := make([]*urlRule, 0, len(configs))
+	for i, config := range configs {
+		if config.WorkloadName == "" && len(config.Ports) == 0 {
+			return nil, fmt.Errorf("url_rules[%d]: either workload_name or ports is required", i)
+		}
+		var templates []string
+		if config.OpenAPI != "" {
+			var err error
+			if templates, err = urlclustering.LoadOpenAPITemplates(config.OpenAPI); err != nil {
+				return nil, fmt.Errorf("url_rules[%d]: %w", i, err)
+			}
+		}
+		ruleSet, err := urlclustering.NewRuleSet(config.Rewrites, templates)
+		if err != nil {
+			return nil, fmt.Errorf("url_rules[%d]: %w", i, err)
+		}
+		rule := &urlRule{
+			workloadName: config.WorkloadName,
+			ports:        make(map[uint32]bool),
+			ruleSet:      ruleSet,
+		}
+		for _, port := range config.Ports {
+			rule.ports[port] = true
+		}
+		rules = append(rules, rule)
+	}
+	return &urlRules{rules: rules, metadata: metadata}, nil
+}
+
+func (r *urlRules) apply(record *model.DataGroup) {
+	labels := record.Labels
+	protocolName := labels.GetStringValue(constlabels.Protocol)
+	if protocolName != protocol.HTTP && protocolName != protocol.HTTP2 {
+		return
+	}
+	url := labels.GetStringValue(constlabels.HttpUrl)
+	if url == "" {
+		return
+	}
+	dstPort := uint32(labels.GetIntValue(constlabels.DstPort))
+	dnatPort := labels.GetIntValue(constlabels.DnatPort)
+	var workloadName *string
+	for _, rule := range r.rules {
+		if len(rule.ports) > 0 && !rule.ports[dstPort] && (dnatPort <= 0 || !rule.ports[uint32(dnatPort)]) {
+			continue
+		}
+		if rule.workloadName != "" {
+			// The workload is found only once for each record.
+			if workloadName == nil {
+				name := r.getDstWorkloadName(labels)
+				workloadName = &name
+			}
+			if rule.workloadName != *workloadName {
+				continue
+			}
+		}
+		if contentKey, ok := rule.ruleSet.Match(url); ok {
+			labels.UpdateAddStringValue(constlabels.ContentKey, contentKey)
+			return
+		}
+	}
+}
+
+// getDstWorkloadName returns the workload of the destination. The real destination translated by
+// DNAT is preferred, otherwise the destination could be a pod or a service.
+func (r *urlRules) getDstWorkloadName(labels *model.AttributeMap) string {
+	if r.metadata == nil {
+		return ""
+	}
+	if dnatIp := labels.GetStringValue(constlabels.DnatIp); dnatIp != "" {
+		dnatPort := labels.GetIntValue(constlabels.DnatPort)
+		if containerInfo, ok := r.metadata.GetContainerByIpPort(dnatIp, uint32(dnatPort)); ok {
+			return containerInfo.RefPodInfo.WorkloadName
+		}
+	}
+	dstIp := labels.GetStringValue(constlabels.DstIp)
+	dstPort := uint32(labels.GetIntValue(constlabels.DstPort))
+	if containerInfo, ok := r.metadata.GetContainerByIpPort(dstIp, dstPort); ok {
+		return containerInfo.RefPodInfo.WorkloadName
+	}
+	if serviceInfo, ok := r.metadata.GetServiceByIpPort(dstIp, dstPort); ok {
+		return serviceInfo.WorkloadName
+	}
+	return ""
+}
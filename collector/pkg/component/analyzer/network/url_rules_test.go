@@ -0,0 +1,67 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+func TestUrlRules(t *testing.T) {
+	openapi := filepath.Join(t.TempDir(), "order.yaml")
+	assert.NoError(t, os.WriteFile(openapi, []byte(`openapi: 3.0.0
+paths:
+  /v1/orders/{orderId}: {}
+  /v1/orders/search: {}
+`), 0644))
+
+	metadata := kubernetes.New()
+	metadata.AddContainerByIpPort("10.0.0.1", 8080, &kubernetes.K8sContainerInfo{
+		RefPodInfo: &kubernetes.K8sPodInfo{WorkloadName: "order-service"},
+	})
+	rules, err := newUrlRules([]UrlRuleConfig{
+		{WorkloadName: "order-service", OpenAPI: openapi},
+		{Ports: []uint32{9090}, Rewrites: []urlclustering.RewriteRule{{Pattern: `^/users/\w+$`, Replacement: "/users/{name}"}}},
+	}, metadata)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		protocol   string
+		dstIp      string
+		dstPort    int64
+		url        string
+		contentKey string
+	}{
+		{"matched by workload", "http", "10.0.0.1", 8080, "/v1/orders/42?verbose=true", "/v1/orders/{orderId}"},
+		{"literal route preferred", "http2", "10.0.0.1", 8080, "/v1/orders/search", "/v1/orders/search"},
+		{"matched by port", "http", "10.0.0.2", 9090, "/users/john", "/users/{name}"},
+		{"url not matched", "http", "10.0.0.2", 9090, "/orders/1", "/orders/*"},
+		{"workload not matched", "http", "10.0.0.2", 8080, "/v1/orders/42", "/*/orders/*"},
+		{"not http", "grpc", "10.0.0.1", 8080, "/v1/orders/42", "/*/orders/*"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := model.NewAttributeMap()
+			labels.AddStringValue(constlabels.Protocol, tt.protocol)
+			labels.AddStringValue(constlabels.DstIp, tt.dstIp)
+			labels.AddIntValue(constlabels.DstPort, tt.dstPort)
+			labels.AddIntValue(constlabels.DnatPort, -1)
+			labels.AddStringValue(constlabels.HttpUrl, tt.url)
+			labels.AddStringValue(constlabels.ContentKey, urlclustering.AlphabeticClustering(tt.url))
+			record := model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 0)
+			rules.apply(record)
+			assert.Equal(t, tt.contentKey, record.Labels.GetStringValue(constlabels.ContentKey))
+		})
+	}
+
+	_, err = newUrlRules([]UrlRuleConfig{{OpenAPI: openapi}}, metadata)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package processanalyzer
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/process"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type analyzer.Type = "processanalyzer"
+
+// ProcessAnalyzer consumes the procexit events to expire the processes cached by
+// processmetadataprocessor. It produces no data.
+type ProcessAnalyzer struct {
+	cfg       *Config
+	telemetry *component.TelemetryTools
+}
+
+func New(cfg interface{}, telemetry *component.TelemetryTools, _ []consumer.Consumer) analyzer.Analyzer {
+	config, ok := cfg.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert processanalyzer config")
+	}
+	return &ProcessAnalyzer{
+		cfg:       config,
+		telemetry: telemetry,
+	}
+}
+
+func (a *ProcessAnalyzer) Start() error {
+	return nil
+}
+
+func (a *ProcessAnalyzer) ConsumeEvent(event *model.KindlingEvent) error {
+	// The event is also received when a thread exits.
+	if event.Name != constnames.ProcessExitEvent || event.GetPid() != event.GetTid() {
+		return nil
+	}
+	process.ExpireProcess(int(event.GetPid()))
+	return nil
+}
+
+func (a *ProcessAnalyzer) Shutdown() error {
+	return nil
+}
+
+func (a *ProcessAnalyzer) Type() analyzer.Type {
+	return Type
+}
+
+func (a *ProcessAnalyzer) ConsumableEvents() []string {
+	return []string{constnames.ProcessExitEvent}
+}
+
+type Config struct {
+}
@@ -36,6 +36,60 @@ func ParseTraceHeader(headers map[string]string) (string, string) {
 	return "", ""
 }
 
+// ParseParentSpanId returns the span id in the headers of traceType, which is the parent of the
+// span of this request. It is empty if the trace type doesn't propagate a compatible span id
+// or the header is malformed.
+func ParseParentSpanId(traceType string, headers map[string]string) string {
+	switch traceType {
+	case "zipkin":
+		return headers["x-b3-spanid"]
+	case "jaeger":
+		// {trace-id}:{span-id}:{parent-span-id}:{flags}
+		if parts := strings.Split(headers["uber-trace-id"], ":"); len(parts) == 4 &&
+			len(parts[1]) <= 16 && isNonZeroHex(parts[1]) {
+			return parts[1]
+		}
+	case "w3c":
+		// {version}-{trace-id}-{parent-id}-{trace-flags}
+		// The traceresponse contains the span id of the server, which is not the parent.
+		parts := strings.Split(headers["traceparent"], "-")
+		if len(parts) != 4 {
+			return ""
+		}
+		version, traceId, parentId, flags := parts[0], parts[1], parts[2], parts[3]
+		// The version "ff" is forbidden.
+		if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+			return ""
+		}
+		if len(traceId) != 32 || !isNonZeroHex(traceId) || len(flags) != 2 || !isLowerHex(flags) {
+			return ""
+		}
+		if len(parentId) == 16 && isNonZeroHex(parentId) {
+			return parentId
+		}
+	}
+	return ""
+}
+
+// isLowerHex returns whether the value is not empty and consists of the lowercase hex digits.
+func isLowerHex(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isNonZeroHex returns whether the value is an id in lowercase hex, which is invalid if all the digits are zero.
+func isNonZeroHex(value string) bool {
+	return isLowerHex(value) && strings.Trim(value, "0") != ""
+}
+
 // See the doc
 // https://github.com/apache/skywalking/blob/master/docs/en/protocols/Skywalking-Cross-Process-Propagation-Headers-Protocol-v3.md
 func parseSkyWalkingTraceId(value string) string {
@@ -34,6 +34,51 @@ func TestParseTraceHeader(t *testing.T) {
 	}
 }
 
+func TestParseParentSpanId(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		traceType string
+		spanId    string
+	}{
+		{name: "skywalking", key: "sw8", value: "1-OWZhM2VkOGRhZDhmNGExNWFkYjIzNDgyNWJmYzcxMzUuNTQuMTY2MDE4OTQxNzk2MzAwMDE=-OWZhM2V", traceType: "skywalking", spanId: ""},
+		{name: "zipkin", key: "x-b3-spanid", value: "e457b5a2e4d86bd1", traceType: "zipkin", spanId: "e457b5a2e4d86bd1"},
+		{name: "jaeger", key: "uber-trace-id", value: "3997ed0a6a71f050:cf49be2de63d86e7:e02475aab05fd358:1", traceType: "jaeger", spanId: "cf49be2de63d86e7"},
+		{name: "w3c-request", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-00", traceType: "w3c", spanId: "d75597dee50b0cac"},
+		{name: "w3c-response", key: "traceresponse", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-828c5d0d435ba505-01", traceType: "w3c", spanId: ""},
+		{name: "jaeger-short-span-id", key: "uber-trace-id", value: "3997ed0a6a71f050:86e7:0:1", traceType: "jaeger", spanId: "86e7"},
+		{name: "jaeger-too-few-parts", key: "uber-trace-id", value: "3997ed0a6a71f050:cf49be2de63d86e7:1", traceType: "jaeger", spanId: ""},
+		{name: "jaeger-empty-span-id", key: "uber-trace-id", value: "3997ed0a6a71f050::e02475aab05fd358:1", traceType: "jaeger", spanId: ""},
+		{name: "jaeger-zero-span-id", key: "uber-trace-id", value: "3997ed0a6a71f050:0000000000000000:0:1", traceType: "jaeger", spanId: ""},
+		{name: "jaeger-non-hex-span-id", key: "uber-trace-id", value: "3997ed0a6a71f050:cf49be2de63d86eg:0:1", traceType: "jaeger", spanId: ""},
+		{name: "jaeger-long-span-id", key: "uber-trace-id", value: "3997ed0a6a71f050:cf49be2de63d86e7ab:0:1", traceType: "jaeger", spanId: ""},
+		{name: "w3c-too-few-parts", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac", traceType: "w3c", spanId: ""},
+		{name: "w3c-too-many-parts", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-00-01", traceType: "w3c", spanId: ""},
+		{name: "w3c-misplaced-dashes", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e47361d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-long-version", key: "traceparent", value: "00000-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-forbidden-version", key: "traceparent", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-non-hex-version", key: "traceparent", value: "0x-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-uppercase-span-id", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-D75597DEE50B0CAC-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-uppercase-trace-id", key: "traceparent", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-zero-span-id", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-zero-trace-id", key: "traceparent", value: "00-00000000000000000000000000000000-d75597dee50b0cac-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-short-span-id", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0c-00", traceType: "w3c", spanId: ""},
+		{name: "w3c-non-hex-flags", key: "traceparent", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-d75597dee50b0cac-0z", traceType: "w3c", spanId: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{
+				tt.key: tt.value,
+			}
+
+			if spanId := ParseParentSpanId(tt.traceType, headers); spanId != tt.spanId {
+				t.Errorf("Fail to check spanId, got = %s, want %s", spanId, tt.spanId)
+			}
+		})
+	}
+}
+
 func Test_parseSkyWalkingTraceId(t *testing.T) {
 	type args struct {
 		value string
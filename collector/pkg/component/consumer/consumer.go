@@ -5,3 +5,9 @@ import "github.com/Kindling-project/kindling/collector/pkg/model"
 type Consumer interface {
 	Consume(dataGroup *model.DataGroup) error
 }
+
+// Shutdowner is implemented by the consumers holding resources like goroutines or connections,
+// which are released when the application shuts down.
+type Shutdowner interface {
+	Shutdown() error
+}
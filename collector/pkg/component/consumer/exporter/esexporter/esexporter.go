@@ -1,6 +1,10 @@
 package esexporter
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	"github.com/Kindling-project/kindling/collector/pkg/esclient"
@@ -12,8 +16,13 @@ import (
 
 const Type = "esexporter"
 
+// reconnectInterval is how often the exporter tries to create the client again if it failed.
+const reconnectInterval = 30 * time.Second
+
 type EsExporter struct {
-	esClient *esclient.EsClient
+	esClient    *esclient.EsClient
+	mutex       sync.Mutex
+	lastConnect time.Time
 
 	telemetry *component.TelemetryTools
 	config    *Config
@@ -22,8 +31,9 @@ type EsExporter struct {
 func New(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
 	cfg, _ := config.(*Config)
 	ret := &EsExporter{
-		config:    cfg,
-		telemetry: telemetry,
+		config:      cfg,
+		telemetry:   telemetry,
+		lastConnect: time.Now(),
 	}
 	client, err := esclient.NewEsClient(cfg.GetEsHost())
 	if err != nil {
@@ -49,16 +59,17 @@ func (e *EsExporter) Consume(dataGroup *model.DataGroup) error {
 		// We don't care about metrics now.
 		return nil
 	case constnames.SingleNetRequestMetricGroup:
-		e.sendTrace(dataGroup)
-		return nil
+		return e.sendTrace(dataGroup)
 	}
 	return nil
 }
 
-func (e *EsExporter) sendTrace(dataGroup *model.DataGroup) {
+// sendTrace returns the error of indexing the trace, so it can be retried by a retry queue.
+func (e *EsExporter) sendTrace(dataGroup *model.DataGroup) error {
 	e.telemetry.Logger.Info("Will send a trace to ElasticSearch")
-	if e.esClient == nil {
-		return
+	esClient, err := e.getEsClient()
+	if err != nil {
+		return err
 	}
 	trace := TraceData{
 		Name:      dataGroup.Name,
@@ -69,11 +80,26 @@ func (e *EsExporter) sendTrace(dataGroup *model.DataGroup) {
 	for _, metric := range dataGroup.Metrics {
 		trace.Metrics[metric.Name] = metric.GetInt().Value
 	}
-	if e.esClient != nil {
-		_ = e.esClient.IndexJson(e.config.GetEsIndexName(), trace)
-	} else {
-		e.telemetry.Logger.Infof("EsClient is nil, the trace should have been sent: %v", trace)
+	return esClient.IndexJson(e.config.GetEsIndexName(), trace)
+}
+
+// getEsClient creates the client again if Elasticsearch was unavailable when the exporter started.
+// It is retried at most once per reconnectInterval, and the traces are rejected in between.
+func (e *EsExporter) getEsClient() (*esclient.EsClient, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.esClient == nil {
+		if wait := reconnectInterval - time.Since(e.lastConnect); wait > 0 {
+			return nil, fmt.Errorf("elasticsearch is unavailable, will reconnect in %v", wait.Round(time.Second))
+		}
+		e.lastConnect = time.Now()
+		client, err := esclient.NewEsClient(e.config.GetEsHost())
+		if err != nil {
+			return nil, err
+		}
+		e.esClient = client
 	}
+	return e.esClient, nil
 }
 
 type TraceData struct {
@@ -0,0 +1,116 @@
+package kafkaexporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	jsonEncoding     = "json"
+	protobufEncoding = "protobuf"
+
+	noPartitionKey       = "none"
+	workloadPartitionKey = "workload"
+	pidPartitionKey      = "pid"
+)
+
+type Config struct {
+	Brokers []string `mapstructure:"brokers"`
+	// Topics of each kind of data. The data won't be exported if its topic is empty.
+	Topics *TopicsConfig `mapstructure:"topics"`
+	// Encoding is "json" or "protobuf". The schema of "protobuf" is in datagroup.proto.
+	Encoding string `mapstructure:"encoding"`
+	// PartitionKey decides which data are sent to the same partition in order.
+	// It is "workload", "pid" or "none". The data are sent to random partitions if the key is "none" or
+	// the data don't have the corresponding labels.
+	PartitionKey string `mapstructure:"partition_key"`
+	// Compression is "none", "gzip", "snappy", "lz4" or "zstd". "zstd" requires kafka_version >= "2.1.0".
+	Compression string       `mapstructure:"compression"`
+	Batch       *BatchConfig `mapstructure:"batch"`
+	// KafkaVersion is the version of the brokers, e.g. "2.1.0". It is "1.0.0" if empty.
+	KafkaVersion string `mapstructure:"kafka_version"`
+	ClientId     string `mapstructure:"client_id"`
+	// RequiredAcks is 0 (no response), 1 (the leader only) or -1 (all the in-sync replicas).
+	RequiredAcks int `mapstructure:"required_acks"`
+	// The unit is second.
+	Timeout int `mapstructure:"timeout"`
+}
+
+type TopicsConfig struct {
+	Traces       string `mapstructure:"traces"`
+	Metrics      string `mapstructure:"metrics"`
+	CameraEvents string `mapstructure:"camera_events"`
+}
+
+type BatchConfig struct {
+	// MaxMessages is the best-effort number of messages of a batch.
+	MaxMessages int `mapstructure:"max_messages"`
+	// MaxBytes is the best-effort size of a batch.
+	MaxBytes int `mapstructure:"max_bytes"`
+	// FlushInterval is the max time the messages are buffered. The unit is millisecond.
+	FlushInterval int `mapstructure:"flush_interval"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Brokers: []string{"localhost:9092"},
+		Topics: &TopicsConfig{
+			Traces:       "kindling_traces",
+			Metrics:      "kindling_metrics",
+			CameraEvents: "kindling_camera_events",
+		},
+		Encoding:     jsonEncoding,
+		PartitionKey: workloadPartitionKey,
+		Compression:  "none",
+		Batch: &BatchConfig{
+			MaxMessages:   1000,
+			MaxBytes:      1024 * 1024,
+			FlushInterval: 1000,
+		},
+		ClientId:     "kindling",
+		RequiredAcks: 1,
+		Timeout:      10,
+	}
+}
+
+func (cfg *Config) toSaramaConfig() (*sarama.Config, error) {
+	ret := sarama.NewConfig()
+	if cfg.ClientId != "" {
+		ret.ClientID = cfg.ClientId
+	}
+	if cfg.KafkaVersion != "" {
+		version, err := sarama.ParseKafkaVersion(cfg.KafkaVersion)
+		if err != nil {
+			return nil, err
+		}
+		ret.Version = version
+	}
+	switch cfg.Compression {
+	case "", "none":
+		ret.Producer.Compression = sarama.CompressionNone
+	case "gzip":
+		ret.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		ret.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		ret.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		ret.Producer.Compression = sarama.CompressionZSTD
+	default:
+		return nil, fmt.Errorf("unknown compression %q", cfg.Compression)
+	}
+	if cfg.Batch != nil {
+		ret.Producer.Flush.Messages = cfg.Batch.MaxMessages
+		ret.Producer.Flush.Bytes = cfg.Batch.MaxBytes
+		ret.Producer.Flush.Frequency = time.Duration(cfg.Batch.FlushInterval) * time.Millisecond
+	}
+	ret.Producer.RequiredAcks = sarama.RequiredAcks(cfg.RequiredAcks)
+	if cfg.Timeout > 0 {
+		ret.Producer.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	ret.Producer.Return.Successes = false
+	ret.Producer.Return.Errors = true
+	return ret, ret.Validate()
+}
@@ -0,0 +1,143 @@
+package kafkaexporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+type encoder interface {
+	encode(dataGroup *model.DataGroup) ([]byte, error)
+}
+
+func newEncoder(encoding string) (encoder, error) {
+	switch encoding {
+	case "", jsonEncoding:
+		return &jsonEncoder{}, nil
+	case protobufEncoding:
+		return &protobufEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
+
+// jsonEncoder encodes the data groups following their JSON tags.
+type jsonEncoder struct{}
+
+func (e *jsonEncoder) encode(dataGroup *model.DataGroup) ([]byte, error) {
+	return json.Marshal(dataGroup)
+}
+
+// protobufEncoder encodes the data groups as the message DataGroup in datagroup.proto.
+type protobufEncoder struct{}
+
+func (e *protobufEncoder) encode(dataGroup *model.DataGroup) ([]byte, error) {
+	b := make([]byte, 0, 512)
+	b = appendString(b, 1, dataGroup.Name)
+	for _, metric := range dataGroup.Metrics {
+		b = appendMessage(b, 2, encodeMetric(metric))
+	}
+	labels := dataGroup.Labels.GetValues()
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := appendString(nil, 1, key)
+		entry = appendMessage(entry, 2, encodeAttributeValue(dataGroup.Labels, key, labels[key]))
+		b = appendMessage(b, 3, entry)
+	}
+	if dataGroup.Timestamp != 0 {
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		b = protowire.AppendVarint(b, dataGroup.Timestamp)
+	}
+	return b, nil
+}
+
+func encodeMetric(metric *model.Metric) []byte {
+	b := appendString(nil, 1, metric.Name)
+	switch metric.DataType() {
+	case model.IntMetricType:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(metric.GetInt().Value))
+	case model.HistogramMetricType:
+		histogram := metric.GetHistogram()
+		h := appendVarint(nil, 1, uint64(histogram.Sum))
+		h = appendVarint(h, 2, histogram.Count)
+		if len(histogram.ExplicitBoundaries) > 0 {
+			var packed []byte
+			for _, bound := range histogram.ExplicitBoundaries {
+				packed = protowire.AppendVarint(packed, uint64(bound))
+			}
+			h = appendMessage(h, 3, packed)
+		}
+		if len(histogram.BucketCounts) > 0 {
+			var packed []byte
+			for _, count := range histogram.BucketCounts {
+				packed = protowire.AppendVarint(packed, count)
+			}
+			h = appendMessage(h, 4, packed)
+		}
+		b = appendMessage(b, 3, h)
+	case model.SummaryMetricType:
+		summary := metric.GetSummary()
+		s := appendVarint(nil, 1, uint64(summary.Sum))
+		s = appendVarint(s, 2, summary.Count)
+		for _, q := range summary.GetQuantiles() {
+			var quantile []byte
+			quantile = protowire.AppendTag(quantile, 1, protowire.Fixed64Type)
+			quantile = protowire.AppendFixed64(quantile, math.Float64bits(q))
+			quantile = protowire.AppendTag(quantile, 2, protowire.Fixed64Type)
+			quantile = protowire.AppendFixed64(quantile, math.Float64bits(summary.Sketch.Quantile(q)))
+			s = appendMessage(s, 3, quantile)
+		}
+		b = appendMessage(b, 4, s)
+	}
+	return b
+}
+
+func encodeAttributeValue(labels *model.AttributeMap, key string, value model.AttributeValue) []byte {
+	var b []byte
+	switch value.Type() {
+	case model.IntAttributeValueType:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(labels.GetIntValue(key)))
+	case model.BooleanAttributeValueType:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(labels.GetBoolValue(key)))
+	default:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, value.ToString())
+	}
+	return b
+}
+
+// appendString appends a string field, which is omitted if empty as proto3 does.
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendVarint appends a varint field, which is omitted if zero as proto3 does.
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// appendMessage appends a length-delimited field, which could also be a packed repeated field.
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
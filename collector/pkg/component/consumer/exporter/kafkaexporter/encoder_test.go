@@ -0,0 +1,128 @@
+package kafkaexporter
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newTestDataGroup() *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.ContentKey, "/api/users")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	labels.AddBoolValue(constlabels.IsServer, true)
+	sketch := ddsketch.New(0.01)
+	sketch.Add(100)
+	return model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, 123,
+		model.NewIntMetric("request_count", 3),
+		model.NewHistogramMetric("request_histogram", &model.Histogram{
+			Sum: 300, Count: 3, ExplicitBoundaries: []int64{10, 100}, BucketCounts: []uint64{0, 3},
+		}),
+		model.NewSummaryMetric("request_quantile", &model.Summary{Sum: 100, Count: 1, Sketch: sketch, Quantiles: []float64{0.5, 0.99}}))
+}
+
+// fields decodes a message into the values of its fields. The nested messages are left as bytes.
+func fields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	ret := make(map[protowire.Number][]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			var bits uint64
+			bits, n = protowire.ConsumeFixed64(b)
+			v = math.Float64frombits(bits)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		if n < 0 {
+			t.Fatalf("invalid field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+		ret[num] = append(ret[num], v)
+	}
+	return ret
+}
+
+func TestProtobufEncoder(t *testing.T) {
+	b, err := (&protobufEncoder{}).encode(newTestDataGroup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataGroup := fields(t, b)
+	assert.Equal(t, []byte(constnames.AggregatedNetRequestMetricGroup), dataGroup[1][0])
+	assert.Equal(t, uint64(123), dataGroup[4][0])
+
+	// Metrics
+	assert.Len(t, dataGroup[2], 3)
+	count := fields(t, dataGroup[2][0].([]byte))
+	assert.Equal(t, []byte("request_count"), count[1][0])
+	assert.Equal(t, uint64(3), count[2][0])
+	histogram := fields(t, fields(t, dataGroup[2][1].([]byte))[3][0].([]byte))
+	assert.Equal(t, uint64(300), histogram[1][0])
+	assert.Equal(t, uint64(3), histogram[2][0])
+	assert.Equal(t, []byte{10, 100}, histogram[3][0])
+	assert.Equal(t, []byte{0, 3}, histogram[4][0])
+	summary := fields(t, fields(t, dataGroup[2][2].([]byte))[4][0].([]byte))
+	// The quantiles configured for the summary are encoded.
+	assert.Len(t, summary[3], 2)
+	quantile := fields(t, summary[3][0].([]byte))
+	assert.Equal(t, 0.5, quantile[1][0])
+	assert.InEpsilon(t, 100, quantile[2][0], 0.01)
+
+	// Labels are the map entries sorted by their keys.
+	labels := make(map[string]map[protowire.Number][]interface{})
+	for _, entry := range dataGroup[3] {
+		entryFields := fields(t, entry.([]byte))
+		labels[string(entryFields[1][0].([]byte))] = fields(t, entryFields[2][0].([]byte))
+	}
+	assert.Equal(t, []byte("/api/users"), labels[constlabels.ContentKey][1][0])
+	assert.Equal(t, uint64(8080), labels[constlabels.DstPort][2][0])
+	assert.Equal(t, uint64(1), labels[constlabels.IsServer][3][0])
+}
+
+func TestJsonEncoder(t *testing.T) {
+	b, err := (&jsonEncoder{}).encode(newTestDataGroup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, constnames.AggregatedNetRequestMetricGroup, got["name"])
+	assert.Equal(t, float64(123), got["timestamp"])
+	assert.Equal(t, map[string]interface{}{
+		constlabels.ContentKey: "/api/users",
+		constlabels.DstPort:    float64(8080),
+		constlabels.IsServer:   true,
+	}, got["labels"])
+	metrics := got["metrics"].([]interface{})
+	assert.Len(t, metrics, 3)
+	// The summary keeps its sum, count and the values of the configured quantiles.
+	summary := metrics[2].(map[string]interface{})["Data"].(map[string]interface{})
+	assert.Equal(t, float64(100), summary["Sum"])
+	assert.Equal(t, float64(1), summary["Count"])
+	quantiles := summary["Quantiles"].([]interface{})
+	if assert.Len(t, quantiles, 2) {
+		quantile := quantiles[1].(map[string]interface{})
+		assert.Equal(t, 0.99, quantile["Quantile"])
+		assert.InEpsilon(t, 100, quantile["Value"], 0.01)
+	}
+}
@@ -0,0 +1,245 @@
+package kafkaexporter
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/queuedretry"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type = "kafkaexporter"
+
+// reconnectInterval is how often the exporter tries to connect to the brokers again if it failed.
+const reconnectInterval = 30 * time.Second
+
+var (
+	// errBrokersUnavailable is returned if the producer is not created yet, so the data could be retried
+	// by the retry queue.
+	errBrokersUnavailable = errors.New("kafka brokers are unavailable")
+	// errProducerBusy is returned if the input of the producer is full because the brokers are too slow.
+	// The message is dropped unless it is kept by the retry queue.
+	errProducerBusy = errors.New("kafka producer is busy")
+)
+
+// KafkaExporter publishes the traces, metrics and camera events to Kafka topics. The messages are
+// batched and compressed by the producer asynchronously, and the failures are logged. The producer
+// is created in the background, so Consume never waits for the brokers. It returns an error at once
+// if the brokers are unavailable or the producer is busy, so the data can be kept by a retry queue.
+type KafkaExporter struct {
+	cfg       *Config
+	telemetry *component.TelemetryTools
+	encoder   encoder
+
+	saramaConfig *sarama.Config
+	// mutex protects the producer from being closed while sending messages.
+	mutex       sync.RWMutex
+	producer    sarama.AsyncProducer
+	lastConnect time.Time
+	connecting  bool
+	shutdown    bool
+	// newProducer creates the producer, which is replaced in the tests.
+	newProducer func(brokers []string, config *sarama.Config) (sarama.AsyncProducer, error)
+	// input buffers the messages for the producer, whose own input is unbuffered. It is drained by
+	// the forwarder started with the producer, and closed when shutting down.
+	input     chan *sarama.ProducerMessage
+	forwarded chan struct{}
+
+	// busyDropped is the number of the messages not accepted by the producer.
+	busyDropped int64
+	// unavailableDropped is the number of the messages dropped because there is no producer.
+	unavailableDropped int64
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Type))
+	}
+	e, err := newKafkaExporter(cfg, telemetry)
+	if err != nil {
+		telemetry.Logger.Panicf("Can't create new kafkaexporter: %v", err)
+	}
+	e.connect()
+	return e
+}
+
+func newKafkaExporter(cfg *Config, telemetry *component.TelemetryTools) (*KafkaExporter, error) {
+	encoder, err := newEncoder(cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	saramaConfig, err := cfg.toSaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Topics == nil {
+		cfg.Topics = &TopicsConfig{}
+	}
+	return &KafkaExporter{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		encoder:      encoder,
+		saramaConfig: saramaConfig,
+		newProducer:  sarama.NewAsyncProducer,
+		input:        make(chan *sarama.ProducerMessage, saramaConfig.ChannelBufferSize),
+		forwarded:    make(chan struct{}),
+	}, nil
+}
+
+// connect creates the producer in the background if there isn't one. It is retried at most once per
+// reconnectInterval if the brokers are not available.
+func (e *KafkaExporter) connect() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.producer != nil || e.connecting || e.shutdown || time.Since(e.lastConnect) < reconnectInterval {
+		return
+	}
+	e.lastConnect = time.Now()
+	e.connecting = true
+	go e.dial()
+}
+
+// dial creates the producer without holding the mutex because it could take as long as the dial
+// timeout and the metadata retries.
+func (e *KafkaExporter) dial() {
+	producer, err := e.newProducer(e.cfg.Brokers, e.saramaConfig)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.connecting = false
+	if err != nil {
+		e.telemetry.Logger.Errorf("Failed to connect to the kafka brokers %v, will retry in %v: %v",
+			e.cfg.Brokers, reconnectInterval, err)
+		return
+	}
+	if e.shutdown {
+		_ = producer.Close()
+		return
+	}
+	e.setProducer(producer)
+}
+
+func (e *KafkaExporter) setProducer(producer sarama.AsyncProducer) {
+	e.producer = producer
+	go func() {
+		defer close(e.forwarded)
+		for msg := range e.input {
+			producer.Input() <- msg
+		}
+	}()
+	go func() {
+		for err := range producer.Errors() {
+			e.telemetry.Logger.Warn("Failed to send the message to kafka", zap.String("topic", err.Msg.Topic),
+				zap.Error(err.Err))
+		}
+	}()
+}
+
+func (e *KafkaExporter) Consume(dataGroup *model.DataGroup) error {
+	if ce := e.telemetry.Logger.Check(zap.DebugLevel, ""); ce != nil {
+		e.telemetry.Logger.Debug(dataGroup.String())
+	}
+	topic := e.topic(dataGroup)
+	if topic == "" {
+		return nil
+	}
+	value, err := e.encoder.encode(dataGroup)
+	if err != nil {
+		return queuedretry.NewPermanentError(err)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(value),
+	}
+	if key := e.partitionKey(dataGroup); key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+	return e.enqueue(msg)
+}
+
+// enqueue buffers the message for the producer without waiting, so Consume is not blocked by slow or
+// unavailable brokers. The message is dropped if the buffer is full.
+func (e *KafkaExporter) enqueue(msg *sarama.ProducerMessage) error {
+	e.mutex.RLock()
+	if e.producer == nil {
+		e.mutex.RUnlock()
+		dropped := atomic.AddInt64(&e.unavailableDropped, 1)
+		e.telemetry.Logger.Debug("The kafka producer is not ready, and the message is not sent", zap.String("topic", msg.Topic),
+			zap.Int64("totalUnavailableDropped", dropped))
+		e.connect()
+		return errBrokersUnavailable
+	}
+	defer e.mutex.RUnlock()
+	select {
+	case e.input <- msg:
+		return nil
+	default:
+		dropped := atomic.AddInt64(&e.busyDropped, 1)
+		e.telemetry.Logger.Warn("The kafka producer is busy, and the message is not sent", zap.String("topic", msg.Topic),
+			zap.Int64("totalBusyDropped", dropped))
+		return errProducerBusy
+	}
+}
+
+func (e *KafkaExporter) topic(dataGroup *model.DataGroup) string {
+	switch dataGroup.Name {
+	case constnames.SingleNetRequestMetricGroup, constnames.SpanEvent:
+		return e.cfg.Topics.Traces
+	case constnames.CameraEventGroupName:
+		return e.cfg.Topics.CameraEvents
+	default:
+		return e.cfg.Topics.Metrics
+	}
+}
+
+func (e *KafkaExporter) partitionKey(dataGroup *model.DataGroup) string {
+	labels := dataGroup.Labels
+	switch e.cfg.PartitionKey {
+	case workloadPartitionKey:
+		// The workload of the agent side is used, which is the server for the server-side data.
+		var namespace, workload string
+		switch {
+		case labels.HasAttribute(constlabels.WorkloadName):
+			namespace, workload = labels.GetStringValue(constlabels.Namespace), labels.GetStringValue(constlabels.WorkloadName)
+		case labels.GetBoolValue(constlabels.IsServer):
+			namespace, workload = labels.GetStringValue(constlabels.DstNamespace), labels.GetStringValue(constlabels.DstWorkloadName)
+		default:
+			namespace, workload = labels.GetStringValue(constlabels.SrcNamespace), labels.GetStringValue(constlabels.SrcWorkloadName)
+		}
+		if workload == "" {
+			return ""
+		}
+		return namespace + "/" + workload
+	case pidPartitionKey:
+		if pid := labels.GetIntValue(constlabels.Pid); pid > 0 {
+			return strconv.FormatInt(pid, 10)
+		}
+	}
+	return ""
+}
+
+// Shutdown flushes the buffered messages and closes the producer.
+func (e *KafkaExporter) Shutdown() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	// The producer being created is closed once it is ready.
+	e.shutdown = true
+	if e.producer == nil {
+		return nil
+	}
+	close(e.input)
+	<-e.forwarded
+	err := e.producer.Close()
+	e.producer = nil
+	return err
+}
@@ -0,0 +1,296 @@
+package kafkaexporter
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/queuedretry"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+// fakeProducer records the messages instead of sending them.
+type fakeProducer struct {
+	input    chan *sarama.ProducerMessage
+	errors   chan *sarama.ProducerError
+	messages []*sarama.ProducerMessage
+}
+
+func newFakeProducer() *fakeProducer {
+	return &fakeProducer{
+		input:  make(chan *sarama.ProducerMessage, 10),
+		errors: make(chan *sarama.ProducerError),
+	}
+}
+
+func (p *fakeProducer) AsyncClose()                               { close(p.errors) }
+func (p *fakeProducer) Close() error                              { p.AsyncClose(); return nil }
+func (p *fakeProducer) Input() chan<- *sarama.ProducerMessage     { return p.input }
+func (p *fakeProducer) Successes() <-chan *sarama.ProducerMessage { return nil }
+func (p *fakeProducer) Errors() <-chan *sarama.ProducerError      { return p.errors }
+
+func (p *fakeProducer) received() []*sarama.ProducerMessage {
+	for len(p.input) > 0 {
+		p.messages = append(p.messages, <-p.input)
+	}
+	return p.messages
+}
+
+func newDataGroup(name string, isServer bool, pid int64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	labels.AddStringValue(constlabels.SrcNamespace, "default")
+	labels.AddStringValue(constlabels.SrcWorkloadName, "client")
+	labels.AddStringValue(constlabels.DstNamespace, "default")
+	labels.AddStringValue(constlabels.DstWorkloadName, "server")
+	labels.AddIntValue(constlabels.Pid, pid)
+	return model.NewDataGroup(name, labels, 0)
+}
+
+func TestKafkaExporter_Consume(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Topics.CameraEvents = ""
+	e, err := newKafkaExporter(cfg, component.NewDefaultTelemetryTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer := newFakeProducer()
+	e.setProducer(producer)
+
+	_ = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100))
+	_ = e.Consume(newDataGroup(constnames.AggregatedNetRequestMetricGroup, false, 100))
+	// The camera events are not exported as the topic is empty.
+	_ = e.Consume(newDataGroup(constnames.CameraEventGroupName, true, 100))
+	// The buffered messages are passed to the producer when shutting down.
+	assert.NoError(t, e.Shutdown())
+	messages := producer.received()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "kindling_traces", messages[0].Topic)
+		assert.Equal(t, sarama.StringEncoder("default/server"), messages[0].Key)
+		assert.Equal(t, "kindling_metrics", messages[1].Topic)
+		assert.Equal(t, sarama.StringEncoder("default/client"), messages[1].Key)
+	}
+
+	cfg.PartitionKey = pidPartitionKey
+	e, _ = newKafkaExporter(cfg, component.NewDefaultTelemetryTools())
+	producer = newFakeProducer()
+	e.setProducer(producer)
+	_ = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100))
+	_ = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 0))
+	assert.NoError(t, e.Shutdown())
+	messages = producer.received()
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, sarama.StringEncoder("100"), messages[0].Key)
+		assert.Nil(t, messages[1].Key)
+	}
+}
+
+func TestKafkaExporter_BrokersUnavailable(t *testing.T) {
+	e, err := newKafkaExporter(NewDefaultConfig(), component.NewDefaultTelemetryTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// It has failed to connect just now, so it won't reconnect.
+	e.lastConnect = time.Now()
+	err = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100))
+	assert.Equal(t, errBrokersUnavailable, err)
+	assert.False(t, queuedretry.IsPermanent(err))
+}
+
+func TestKafkaExporter_ProducerBusy(t *testing.T) {
+	e, err := newKafkaExporter(NewDefaultConfig(), component.NewDefaultTelemetryTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.input = make(chan *sarama.ProducerMessage, 2)
+	// The producer doesn't accept any message until it is drained.
+	producer := &fakeProducer{input: make(chan *sarama.ProducerMessage), errors: make(chan *sarama.ProducerError)}
+	e.setProducer(producer)
+	accepted := 0
+	// The buffer is full soon, and the message is dropped at once.
+	for {
+		if err = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100)); err != nil {
+			break
+		}
+		accepted++
+		// The forwarder holds one message at most.
+		assert.LessOrEqual(t, accepted, cap(e.input)+1)
+	}
+	assert.Equal(t, errProducerBusy, err)
+	assert.False(t, queuedretry.IsPermanent(err))
+	assert.Equal(t, int64(1), e.busyDropped)
+
+	var received int64
+	go func() {
+		for range producer.input {
+			atomic.AddInt64(&received, 1)
+		}
+	}()
+	assert.NoError(t, e.Shutdown())
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&received) == int64(accepted) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKafkaExporter_ConnectInBackground(t *testing.T) {
+	e, err := newKafkaExporter(NewDefaultConfig(), component.NewDefaultTelemetryTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialing := make(chan struct{})
+	producer := newFakeProducer()
+	e.newProducer = func([]string, *sarama.Config) (sarama.AsyncProducer, error) {
+		<-dialing
+		return producer, nil
+	}
+	// Consume doesn't wait for the brokers.
+	err = e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100))
+	assert.Equal(t, errBrokersUnavailable, err)
+	assert.Equal(t, int64(1), e.unavailableDropped)
+	close(dialing)
+	assert.Eventually(t, func() bool {
+		return e.Consume(newDataGroup(constnames.SingleNetRequestMetricGroup, true, 100)) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, e.Shutdown())
+
+	// The producer created after shutting down is closed.
+	e, _ = newKafkaExporter(NewDefaultConfig(), component.NewDefaultTelemetryTools())
+	dialing = make(chan struct{})
+	closed := make(chan struct{})
+	e.newProducer = func([]string, *sarama.Config) (sarama.AsyncProducer, error) {
+		<-dialing
+		return &closeNotifier{fakeProducer: newFakeProducer(), closed: closed}, nil
+	}
+	e.connect()
+	assert.NoError(t, e.Shutdown())
+	close(dialing)
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Error("the producer is not closed")
+	}
+}
+
+type closeNotifier struct {
+	*fakeProducer
+	closed chan struct{}
+}
+
+func (p *closeNotifier) Close() error {
+	close(p.closed)
+	return p.fakeProducer.Close()
+}
+
+func TestKafkaExporter_Broker(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("kindling_metrics", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t).SetVersion(3),
+	})
+
+	cfg := NewDefaultConfig()
+	cfg.Brokers = []string{broker.Addr()}
+	cfg.Encoding = jsonEncoding
+	cfg.Compression = "gzip"
+	cfg.KafkaVersion = "2.1.0"
+	cfg.RequiredAcks = -1
+	cfg.Batch = &BatchConfig{MaxMessages: 10, FlushInterval: 1000}
+	e := New(cfg, component.NewDefaultTelemetryTools()).(*KafkaExporter)
+	// The producer is created in the background.
+	assert.Eventually(t, func() bool {
+		e.mutex.RLock()
+		defer e.mutex.RUnlock()
+		return e.producer != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, e.Consume(newDataGroup(constnames.AggregatedNetRequestMetricGroup, true, int64(i))))
+	}
+	// The messages are sent in one batch.
+	assert.Eventually(t, func() bool { return len(produceRequests(broker)) == 1 }, 5*time.Second, 10*time.Millisecond)
+	_ = e.Consume(newDataGroup(constnames.AggregatedNetRequestMetricGroup, true, 0))
+	// The rest messages are sent before the producer is closed.
+	assert.NoError(t, e.Shutdown())
+	requests := produceRequests(broker)
+	if !assert.Len(t, requests, 2) {
+		return
+	}
+	request := requests[0]
+	assert.Equal(t, sarama.WaitForAll, request.RequiredAcks)
+	// The produce requests of v3 carry the record batches, which are supported since Kafka 0.11.
+	assert.Equal(t, int16(3), request.Version)
+	batches := recordBatches(request)
+	if assert.Len(t, batches, 1) {
+		assert.Equal(t, "kindling_metrics", batches[0].topic)
+		assert.Equal(t, int64(sarama.CompressionGZIP), batches[0].codec)
+		if assert.Len(t, batches[0].records, 10) {
+			record := batches[0].records[0]
+			assert.Equal(t, "default/server", string(record.key))
+			expected, _ := (&jsonEncoder{}).encode(newDataGroup(constnames.AggregatedNetRequestMetricGroup, true, 0))
+			assert.JSONEq(t, string(expected), string(record.value))
+		}
+	}
+}
+
+func produceRequests(broker *sarama.MockBroker) []*sarama.ProduceRequest {
+	ret := make([]*sarama.ProduceRequest, 0)
+	for _, rr := range broker.History() {
+		if request, ok := rr.Request.(*sarama.ProduceRequest); ok {
+			ret = append(ret, request)
+		}
+	}
+	return ret
+}
+
+type recordBatch struct {
+	topic   string
+	codec   int64
+	records []record
+}
+
+type record struct {
+	key   []byte
+	value []byte
+}
+
+// recordBatches returns the records decoded by the mock broker. They are read by reflection because
+// sarama doesn't export them.
+func recordBatches(request *sarama.ProduceRequest) []recordBatch {
+	ret := make([]recordBatch, 0)
+	topics := reflect.ValueOf(request).Elem().FieldByName("records")
+	for topicIter := topics.MapRange(); topicIter.Next(); {
+		for partitionIter := topicIter.Value().MapRange(); partitionIter.Next(); {
+			batch := partitionIter.Value().FieldByName("RecordBatch").Elem()
+			b := recordBatch{topic: topicIter.Key().String(), codec: batch.FieldByName("Codec").Int()}
+			records := batch.FieldByName("Records")
+			for i := 0; i < records.Len(); i++ {
+				r := records.Index(i).Elem()
+				b.records = append(b.records, record{key: r.FieldByName("Key").Bytes(), value: r.FieldByName("Value").Bytes()})
+			}
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	for _, modify := range []func(cfg *Config){
+		func(cfg *Config) { cfg.Encoding = "xml" },
+		func(cfg *Config) { cfg.Compression = "brotli" },
+		func(cfg *Config) { cfg.KafkaVersion = "abc" },
+		// zstd requires a newer version.
+		func(cfg *Config) { cfg.Compression = "zstd" },
+	} {
+		cfg := NewDefaultConfig()
+		modify(cfg)
+		_, err := newKafkaExporter(cfg, component.NewDefaultTelemetryTools())
+		assert.Error(t, err)
+	}
+}
@@ -12,6 +12,10 @@ type Config struct {
 	CustomLabels         map[string]string                `mapstructure:"custom_labels"`
 	MetricAggregationMap map[string]MetricAggregationKind `mapstructure:"metric_aggregation_map"`
 	AdapterConfig        *AdapterConfig                   `mapstructure:"adapter_config"`
+	// MaxSeries is the max number of the series of each metric group kept by the exporter before
+	// they are exported. Once it is reached, the new label combinations are folded into the series
+	// whose string labels are "__overflow__". 0 means no limit.
+	MaxSeries int `mapstructure:"max_series"`
 }
 
 type PrometheusConfig struct {
@@ -7,12 +7,15 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
 	apitrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Consume only returns the errors of adapting the data group. The results are exported asynchronously
+// by the OpenTelemetry SDK, which retries and reports the errors of the backend by itself.
 func (e *OtelExporter) Consume(dataGroup *model.DataGroup) error {
 	if dataGroup == nil {
 		// no need consume
@@ -24,16 +27,23 @@ func (e *OtelExporter) Consume(dataGroup *model.DataGroup) error {
 		e.telemetry.Logger.Debug("exporter receives a dataGroup: \n" + dataGroup.String())
 	}
 
+	var adaptErr error
 	for i := 0; i < len(e.adapters); i++ {
 		results, err := e.adapters[i].Adapt(dataGroup, adapter.AttributeList)
 		if err != nil {
 			e.telemetry.Logger.Error("Failed to adapt dataGroup", zap.Error(err))
+			adaptErr = err
 		}
 		if len(results) > 0 {
 			e.Export(results)
 		}
 	}
-	return nil
+	return adaptErr
+}
+
+// RetryQueueUnsupported implements queuedretry.UnsupportedExporter.
+func (e *OtelExporter) RetryQueueUnsupported() string {
+	return "the data are exported asynchronously by the OpenTelemetry SDK"
 }
 
 func (e *OtelExporter) Export(results []*adapter.AdaptedResult) {
@@ -56,12 +66,42 @@ func (e *OtelExporter) exportTrace(result *adapter.AdaptedResult) {
 		e.telemetry.Logger.Error("Send span failed: this exporter doesn't support Span Data", zap.String("exporter", e.cfg.ExportKind))
 		return
 	}
+	info := result.Span
+	if info == nil {
+		_, span := e.defaultTracer.Start(
+			context.Background(),
+			constvalues.SpanInfo,
+			apitrace.WithAttributes(result.AttrsList...),
+		)
+		span.End()
+		return
+	}
+	// Graft the span onto the trace of the applications if the trace headers are known.
+	ctx := context.Background()
+	if info.TraceId.IsValid() {
+		if info.ParentSpanId.IsValid() {
+			ctx = apitrace.ContextWithRemoteSpanContext(ctx, apitrace.NewSpanContext(apitrace.SpanContextConfig{
+				TraceID:    info.TraceId,
+				SpanID:     info.ParentSpanId,
+				TraceFlags: apitrace.FlagsSampled,
+				Remote:     true,
+			}))
+		} else {
+			ctx = contextWithTraceId(ctx, info.TraceId)
+		}
+	}
 	_, span := e.defaultTracer.Start(
-		context.Background(),
-		constvalues.SpanInfo,
+		ctx,
+		info.Name,
+		apitrace.WithSpanKind(info.Kind),
+		apitrace.WithTimestamp(info.StartTime),
 		apitrace.WithAttributes(result.AttrsList...),
+		apitrace.WithAttributes(info.Attributes...),
 	)
-	span.End()
+	if info.IsError {
+		span.SetStatus(codes.Error, info.StatusMessage)
+	}
+	span.End(apitrace.WithTimestamp(info.EndTime))
 }
 
 func (e *OtelExporter) exportMetric(result *adapter.AdaptedResult) {
@@ -85,6 +125,8 @@ func (e *OtelExporter) exportMetric(result *adapter.AdaptedResult) {
 			}
 		} else if ok && metric.DataType() == model.IntMetricType {
 			measurements = append(measurements, e.instrumentFactory.getInstrument(metric.Name, metricKind).Measurement(metric.GetInt().Value))
+		} else if metric.DataType() == model.SummaryMetricType {
+			e.instrumentFactory.recordSummary(metric.Name, metric.GetSummary(), result.AttrsList)
 		} else if metric.DataType() == model.HistogramMetricType {
 			e.telemetry.Logger.Warn("Failed to exporter Metric: can not use otlp-exporter to export histogram Data", zap.String("MetricName", metric.Name))
 		} else {
@@ -0,0 +1,55 @@
+package otelexporter
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type traceIdKey struct{}
+
+// contextWithTraceId makes the root span started with the context use the trace id. It is used
+// when the trace id of a request is known but its parent span is not.
+func contextWithTraceId(ctx context.Context, traceId trace.TraceID) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, traceId)
+}
+
+// idGenerator generates random ids like the default one of the SDK, except that the trace ids
+// set by contextWithTraceId are reused.
+type idGenerator struct {
+	sync.Mutex
+	randSource *rand.Rand
+}
+
+var _ sdktrace.IDGenerator = &idGenerator{}
+
+func newIdGenerator() *idGenerator {
+	var rngSeed int64
+	_ = binary.Read(crand.Reader, binary.LittleEndian, &rngSeed)
+	return &idGenerator{randSource: rand.New(rand.NewSource(rngSeed))}
+}
+
+func (gen *idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	gen.Lock()
+	defer gen.Unlock()
+	tid, ok := ctx.Value(traceIdKey{}).(trace.TraceID)
+	if !ok || !tid.IsValid() {
+		gen.randSource.Read(tid[:])
+	}
+	sid := trace.SpanID{}
+	gen.randSource.Read(sid[:])
+	return tid, sid
+}
+
+func (gen *idGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	gen.Lock()
+	defer gen.Unlock()
+	sid := trace.SpanID{}
+	gen.randSource.Read(sid[:])
+	return sid
+}
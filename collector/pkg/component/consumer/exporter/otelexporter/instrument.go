@@ -23,6 +23,7 @@ var traceAsMetricHelp = "Describe a single request which is abnormal. " +
 
 type instrumentFactory struct {
 	instruments  sync.Map
+	summaries    sync.Map
 	meter        metric.Meter
 	customLabels []attribute.KeyValue
 	telemetry    *component.TelemetryTools
@@ -34,7 +35,9 @@ type instrumentFactory struct {
 	K8sWorkloadSelector   *aggregator.LabelSelectors
 }
 
-func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTools, customLabels []attribute.KeyValue) *instrumentFactory {
+// newInstrumentFactory returns a factory whose aggregator keeps at most maxSeries series of each
+// metric group, and 0 means no limit.
+func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTools, customLabels []attribute.KeyValue, maxSeries int) *instrumentFactory {
 	return &instrumentFactory{
 		instruments:  sync.Map{},
 		meter:        meter,
@@ -52,6 +55,7 @@ func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTool
 					{Kind: defaultaggregator.LastKind, OutputName: constnames.K8sWorkLoadMetricName},
 				},
 			},
+			MaxSeries: maxSeries,
 		}),
 
 		traceAsMetricSelector: newTraceAsMetricSelectors(),
@@ -136,6 +140,8 @@ func newTraceAsMetricSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.Protocol, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.IsServer, VType: aggregator.BooleanType},
 		aggregator.LabelSelector{Name: constlabels.ContainerId, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessSystemdUnit, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessUser, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNamespace, VType: aggregator.StringType},
@@ -146,6 +152,7 @@ func newTraceAsMetricSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.SrcIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SrcContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNamespace, VType: aggregator.StringType},
@@ -159,6 +166,7 @@ func newTraceAsMetricSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DnatPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DstContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.DstContainerImage, VType: aggregator.StringType},
 
 		aggregator.LabelSelector{Name: constlabels.RequestReqxferStatus, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.RequestProcessingStatus, VType: aggregator.StringType},
@@ -183,6 +191,7 @@ func newTcpRttMicroSecondsSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.SrcPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SrcContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNamespace, VType: aggregator.StringType},
@@ -194,6 +203,7 @@ func newTcpRttMicroSecondsSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DstPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DstContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.DstContainerImage, VType: aggregator.StringType},
 	)
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 )
 
@@ -132,7 +133,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			traceProvider:        nil,
 			defaultTracer:        nil,
 			customLabels:         customLabels,
-			instrumentFactory:    newInstrumentFactory(exp.MeterProvider().Meter(MeterName), telemetry, customLabels),
+			instrumentFactory:    newInstrumentFactory(exp.MeterProvider().Meter(MeterName), telemetry, customLabels, cfg.MaxSeries),
 			metricAggregationMap: cfg.MetricAggregationMap,
 			telemetry:            telemetry,
 			adapters: []adapter.Adapter{
@@ -141,6 +142,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 					StoreTraceAsSpan:   cfg.AdapterConfig.NeedTraceAsResourceSpan,
 					StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
 					StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+					PodDimensions:      kubernetes.PodDimensions(),
 				}),
 				adapter.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
 					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName},
@@ -189,6 +191,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 
 		tracerProvider := sdktrace.NewTracerProvider(
 			sdktrace.WithSampler(sdktrace.AlwaysSample()),
+			sdktrace.WithIDGenerator(newIdGenerator()),
 			sdktrace.WithSpanProcessor(ssp),
 			sdktrace.WithResource(rs),
 		)
@@ -201,7 +204,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			traceProvider:        tracerProvider,
 			defaultTracer:        tracer,
 			customLabels:         customLabels,
-			instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, customLabels),
+			instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, customLabels, cfg.MaxSeries),
 			metricAggregationMap: cfg.MetricAggregationMap,
 			telemetry:            telemetry,
 			adapters: []adapter.Adapter{
@@ -210,6 +213,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 					StoreTraceAsSpan:   cfg.AdapterConfig.NeedTraceAsResourceSpan,
 					StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
 					StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+					PodDimensions:      kubernetes.PodDimensions(),
 				}),
 				adapter.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
 					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName},
@@ -222,10 +226,17 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			return nil
 		}
 	}
+	otelexporter.instrumentFactory.aggregator.RegisterSelfMetrics(Otel, telemetry.MeterProvider)
 
 	return otelexporter
 }
 
+// Shutdown stops reporting the self metrics of the aggregator.
+func (e *OtelExporter) Shutdown() error {
+	e.instrumentFactory.aggregator.UnregisterSelfMetrics()
+	return nil
+}
+
 func (e *OtelExporter) findInstrumentKind(metricName string) (MetricAggregationKind, bool) {
 	kind, find := e.metricAggregationMap[metricName]
 	return kind, find
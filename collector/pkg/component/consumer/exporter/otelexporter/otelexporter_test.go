@@ -189,7 +189,7 @@ func BenchmarkOtelExporter_Consume(b *testing.B) {
 		traceProvider:        nil,
 		defaultTracer:        nil,
 		customLabels:         nil,
-		instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, nil),
+		instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, nil, 0),
 		metricAggregationMap: cfg.MetricAggregationMap,
 		telemetry:            component.NewDefaultTelemetryTools(),
 		adapters: []adapter.Adapter{
@@ -0,0 +1,122 @@
+package otelexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newSpanExporter(recorder *tracetest.SpanRecorder) *OtelExporter {
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithIDGenerator(newIdGenerator()),
+	)
+	telemetry := component.NewDefaultTelemetryTools()
+	newSelfMetrics(telemetry.MeterProvider)
+	return &OtelExporter{
+		cfg:           &Config{ExportKind: StdoutKindExporter},
+		traceProvider: tracerProvider,
+		defaultTracer: tracerProvider.Tracer(TracerName),
+		telemetry:     telemetry,
+		adapters: []adapter.Adapter{
+			adapter.NewNetAdapter(nil, &adapter.NetAdapterConfig{StoreTraceAsSpan: true}),
+		},
+	}
+}
+
+func newSingleRequest(isServer bool, protocol string) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	labels.AddStringValue(constlabels.Protocol, protocol)
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.SrcPort, 50000)
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	return model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 1000000000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 5000000))
+}
+
+func attributesOf(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	ret := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		ret[attr.Key] = attr.Value
+	}
+	return ret
+}
+
+func TestExportTrace_HttpServer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	e := newSpanExporter(recorder)
+	dataGroup := newSingleRequest(true, constvalues.ProtocolHttp)
+	dataGroup.Labels.AddStringValue(constlabels.HttpMethod, "GET")
+	dataGroup.Labels.AddStringValue(constlabels.HttpUrl, "/api/users/1?name=a")
+	dataGroup.Labels.AddStringValue(constlabels.ContentKey, "/api/users/*")
+	dataGroup.Labels.AddIntValue(constlabels.HttpStatusCode, 500)
+	dataGroup.Labels.AddBoolValue(constlabels.IsError, true)
+	dataGroup.Labels.AddStringValue(constlabels.HttpApmTraceType, "w3c")
+	dataGroup.Labels.AddStringValue(constlabels.HttpApmTraceId, "4bf92f3577b34da6a3ce929d0e0e4736")
+	dataGroup.Labels.AddStringValue(constlabels.HttpApmParentSpanId, "d75597dee50b0cac")
+	_ = e.Consume(dataGroup)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "GET /api/users/*", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "d75597dee50b0cac", span.Parent().SpanID().String())
+	assert.Equal(t, int64(5000000), span.EndTime().Sub(span.StartTime()).Nanoseconds())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, "HTTP status code 500", span.Status().Description)
+	attrs := attributesOf(span)
+	assert.Equal(t, "/api/users/1?name=a", attrs[semconv.HTTPTargetKey].AsString())
+	assert.Equal(t, "10.0.0.2", attrs[semconv.NetHostIPKey].AsString())
+	assert.Equal(t, int64(50000), attrs[semconv.NetPeerPortKey].AsInt64())
+}
+
+func TestExportTrace_TraceIdOnly(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	e := newSpanExporter(recorder)
+	dataGroup := newSingleRequest(false, constvalues.ProtocolMysql)
+	dataGroup.Labels.AddStringValue(constlabels.Sql, "select * from users")
+	dataGroup.Labels.AddStringValue(constlabels.ContentKey, "select users")
+	// The 64-bit trace ids of Zipkin are padded.
+	dataGroup.Labels.AddStringValue(constlabels.HttpApmTraceId, "223f3b00a283c75c")
+	_ = e.Consume(dataGroup)
+	// The trace ids of SkyWalking are not compatible.
+	dataGroup.Labels.UpdateAddStringValue(constlabels.HttpApmTraceId, "9fa3ed8dad8f4a15adb234825bfc7135.54.16601894179630001")
+	_ = e.Consume(dataGroup)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "select users", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, "0000000000000000223f3b00a283c75c", span.SpanContext().TraceID().String())
+	assert.False(t, span.Parent().IsValid())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	attrs := attributesOf(span)
+	assert.Equal(t, "mysql", attrs[semconv.DBSystemKey].AsString())
+	assert.Equal(t, "select * from users", attrs[semconv.DBStatementKey].AsString())
+	assert.Equal(t, "10.0.0.2", attrs[semconv.NetPeerIPKey].AsString())
+	assert.True(t, spans[1].SpanContext().TraceID().IsValid())
+	assert.NotEqual(t, span.SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+}
@@ -0,0 +1,92 @@
+package otelexporter
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const quantileLabel = "quantile"
+
+// summaryInstrument observes the quantiles of the summary metrics as gauges with the label "quantile",
+// e.g. kindling_entity_request_duration_nanoseconds{quantile="0.99"}, together with the gauges
+// "_sum" and "_count" like the summaries of Prometheus.
+// The summaries with the same labels received between two collections are merged, and the series are
+// cleared after being observed like the other gauges.
+type summaryInstrument struct {
+	quantile metric.Float64GaugeObserver
+	sum      metric.Int64GaugeObserver
+	count    metric.Int64GaugeObserver
+	mutex    sync.Mutex
+	series   map[attribute.Distinct]*summarySeries
+}
+
+type summarySeries struct {
+	attrs     []attribute.KeyValue
+	sum       int64
+	count     uint64
+	sketch    *ddsketch.DDSketch
+	quantiles []float64
+}
+
+func (s *summaryInstrument) record(summary *model.Summary, attrs []attribute.KeyValue) {
+	if summary.Sketch == nil {
+		return
+	}
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if series, ok := s.series[key]; ok {
+		series.sum += summary.Sum
+		series.count += summary.Count
+		series.sketch.Merge(summary.Sketch)
+		return
+	}
+	// The attrs will be reused after being exported, so they are copied.
+	seriesAttrs := make([]attribute.KeyValue, len(attrs), len(attrs)+1)
+	copy(seriesAttrs, attrs)
+	s.series[key] = &summarySeries{
+		attrs:     seriesAttrs,
+		sum:       summary.Sum,
+		count:     summary.Count,
+		sketch:    summary.Sketch.Copy(),
+		quantiles: summary.GetQuantiles(),
+	}
+}
+
+func (s *summaryInstrument) observe(_ context.Context, result metric.BatchObserverResult) {
+	s.mutex.Lock()
+	series := s.series
+	s.series = make(map[attribute.Distinct]*summarySeries)
+	s.mutex.Unlock()
+	for _, v := range series {
+		for _, q := range v.quantiles {
+			attrs := append(v.attrs, attribute.String(quantileLabel, strconv.FormatFloat(q, 'f', -1, 64)))
+			result.Observe(attrs, s.quantile.Observation(v.sketch.Quantile(q)))
+		}
+		result.Observe(v.attrs, s.sum.Observation(v.sum), s.count.Observation(int64(v.count)))
+	}
+}
+
+// recordSummary records the summary metric whose quantiles will be observed in the next collection.
+func (i *instrumentFactory) recordSummary(metricName string, summary *model.Summary, attrs []attribute.KeyValue) {
+	ins, ok := i.summaries.Load(metricName)
+	if !ok {
+		newIns := &summaryInstrument{series: make(map[attribute.Distinct]*summarySeries)}
+		var loaded bool
+		if ins, loaded = i.summaries.LoadOrStore(metricName, newIns); !loaded {
+			batch := metric.Must(i.meter).NewBatchObserver(newIns.observe)
+			newIns.quantile = batch.NewFloat64GaugeObserver(metricName, WithDescription(metricName))
+			newIns.sum = batch.NewInt64GaugeObserver(metricName+"_sum", WithDescription(metricName+"_sum"))
+			newIns.count = batch.NewInt64GaugeObserver(metricName+"_count", WithDescription(metricName+"_count"))
+		}
+	}
+	ins.(*summaryInstrument).record(summary, attrs)
+}
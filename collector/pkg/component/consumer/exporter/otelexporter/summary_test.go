@@ -0,0 +1,85 @@
+package otelexporter
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	otelprocessor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func newSummary(values ...int64) *model.Summary {
+	summary := &model.Summary{Sketch: ddsketch.New(0.01)}
+	for _, value := range values {
+		summary.Sum += value
+		summary.Count++
+		summary.Sketch.Add(float64(value))
+	}
+	return summary
+}
+
+func Test_instrumentFactory_recordSummary(t *testing.T) {
+	c := controller.New(otelprocessor.NewFactory(selector.NewWithInexpensiveDistribution(),
+		aggregation.CumulativeTemporalitySelector()))
+	exp, err := prometheus.New(prometheus.Config{}, c)
+	if err != nil {
+		t.Fatalf("failed to create the prometheus exporter: %v", err)
+	}
+	ins := newInstrumentFactory(exp.MeterProvider().Meter("test"), component.NewDefaultTelemetryTools(), nil, 0)
+
+	metricName := "kindling_entity_request_duration_nanoseconds"
+	attrs := []attribute.KeyValue{attribute.String("content_key", "/orders")}
+	values := make([]int64, 0, 100)
+	for i := int64(1); i <= 100; i++ {
+		values = append(values, i*1000000)
+	}
+	// The summaries with the same labels are merged.
+	// The quantiles are the ones of the first summary of the series.
+	first := newSummary(values[:50]...)
+	first.Quantiles = []float64{0.5, 0.99}
+	ins.recordSummary(metricName, first, attrs)
+	ins.recordSummary(metricName, newSummary(values[50:]...), attrs)
+	ins.recordSummary(metricName, newSummary(1000000), []attribute.KeyValue{attribute.String("content_key", "/users")})
+
+	recorder := httptest.NewRecorder()
+	exp.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	got := make(map[string]float64)
+	totals := make(map[string]float64)
+	for _, line := range strings.Split(recorder.Body.String(), "\n") {
+		if matches := sampleRegexp.FindStringSubmatch(line); matches != nil {
+			got[matches[1]+" "+matches[2]], _ = strconv.ParseFloat(matches[3], 64)
+		} else if matches := totalRegexp.FindStringSubmatch(line); matches != nil {
+			totals[matches[2]+" "+matches[1]], _ = strconv.ParseFloat(matches[3], 64)
+		}
+	}
+	assert.Len(t, got, 5)
+	assert.InEpsilon(t, 50e6, got["/orders 0.5"], 0.01)
+	assert.InEpsilon(t, 99e6, got["/orders 0.99"], 0.01)
+	assert.Equal(t, 1e6, got["/users 0.5"])
+	assert.Equal(t, 1e6, got["/users 0.9"])
+	assert.Equal(t, 1e6, got["/users 0.99"])
+
+	assert.Len(t, totals, 4)
+	assert.Equal(t, 5050e6, totals["/orders sum"])
+	assert.Equal(t, 100.0, totals["/orders count"])
+	assert.Equal(t, 1e6, totals["/users sum"])
+	assert.Equal(t, 1.0, totals["/users count"])
+}
+
+var (
+	sampleRegexp = regexp.MustCompile(`^kindling_entity_request_duration_nanoseconds\{content_key="([^"]+)",quantile="([^"]+)".*\} (\S+)$`)
+	totalRegexp  = regexp.MustCompile(`^kindling_entity_request_duration_nanoseconds_(sum|count)\{content_key="([^"]+)".*\} (\S+)$`)
+)
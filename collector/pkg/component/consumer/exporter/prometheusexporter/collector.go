@@ -6,8 +6,6 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/aggregator/defaultaggregator"
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
-	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
-	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -32,14 +30,14 @@ func (c *collector) Collect(metrics chan<- prometheus.Metric) {
 			metric := dataGroup.Metrics[s]
 			switch metric.DataType() {
 			case model.IntMetricType:
-				metric, error := prometheus.NewConstMetric(prometheus.NewDesc(
+				metric, err := prometheus.NewConstMetric(prometheus.NewDesc(
 					sanitize(metric.Name, true),
 					"",
 					keys,
 					nil,
 					// TODO not all IntMetric are Counter, they can also be a Metric
 				), prometheus.CounterValue, float64(metric.GetInt().Value), values...)
-				if error == nil {
+				if err == nil {
 					tm := prometheus.NewMetricWithTimestamp(ts, metric)
 					metrics <- tm
 				}
@@ -50,13 +48,29 @@ func (c *collector) Collect(metrics chan<- prometheus.Metric) {
 					bound := histogram.ExplicitBoundaries[x]
 					buckets[float64(bound)] = histogram.BucketCounts[x]
 				}
-				metric, error := prometheus.NewConstHistogram(prometheus.NewDesc(
+				metric, err := prometheus.NewConstHistogram(prometheus.NewDesc(
 					sanitize(metric.Name, true),
 					"",
 					keys,
 					nil,
 				), histogram.Count, float64(histogram.Sum), buckets, values...)
-				if error == nil {
+				if err == nil {
+					tm := prometheus.NewMetricWithTimestamp(ts, metric)
+					metrics <- tm
+				}
+			case model.SummaryMetricType:
+				summary := metric.GetSummary()
+				quantiles := make(map[float64]float64, len(summary.GetQuantiles()))
+				for _, q := range summary.GetQuantiles() {
+					quantiles[q] = summary.Sketch.Quantile(q)
+				}
+				metric, err := prometheus.NewConstSummary(prometheus.NewDesc(
+					sanitize(metric.Name, true),
+					"",
+					keys,
+					nil,
+				), summary.Count, float64(summary.Sum), quantiles, values...)
+				if err == nil {
 					tm := prometheus.NewMetricWithTimestamp(ts, metric)
 					metrics <- tm
 				}
@@ -70,26 +84,10 @@ func (c *collector) recordMetricGroups(group *model.DataGroup) {
 }
 
 func newCollector(config *Config, _ *component.TelemetryLogger) *collector {
-	// TODO Do this in config later !!!!
-	requestTimeHistogramTopologyMetric := constnames.ToKindlingNetMetricName(constvalues.RequestTimeHistogram, false)
-	requestTimeHistogramEntityMetric := constnames.ToKindlingNetMetricName(constvalues.RequestTimeHistogram, true)
+	aggregatedConfig := defaultaggregator.NewExporterAggregatedConfig()
+	aggregatedConfig.MaxSeries = config.MaxSeries
 	return &collector{
-		aggregator: defaultaggregator.NewCumulativeAggregator(
-			&defaultaggregator.AggregatedConfig{
-				KindMap: map[string][]defaultaggregator.KindConfig{
-					constnames.TcpRttMetricName: {{Kind: defaultaggregator.LastKind, OutputName: constnames.TcpRttMetricName}},
-					requestTimeHistogramTopologyMetric: {{
-						Kind:               defaultaggregator.HistogramKind,
-						OutputName:         requestTimeHistogramTopologyMetric,
-						ExplicitBoundaries: []int64{10e6, 20e6, 50e6, 80e6, 130e6, 200e6, 300e6, 400e6, 500e6, 700e6, 1000e6, 2000e6, 5000e6, 30000e6},
-					}},
-					requestTimeHistogramEntityMetric: {{
-						Kind:               defaultaggregator.HistogramKind,
-						OutputName:         requestTimeHistogramEntityMetric,
-						ExplicitBoundaries: []int64{10e6, 20e6, 50e6, 80e6, 130e6, 200e6, 300e6, 400e6, 500e6, 700e6, 1000e6, 2000e6, 5000e6, 30000e6},
-					}},
-				},
-			}, time.Minute*5)}
+		aggregator: defaultaggregator.NewCumulativeAggregator(aggregatedConfig, time.Minute*5)}
 }
 
 func getTimestamp(ts uint64) time.Time {
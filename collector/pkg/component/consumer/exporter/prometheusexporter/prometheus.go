@@ -14,6 +14,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	adapter3 "github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 )
 
@@ -39,6 +40,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 	}
 
 	collector := newCollector(cfg, telemetry.Logger)
+	collector.aggregator.RegisterSelfMetrics(Type, telemetry.MeterProvider)
 	registry := prometheus.NewRegistry()
 	_ = registry.Register(collector)
 
@@ -47,6 +49,7 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 		StoreTraceAsSpan:   false,
 		StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
 		StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+		PodDimensions:      kubernetes.PodDimensions(),
 	})
 	simpleAdapter := adapter3.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
 		constnames.TcpDropMetricGroupName}, nil)
@@ -103,6 +106,12 @@ func (p *prometheusExporter) Start(_ context.Context) error {
 	return nil
 }
 
+// Shutdown stops reporting the self metrics of the aggregator.
+func (p *prometheusExporter) Shutdown() error {
+	p.collector.aggregator.UnregisterSelfMetrics()
+	return nil
+}
+
 type promLogger struct {
 	realLog *component.TelemetryLogger
 }
@@ -0,0 +1,96 @@
+package remotewriteexporter
+
+import (
+	"errors"
+	"time"
+)
+
+type Config struct {
+	// Endpoint is the URL of the remote write API, e.g. "http://prometheus:9090/api/v1/write".
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout is the timeout of each request.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Headers are added to each request, e.g. "Authorization".
+	Headers map[string]string `mapstructure:"headers"`
+	// ExternalLabels are added to all the series. They don't override the labels of the series.
+	ExternalLabels map[string]string `mapstructure:"external_labels"`
+	// MaxSeries is the max number of the series of each metric group kept by the exporter. Once it
+	// is reached, the new label combinations are folded into the series whose string labels are
+	// "__overflow__" until some series expire. 0 means no limit.
+	MaxSeries int `mapstructure:"max_series"`
+	// FlushInterval is how often the metrics are pushed.
+	FlushInterval time.Duration  `mapstructure:"flush_interval"`
+	Queue         *QueueConfig   `mapstructure:"queue"`
+	Retry         *RetryConfig   `mapstructure:"retry"`
+	AdapterConfig *AdapterConfig `mapstructure:"adapter_config"`
+}
+
+type QueueConfig struct {
+	// Capacity is the max number of the requests waiting to be sent. The oldest requests are
+	// dropped if the queue is full.
+	Capacity int `mapstructure:"capacity"`
+	// MaxSamplesPerSend is the max number of the samples in one request.
+	MaxSamplesPerSend int `mapstructure:"max_samples_per_send"`
+}
+
+type RetryConfig struct {
+	// MaxRetries is the max number of the retries of a request before it is dropped.
+	MaxRetries int `mapstructure:"max_retries"`
+	// MinBackoff is the backoff of the first retry, which is doubled for each retry until MaxBackoff.
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+type AdapterConfig struct {
+	NeedTraceAsMetric  bool `mapstructure:"need_trace_as_metric"`
+	NeedPodDetail      bool `mapstructure:"need_pod_detail"`
+	StoreExternalSrcIP bool `mapstructure:"store_external_src_ip"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Endpoint:      "http://localhost:9090/api/v1/write",
+		Timeout:       10 * time.Second,
+		FlushInterval: 15 * time.Second,
+		MaxSeries:     50000,
+		Queue: &QueueConfig{
+			Capacity:          100,
+			MaxSamplesPerSend: 2000,
+		},
+		Retry: &RetryConfig{
+			MaxRetries: 5,
+			MinBackoff: 100 * time.Millisecond,
+			MaxBackoff: 5 * time.Second,
+		},
+		AdapterConfig: &AdapterConfig{
+			NeedTraceAsMetric:  true,
+			NeedPodDetail:      true,
+			StoreExternalSrcIP: true,
+		},
+	}
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint is empty")
+	}
+	if cfg.FlushInterval <= 0 {
+		return errors.New("flush_interval must be positive")
+	}
+	if cfg.MaxSeries < 0 {
+		return errors.New("max_series must not be negative")
+	}
+	if cfg.Queue == nil || cfg.Queue.Capacity <= 0 || cfg.Queue.MaxSamplesPerSend <= 0 {
+		return errors.New("queue.capacity and queue.max_samples_per_send must be positive")
+	}
+	if cfg.Retry == nil {
+		cfg.Retry = &RetryConfig{}
+	}
+	if cfg.Retry.MaxBackoff < cfg.Retry.MinBackoff {
+		cfg.Retry.MaxBackoff = cfg.Retry.MinBackoff
+	}
+	if cfg.AdapterConfig == nil {
+		cfg.AdapterConfig = &AdapterConfig{}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package remotewriteexporter
+
+import (
+	"sync/atomic"
+)
+
+// request is a compressed WriteRequest.
+type request struct {
+	body    []byte
+	samples int
+}
+
+// queue buffers the requests in memory. There is no WAL, so the requests are lost if the agent
+// restarts.
+type queue struct {
+	ch chan *request
+	// droppedSamples is the count of the samples dropped because the queue is full.
+	droppedSamples int64
+}
+
+func newQueue(capacity int) *queue {
+	return &queue{ch: make(chan *request, capacity)}
+}
+
+// push adds the request to the queue, dropping the oldest requests if it is full.
+func (q *queue) push(r *request) {
+	for {
+		select {
+		case q.ch <- r:
+			return
+		default:
+		}
+		select {
+		case oldest := <-q.ch:
+			atomic.AddInt64(&q.droppedSamples, int64(oldest.samples))
+		default:
+		}
+	}
+}
+
+func (q *queue) size() int {
+	return len(q.ch)
+}
+
+// close stops the queue from accepting requests. The requests left can still be received.
+func (q *queue) close() {
+	close(q.ch)
+}
@@ -0,0 +1,272 @@
+package remotewriteexporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/snappy"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator/defaultaggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type = "remotewriteexporter"
+
+// metricExpiration is how long a series is pushed after it is updated for the last time.
+const metricExpiration = 5 * time.Minute
+
+// shutdownTimeout is how long Shutdown waits for the requests left to be sent. The requests in flight
+// are canceled after it.
+const shutdownTimeout = 10 * time.Second
+
+// RemoteWriteExporter pushes the metrics to the Prometheus remote write API, which works for the
+// agents that can't be scraped. The metrics are aggregated cumulatively like prometheusexporter,
+// and all the series are pushed every flush interval.
+type RemoteWriteExporter struct {
+	cfg        *Config
+	telemetry  *component.TelemetryTools
+	adapters   []adapter.Adapter
+	aggregator *defaultaggregator.CumulativeAggregator
+	client     *http.Client
+	queue      *queue
+
+	sentSamples   int64
+	failedSamples int64
+
+	// ctx is canceled if Shutdown times out.
+	ctx             context.Context
+	cancel          context.CancelFunc
+	shutdownTimeout time.Duration
+	stopCh          chan struct{}
+	stopOnce        sync.Once
+	wg              sync.WaitGroup
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Type))
+	}
+	e, err := newRemoteWriteExporter(cfg, telemetry)
+	if err != nil {
+		telemetry.Logger.Panicf("Can't create new remotewriteexporter: %v", err)
+	}
+	e.start()
+	newSelfMetrics(telemetry.MeterProvider, e)
+	e.aggregator.RegisterSelfMetrics(Type, telemetry.MeterProvider)
+	return e
+}
+
+func newRemoteWriteExporter(cfg *Config, telemetry *component.TelemetryTools) (*RemoteWriteExporter, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	aggregatedConfig := defaultaggregator.NewExporterAggregatedConfig(constnames.TraceAsMetric, constnames.K8sWorkLoadMetricName)
+	aggregatedConfig.MaxSeries = cfg.MaxSeries
+	aggregator := defaultaggregator.NewCumulativeAggregator(aggregatedConfig, metricExpiration)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RemoteWriteExporter{
+		cfg:       cfg,
+		telemetry: telemetry,
+		adapters: []adapter.Adapter{
+			adapter.NewNetAdapter(nil, &adapter.NetAdapterConfig{
+				StoreTraceAsMetric: cfg.AdapterConfig.NeedTraceAsMetric,
+				StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
+				StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+				PodDimensions:      kubernetes.PodDimensions(),
+			}),
+			adapter.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
+				constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName},
+				nil),
+		},
+		aggregator: aggregator,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		queue:      newQueue(cfg.Queue.Capacity),
+
+		ctx:             ctx,
+		cancel:          cancel,
+		shutdownTimeout: shutdownTimeout,
+		stopCh:          make(chan struct{}),
+	}, nil
+}
+
+func (e *RemoteWriteExporter) start() {
+	e.wg.Add(2)
+	go e.flushLoop()
+	go e.sendLoop()
+}
+
+func (e *RemoteWriteExporter) Consume(dataGroup *model.DataGroup) error {
+	if dataGroup == nil {
+		return nil
+	}
+	if ce := e.telemetry.Logger.Check(zap.DebugLevel, ""); ce != nil {
+		e.telemetry.Logger.Debug("exporter receives a dataGroup:\n" + dataGroup.String())
+	}
+	for i := 0; i < len(e.adapters); i++ {
+		results, err := e.adapters[i].Adapt(dataGroup, adapter.AttributeMap)
+		if err != nil {
+			e.telemetry.Logger.Error("Failed to adapt dataGroup", zap.Error(err))
+		}
+		for _, result := range results {
+			if result.ResultType == adapter.Metric {
+				e.aggregator.AggregatorWithAllLabelsAndMetric(
+					model.NewDataGroup("", result.AttrsMap, result.Timestamp, result.Metrics...), time.Now())
+			}
+			result.Free()
+		}
+	}
+	return nil
+}
+
+func (e *RemoteWriteExporter) flushLoop() {
+	defer e.wg.Done()
+	// The sender exits after the requests left are sent.
+	defer e.queue.close()
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.flush(time.Now())
+		case <-e.stopCh:
+			e.flush(time.Now())
+			return
+		}
+	}
+}
+
+// flush puts all the series into the queue, split by queue.max_samples_per_send.
+func (e *RemoteWriteExporter) flush(now time.Time) {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	batch := make([]timeSeries, 0, e.cfg.Queue.MaxSamplesPerSend)
+	for _, dataGroup := range e.aggregator.DumpAndRemoveExpired(now) {
+		for _, series := range toTimeSeries(dataGroup, e.cfg.ExternalLabels) {
+			batch = append(batch, series)
+			if len(batch) >= e.cfg.Queue.MaxSamplesPerSend {
+				e.enqueue(batch, timestamp)
+				batch = batch[:0]
+			}
+		}
+	}
+	if len(batch) > 0 {
+		e.enqueue(batch, timestamp)
+	}
+}
+
+func (e *RemoteWriteExporter) enqueue(batch []timeSeries, timestamp int64) {
+	e.queue.push(&request{
+		body:    snappy.Encode(nil, encodeWriteRequest(batch, timestamp)),
+		samples: len(batch),
+	})
+}
+
+func (e *RemoteWriteExporter) sendLoop() {
+	defer e.wg.Done()
+	for req := range e.queue.ch {
+		e.sendWithRetry(req)
+	}
+}
+
+// sendWithRetry retries the recoverable errors with exponential backoff. The retries stop when
+// the exporter is shut down.
+func (e *RemoteWriteExporter) sendWithRetry(req *request) {
+	backoff := e.cfg.Retry.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := e.send(req)
+		if err == nil {
+			atomic.AddInt64(&e.sentSamples, int64(req.samples))
+			return
+		}
+		_, recoverable := err.(recoverableError)
+		if !recoverable || attempt >= e.cfg.Retry.MaxRetries {
+			atomic.AddInt64(&e.failedSamples, int64(req.samples))
+			e.telemetry.Logger.Warnf("Failed to send %d samples to %s after %d retries: %v",
+				req.samples, e.cfg.Endpoint, attempt, err)
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-e.stopCh:
+			atomic.AddInt64(&e.failedSamples, int64(req.samples))
+			return
+		}
+		backoff *= 2
+		if backoff > e.cfg.Retry.MaxBackoff {
+			backoff = e.cfg.Retry.MaxBackoff
+		}
+	}
+}
+
+// recoverableError is the error of the requests that could succeed if retried, like the network
+// errors and the responses with status 5xx or 429.
+type recoverableError struct {
+	error
+}
+
+func (e *RemoteWriteExporter) send(req *request) error {
+	httpReq, err := http.NewRequestWithContext(e.ctx, http.MethodPost, e.cfg.Endpoint, bytes.NewReader(req.body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.cfg.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	httpReq.Header.Set("User-Agent", "kindling")
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return recoverableError{err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err = fmt.Errorf("server returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(body))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+// Shutdown pushes the metrics for the last time and waits until the requests left are sent. The
+// requests not sent within shutdownTimeout are dropped.
+func (e *RemoteWriteExporter) Shutdown() error {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+	defer e.aggregator.UnregisterSelfMetrics()
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), e.shutdownTimeout)
+	defer cancel()
+	select {
+	case <-done:
+		e.cancel()
+		return nil
+	case <-ctx.Done():
+		// The requests fail at once after being canceled, and they are not retried after stopCh is closed.
+		e.cancel()
+		<-done
+		return fmt.Errorf("failed to send the requests left to %s: %w", e.cfg.Endpoint, ctx.Err())
+	}
+}
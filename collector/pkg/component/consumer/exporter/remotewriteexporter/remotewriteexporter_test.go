@@ -0,0 +1,204 @@
+package remotewriteexporter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// remoteWriteStub is a remote write server failing the first requests with the given statuses.
+// It doesn't respond until the requests are canceled if hang is true.
+type remoteWriteStub struct {
+	t        *testing.T
+	hang     bool
+	statuses []int
+	calls    int64
+	mutex    sync.Mutex
+	series   []decodedSeries
+}
+
+func (s *remoteWriteStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	call := atomic.AddInt64(&s.calls, 1)
+	assert.Equal(s.t, "snappy", r.Header.Get("Content-Encoding"))
+	assert.Equal(s.t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+	assert.Equal(s.t, "Bearer token", r.Header.Get("Authorization"))
+	if s.hang {
+		// The server notices the canceled requests only after the body is read.
+		_, _ = io.ReadAll(r.Body)
+		<-r.Context().Done()
+		return
+	}
+	if int(call) <= len(s.statuses) {
+		w.WriteHeader(s.statuses[call-1])
+		return
+	}
+	compressed, _ := io.ReadAll(r.Body)
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mutex.Lock()
+	s.series = append(s.series, decodeWriteRequest(s.t, body)...)
+	s.mutex.Unlock()
+}
+
+func newTestExporter(t *testing.T, stub *remoteWriteStub, modify func(cfg *Config)) *RemoteWriteExporter {
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	cfg := NewDefaultConfig()
+	cfg.Endpoint = server.URL
+	cfg.Headers = map[string]string{"Authorization": "Bearer token"}
+	cfg.ExternalLabels = map[string]string{"cluster": "prod"}
+	cfg.Retry.MinBackoff = time.Millisecond
+	if modify != nil {
+		modify(cfg)
+	}
+	e, err := newRemoteWriteExporter(cfg, component.NewDefaultTelemetryTools())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.start()
+	return e
+}
+
+func newTcpRttDataGroup() *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	return model.NewDataGroup(constnames.TcpRttMetricGroupName, labels, 0,
+		model.NewIntMetric(constnames.TcpRttMetricName, 100))
+}
+
+func TestRemoteWriteExporter_Retry(t *testing.T) {
+	stub := &remoteWriteStub{t: t, statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	e := newTestExporter(t, stub, func(cfg *Config) { cfg.FlushInterval = 100 * time.Millisecond })
+	_ = e.Consume(newTcpRttDataGroup())
+	// The retries are stopped when shutting down, so wait until the request is sent.
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&e.sentSamples) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, e.Shutdown())
+
+	// The series are pushed again when shutting down.
+	assert.Equal(t, int64(len(stub.statuses)+len(stub.series)), stub.calls)
+	if assert.NotEmpty(t, stub.series) {
+		assert.Equal(t, map[string]string{
+			nameLabel:         constnames.TcpRttMetricName,
+			constlabels.SrcIp: "10.0.0.1",
+			constlabels.DstIp: "10.0.0.2",
+			"cluster":         "prod",
+		}, stub.series[0].labels)
+		assert.Equal(t, 100.0, stub.series[0].value)
+	}
+}
+
+func TestRemoteWriteExporter_Unrecoverable(t *testing.T) {
+	stub := &remoteWriteStub{t: t, statuses: []int{http.StatusBadRequest}}
+	e := newTestExporter(t, stub, nil)
+	_ = e.Consume(newTcpRttDataGroup())
+	assert.NoError(t, e.Shutdown())
+
+	// The requests with status 4xx are not retried.
+	assert.Equal(t, int64(1), stub.calls)
+	assert.Equal(t, int64(1), e.failedSamples)
+}
+
+func TestRemoteWriteExporter_ShutdownTimeout(t *testing.T) {
+	stub := &remoteWriteStub{t: t, hang: true}
+	e := newTestExporter(t, stub, nil)
+	e.shutdownTimeout = 100 * time.Millisecond
+	_ = e.Consume(newTcpRttDataGroup())
+	start := time.Now()
+	// The request in flight is canceled when the timeout is reached.
+	assert.Error(t, e.Shutdown())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(1), stub.calls)
+	assert.Equal(t, int64(1), e.failedSamples)
+}
+
+func TestRemoteWriteExporter_MaxSamplesPerSend(t *testing.T) {
+	stub := &remoteWriteStub{t: t}
+	e := newTestExporter(t, stub, func(cfg *Config) { cfg.Queue.MaxSamplesPerSend = 2 })
+	for _, ip := range []string{"10.0.0.3", "10.0.0.4", "10.0.0.5"} {
+		dataGroup := newTcpRttDataGroup()
+		dataGroup.Labels.UpdateAddStringValue(constlabels.DstIp, ip)
+		_ = e.Consume(dataGroup)
+	}
+	assert.NoError(t, e.Shutdown())
+
+	assert.Equal(t, int64(2), stub.calls)
+	assert.Len(t, stub.series, 3)
+}
+
+func TestRemoteWriteExporter_PodDimensions(t *testing.T) {
+	kubernetes.SetPodDimensions([]string{"app.kubernetes.io/version", "team"}, nil)
+	defer kubernetes.SetPodDimensions(nil, nil)
+	stub := &remoteWriteStub{t: t}
+	e := newTestExporter(t, stub, nil)
+	for _, isServer := range []bool{true, false} {
+		labels := model.NewAttributeMap()
+		labels.AddBoolValue(constlabels.IsServer, isServer)
+		labels.AddStringValue(constlabels.Protocol, "http")
+		labels.AddStringValue(constlabels.SrcNamespace, "default")
+		labels.AddStringValue(constlabels.DstNamespace, "default")
+		labels.AddStringValue(constlabels.SrcLabelPrefix+"team", "frontend")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"app_kubernetes_io_version", "v1.2.0")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"team", "payment")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"ignored", "true")
+		_ = e.Consume(model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, 0,
+			model.NewIntMetric(constvalues.RequestCount, 1)))
+	}
+	assert.NoError(t, e.Shutdown())
+
+	var entity, topology map[string]string
+	for _, series := range stub.series {
+		switch series.labels[nameLabel] {
+		case "kindling_entity_request_total":
+			entity = series.labels
+		case "kindling_topology_request_total":
+			topology = series.labels
+		}
+	}
+	if assert.NotNil(t, entity) {
+		assert.Equal(t, "v1.2.0", entity["label_app_kubernetes_io_version"])
+		assert.Equal(t, "payment", entity["label_team"])
+		assert.NotContains(t, entity, "label_ignored")
+	}
+	if assert.NotNil(t, topology) {
+		assert.Equal(t, "frontend", topology["src_label_team"])
+		assert.Equal(t, "payment", topology["dst_label_team"])
+		assert.Equal(t, "v1.2.0", topology["dst_label_app_kubernetes_io_version"])
+		assert.NotContains(t, topology, "dst_label_ignored")
+	}
+}
+
+func TestRemoteWriteExporter_MaxSeries(t *testing.T) {
+	stub := &remoteWriteStub{t: t}
+	e := newTestExporter(t, stub, func(cfg *Config) { cfg.MaxSeries = 2 })
+	for _, ip := range []string{"10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"} {
+		dataGroup := newTcpRttDataGroup()
+		dataGroup.Labels.UpdateAddStringValue(constlabels.DstIp, ip)
+		_ = e.Consume(dataGroup)
+	}
+	assert.NoError(t, e.Shutdown())
+
+	// The last two series are folded into the overflow series.
+	dstIps := make([]string, 0, len(stub.series))
+	for _, series := range stub.series {
+		dstIps = append(dstIps, series.labels[constlabels.DstIp])
+	}
+	assert.ElementsMatch(t, []string{"10.0.0.3", "10.0.0.4", "__overflow__"}, dstIps)
+}
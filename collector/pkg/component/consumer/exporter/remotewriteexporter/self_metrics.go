@@ -0,0 +1,33 @@
+package remotewriteexporter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	samplesTotalMetric = "kindling_telemetry_remotewriteexporter_samples_total"
+	queueSizeMetric    = "kindling_telemetry_remotewriteexporter_queue_size"
+)
+
+var once sync.Once
+
+func newSelfMetrics(meterProvider metric.MeterProvider, e *RemoteWriteExporter) {
+	once.Do(func() {
+		meter := metric.Must(meterProvider.Meter("kindling"))
+		meter.NewInt64CounterObserver(samplesTotalMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				result.Observe(atomic.LoadInt64(&e.sentSamples), attribute.String("result", "success"))
+				result.Observe(atomic.LoadInt64(&e.failedSamples), attribute.String("result", "failed"))
+				result.Observe(atomic.LoadInt64(&e.queue.droppedSamples), attribute.String("result", "dropped"))
+			}, metric.WithDescription("The total count of the samples pushed by remotewriteexporter"))
+		meter.NewInt64GaugeObserver(queueSizeMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				result.Observe(int64(e.queue.size()))
+			}, metric.WithDescription("The current number of the requests waiting to be sent by remotewriteexporter"))
+	})
+}
@@ -0,0 +1,140 @@
+package remotewriteexporter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const (
+	nameLabel     = "__name__"
+	bucketLabel   = "le"
+	quantileLabel = "quantile"
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+// timeSeries has one sample as the metrics are pushed every flush interval.
+type timeSeries struct {
+	labels []label
+	value  float64
+}
+
+// toTimeSeries converts a data group into series following the naming of the Prometheus exposition
+// format. The histograms are split into "_bucket", "_sum" and "_count", and so are the summaries.
+func toTimeSeries(dataGroup *model.DataGroup, externalLabels map[string]string) []timeSeries {
+	values := dataGroup.Labels.GetValues()
+	labels := make([]label, 0, len(values)+len(externalLabels)+2)
+	for k, v := range values {
+		labels = append(labels, label{name: sanitize(k), value: v.ToString()})
+	}
+	for k, v := range externalLabels {
+		if !dataGroup.Labels.HasAttribute(k) {
+			labels = append(labels, label{name: sanitize(k), value: v})
+		}
+	}
+
+	ret := make([]timeSeries, 0, len(dataGroup.Metrics))
+	add := func(name string, value float64, extra ...label) {
+		seriesLabels := make([]label, 0, len(labels)+len(extra)+1)
+		seriesLabels = append(seriesLabels, labels...)
+		seriesLabels = append(seriesLabels, extra...)
+		seriesLabels = append(seriesLabels, label{name: nameLabel, value: name})
+		sort.Slice(seriesLabels, func(i, j int) bool { return seriesLabels[i].name < seriesLabels[j].name })
+		ret = append(ret, timeSeries{labels: seriesLabels, value: value})
+	}
+	for _, metric := range dataGroup.Metrics {
+		name := sanitize(metric.Name)
+		switch metric.DataType() {
+		case model.IntMetricType:
+			add(name, float64(metric.GetInt().Value))
+		case model.HistogramMetricType:
+			histogram := metric.GetHistogram()
+			// The bucket counts are cumulative already.
+			for i, bound := range histogram.ExplicitBoundaries {
+				if i < len(histogram.BucketCounts) {
+					add(name+"_bucket", float64(histogram.BucketCounts[i]),
+						label{name: bucketLabel, value: strconv.FormatInt(bound, 10)})
+				}
+			}
+			add(name+"_bucket", float64(histogram.Count), label{name: bucketLabel, value: "+Inf"})
+			add(name+"_sum", float64(histogram.Sum))
+			add(name+"_count", float64(histogram.Count))
+		case model.SummaryMetricType:
+			summary := metric.GetSummary()
+			for _, q := range summary.GetQuantiles() {
+				add(name, summary.Sketch.Quantile(q),
+					label{name: quantileLabel, value: strconv.FormatFloat(q, 'f', -1, 64)})
+			}
+			add(name+"_sum", float64(summary.Sum))
+			add(name+"_count", float64(summary.Count))
+		}
+	}
+	return ret
+}
+
+// encodeWriteRequest encodes the series as the WriteRequest of the remote write protocol:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(series []timeSeries, timestampMs int64) []byte {
+	b := make([]byte, 0, len(series)*256)
+	var ts, sample []byte
+	for _, s := range series {
+		ts = ts[:0]
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+		sample = sample[:0]
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(timestampMs))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, ts)
+	}
+	return b
+}
+
+// sanitize replaces the characters not allowed in the names of Prometheus with "_".
+func sanitize(name string) string {
+	valid := true
+	for i := 0; i < len(name); i++ {
+		if !isValidChar(name[i], i) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return name
+	}
+	b := []byte(name)
+	for i := 0; i < len(b); i++ {
+		if !isValidChar(b[i], i) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func isValidChar(c byte, i int) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9' && i > 0)
+}
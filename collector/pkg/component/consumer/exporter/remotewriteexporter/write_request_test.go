@@ -0,0 +1,105 @@
+package remotewriteexporter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// decodedSeries is a TimeSeries decoded from a WriteRequest.
+type decodedSeries struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+func decodeWriteRequest(t *testing.T, b []byte) []decodedSeries {
+	var ret []decodedSeries
+	for len(b) > 0 {
+		_, _, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		ts, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatalf("invalid time series: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		series := decodedSeries{labels: make(map[string]string)}
+		for len(ts) > 0 {
+			num, _, n := protowire.ConsumeTag(ts)
+			ts = ts[n:]
+			msg, n := protowire.ConsumeBytes(ts)
+			ts = ts[n:]
+			fields := make(map[protowire.Number]interface{})
+			for len(msg) > 0 {
+				fieldNum, typ, n := protowire.ConsumeTag(msg)
+				msg = msg[n:]
+				switch typ {
+				case protowire.BytesType:
+					v, n := protowire.ConsumeString(msg)
+					fields[fieldNum], msg = v, msg[n:]
+				case protowire.Fixed64Type:
+					v, n := protowire.ConsumeFixed64(msg)
+					fields[fieldNum], msg = math.Float64frombits(v), msg[n:]
+				case protowire.VarintType:
+					v, n := protowire.ConsumeVarint(msg)
+					fields[fieldNum], msg = int64(v), msg[n:]
+				}
+			}
+			if num == 1 {
+				series.labels[fields[1].(string)] = fields[2].(string)
+			} else {
+				series.value, series.timestamp = fields[1].(float64), fields[2].(int64)
+			}
+		}
+		ret = append(ret, series)
+	}
+	return ret
+}
+
+func TestToTimeSeries(t *testing.T) {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.Protocol, "http")
+	labels.AddStringValue("http.method", "GET")
+	dataGroup := model.NewDataGroup("", labels, 0,
+		model.NewIntMetric("kindling_entity_request_total", 3),
+		model.NewHistogramMetric("kindling_entity_request_duration_nanoseconds", &model.Histogram{
+			Sum: 300, Count: 3, ExplicitBoundaries: []int64{10, 100}, BucketCounts: []uint64{1, 3},
+		}))
+	series := toTimeSeries(dataGroup, map[string]string{"cluster": "prod", constlabels.Protocol: "tcp"})
+	decoded := decodeWriteRequest(t, encodeWriteRequest(series, 1000))
+	if !assert.Len(t, decoded, 6) {
+		return
+	}
+	assert.Equal(t, map[string]string{
+		nameLabel:            "kindling_entity_request_total",
+		constlabels.Protocol: "http",
+		"http_method":        "GET",
+		"cluster":            "prod",
+	}, decoded[0].labels)
+	assert.Equal(t, 3.0, decoded[0].value)
+	assert.Equal(t, int64(1000), decoded[0].timestamp)
+
+	assert.Equal(t, "kindling_entity_request_duration_nanoseconds_bucket", decoded[1].labels[nameLabel])
+	assert.Equal(t, "10", decoded[1].labels[bucketLabel])
+	assert.Equal(t, 1.0, decoded[1].value)
+	assert.Equal(t, "+Inf", decoded[3].labels[bucketLabel])
+	assert.Equal(t, 3.0, decoded[3].value)
+	assert.Equal(t, "kindling_entity_request_duration_nanoseconds_sum", decoded[4].labels[nameLabel])
+	assert.Equal(t, 300.0, decoded[4].value)
+	assert.Equal(t, "kindling_entity_request_duration_nanoseconds_count", decoded[5].labels[nameLabel])
+}
+
+func TestQueue_DropOldest(t *testing.T) {
+	q := newQueue(2)
+	for i := 1; i <= 3; i++ {
+		q.push(&request{samples: i})
+	}
+	assert.Equal(t, int64(1), q.droppedSamples)
+	assert.Equal(t, 2, (<-q.ch).samples)
+	assert.Equal(t, 3, (<-q.ch).samples)
+}
@@ -19,6 +19,8 @@ type AdaptedResult struct {
 	AttrsMap  *model.AttributeMap
 	Metrics   []*model.Metric
 	Timestamp uint64
+	// Span describes the span of the Trace results.
+	Span *SpanInfo
 
 	// FreeAttrsMap provides an interface for those adapters which need to reuse AttrsList and AttrsMap
 	FreeAttrsMap
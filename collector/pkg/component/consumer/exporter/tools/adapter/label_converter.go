@@ -69,7 +69,7 @@ type extraLabelsParam struct {
 
 func updateProtocolKey(key *extraLabelsKey, labels *model.AttributeMap) *extraLabelsKey {
 	switch labels.GetStringValue(constlabels.Protocol) {
-	case constvalues.ProtocolHttp:
+	case constvalues.ProtocolHttp, constvalues.ProtocolHttp2:
 		key.protocol = HTTP
 	case constvalues.ProtocolGrpc:
 		key.protocol = GRPC
@@ -85,6 +85,14 @@ func updateProtocolKey(key *extraLabelsKey, labels *model.AttributeMap) *extraLa
 		key.protocol = REDIS
 	case constvalues.ProtocolRocketMQ:
 		key.protocol = ROCKETMQ
+	case constvalues.ProtocolPostgresql:
+		key.protocol = POSTGRESQL
+	case constvalues.ProtocolMongodb:
+		key.protocol = MONGODB
+	case constvalues.ProtocolMemcached:
+		key.protocol = MEMCACHED
+	case constvalues.ProtocolAmqp:
+		key.protocol = AMQP
 	default:
 		key.protocol = UNSUPPORTED
 	}
@@ -18,6 +18,10 @@ type NetAdapterConfig struct {
 	StoreTraceAsSpan   bool
 	StorePodDetail     bool
 	StoreExternalSrcIP bool
+	// PodDimensions are the keys of the pod labels and annotations attached by the
+	// k8sprocessor. They are exported as "label_<name>" in the entity metrics and as
+	// "src_label_<name>" and "dst_label_<name>" in the topology metrics and traces.
+	PodDimensions []string
 }
 
 func (n *NetMetricGroupAdapter) Adapt(dataGroup *model.DataGroup, attrType AttrType) ([]*AdaptedResult, error) {
@@ -45,7 +49,10 @@ func (n *NetMetricGroupAdapter) dealWithSingleMetricGroup(dataGroup *model.DataG
 			AttributeMap))
 	}
 	if n.StoreTraceAsSpan {
-		results = append(results, createTrace([]*model.Metric{requestTotalTime}, dataGroup, n.traceToSpanAdapter, attrType))
+		if trace := createTrace([]*model.Metric{requestTotalTime}, dataGroup, n.traceToSpanAdapter, attrType); trace != nil {
+			trace.Span = newSpanInfo(dataGroup, requestTotalTime.GetInt().Value)
+			results = append(results, trace)
+		}
 	}
 	return results
 }
@@ -157,69 +164,71 @@ type NetAdapterManager struct {
 	traceToMetricAdapter  *LabelConverter
 }
 
-func createNetAdapterManager(constLabels []attribute.KeyValue) *NetAdapterManager {
+func createNetAdapterManager(constLabels []attribute.KeyValue, podDimensions []string) *NetAdapterManager {
+	entityDimensions := entityPodDimensionDicList(podDimensions)
+	topologyDimensions := topologyPodDimensionDicList(podDimensions)
 	// TODO deal Error
 	aggEntityAdapterWithIsSlow, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{isSlowDicList}).
+		[][]dictionary{isSlowDicList, entityDimensions}).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	detailEntityAdapterWithIsSlow, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList, isSlowDicList}).
+		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList, isSlowDicList, entityDimensions}).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	aggTopologyAdapterWithIsSlow, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{isSlowDicList}).
+		[][]dictionary{isSlowDicList, topologyDimensions}).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(removeDstPodInfoForNonExternal()).
 		withConstLabels(constLabels).
 		build()
 
 	detailTopologyAdapterWithIsSlow, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, isSlowDicList}).
+		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, isSlowDicList, topologyDimensions}).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(replaceDstIpOrDstPortByDNat()).
 		withConstLabels(constLabels).
 		build()
 
 	aggEntityAdapter, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{}).
+		[][]dictionary{entityDimensions}).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	detailEntityAdapter, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList}).
+		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList, entityDimensions}).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	aggTopologyAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{}).
+		[][]dictionary{topologyDimensions}).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(removeDstPodInfoForNonExternal()).
 		withConstLabels(constLabels).
 		build()
 
 	detailTopologyAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList}).
+		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, topologyDimensions}).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(replaceDstIpOrDstPortByDNat()).
 		withConstLabels(constLabels).
 		build()
 
 	traceToSpanAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, SpanDicList, dNatDicList}).
+		[][]dictionary{topologyInstanceMetricDicList, SpanDicList, dNatDicList, topologyDimensions}).
 		withExtraLabels(spanProtocol, updateProtocolKey).
 		withValueToLabels(traceSpanStatus, getTraceSpanStatusLabels).
 		withConstLabels(constLabels).
 		build()
 
 	traceToMetricAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, dNatDicList}).
+		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, dNatDicList, topologyDimensions}).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withValueToLabels(traceStatus, getTraceStatusLabels).
 		withConstLabels(constLabels).
@@ -240,7 +249,7 @@ func NewNetAdapter(
 	config *NetAdapterConfig,
 ) *NetMetricGroupAdapter {
 	return &NetMetricGroupAdapter{
-		NetAdapterManager: createNetAdapterManager(customLabels),
+		NetAdapterManager: createNetAdapterManager(customLabels, config.PodDimensions),
 		NetAdapterConfig:  config,
 	}
 }
@@ -20,6 +20,10 @@ const (
 	DUBBO
 	REDIS
 	ROCKETMQ
+	POSTGRESQL
+	MONGODB
+	MEMCACHED
+	AMQP
 	UNSUPPORTED
 )
 
@@ -68,6 +72,9 @@ var entityDetailMetricDicList = []dictionary{
 	{constlabels.Pod, constlabels.DstPod, String},
 	{constlabels.Container, constlabels.DstContainer, String},
 	{constlabels.ContainerId, constlabels.DstContainerId, String},
+	{constlabels.ContainerImage, constlabels.DstContainerImage, String},
+	{constlabels.ProcessSystemdUnit, constlabels.ProcessSystemdUnit, String},
+	{constlabels.ProcessUser, constlabels.ProcessUser, String},
 }
 
 var entityMetricDicList = []dictionary{
@@ -88,6 +95,10 @@ var topologyDetailMetricDicList = []dictionary{
 	{constlabels.SrcContainer, constlabels.SrcContainer, String},
 	{constlabels.DstContainerId, constlabels.DstContainerId, String},
 	{constlabels.DstContainer, constlabels.DstContainer, String},
+	{constlabels.SrcContainerImage, constlabels.SrcContainerImage, String},
+	{constlabels.DstContainerImage, constlabels.DstContainerImage, String},
+	{constlabels.ProcessSystemdUnit, constlabels.ProcessSystemdUnit, String},
+	{constlabels.ProcessUser, constlabels.ProcessUser, String},
 	// this info has contained in topology baseInfos
 	//{constlabels.DstNode, constlabels.DstNode, String},
 	//{constlabels.DstPod, constlabels.DstPod, String},
@@ -109,6 +120,8 @@ var SpanDicList = []dictionary{
 	{constlabels.RequestTid, constlabels.RequestTid, Int64},
 	{constlabels.ResponseTid, constlabels.ResponseTid, Int64},
 	{constlabels.Comm, constlabels.Comm, String},
+	{constlabels.ProcessSystemdUnit, constlabels.ProcessSystemdUnit, String},
+	{constlabels.ProcessUser, constlabels.ProcessUser, String},
 	{constlabels.EndTimestamp, constlabels.EndTimestamp, Int64},
 }
 
@@ -129,6 +142,46 @@ var topologyMetricDicList = []dictionary{
 	{constlabels.Protocol, constlabels.Protocol, String},
 }
 
+// entityPodDimensionDicList returns the dictionaries of the pod dimensions of the server.
+func entityPodDimensionDicList(keys []string) []dictionary {
+	names := podDimensionNames(keys)
+	dicList := make([]dictionary, 0, len(names))
+	for _, name := range names {
+		dicList = append(dicList, dictionary{constlabels.LabelPrefix + name, constlabels.DstLabelPrefix + name, String})
+	}
+	return dicList
+}
+
+// topologyPodDimensionDicList returns the dictionaries of the pod dimensions of both sides.
+func topologyPodDimensionDicList(keys []string) []dictionary {
+	names := podDimensionNames(keys)
+	dicList := make([]dictionary, 0, 2*len(names))
+	for _, name := range names {
+		dicList = append(dicList,
+			dictionary{constlabels.SrcLabelPrefix + name, constlabels.SrcLabelPrefix + name, String},
+			dictionary{constlabels.DstLabelPrefix + name, constlabels.DstLabelPrefix + name, String})
+	}
+	return dicList
+}
+
+// podDimensionNames converts the keys into names and removes the duplicated ones.
+func podDimensionNames(keys []string) []string {
+	names := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		name := constlabels.PodDimensionName(key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
 func removeDstPodInfoForNonExternal() adjustFunctions {
 	return adjustFunctions{
 		adjustAttrMaps: func(labels *model.AttributeMap, attributeMap *model.AttributeMap) *model.AttributeMap {
@@ -202,7 +255,7 @@ var entityProtocol = []extraLabelsParam{
 	}, extraLabelsKey{MYSQL}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.ContentKey, String},
-		{constlabels.ResponseContent, constlabels.HttpStatusCode, FromInt64ToString},
+		{constlabels.ResponseContent, constlabels.GrpcStatus, FromInt64ToString},
 	}, extraLabelsKey{GRPC}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.DnsDomain, String},
@@ -221,8 +274,25 @@ var entityProtocol = []extraLabelsParam{
 		{constlabels.ResponseContent, constlabels.RocketMQErrCode, FromInt64ToString},
 	}, extraLabelsKey{ROCKETMQ}},
 	{[]dictionary{
-		{constlabels.RequestContent, constlabels.STR_EMPTY, StrEmpty},
-		{constlabels.ResponseContent, constlabels.STR_EMPTY, StrEmpty},
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.SqlState, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.MongodbErrCode, FromInt64ToString},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.STR_EMPTY, FromProtoclErrorToString},
+	}, extraLabelsKey{MEMCACHED}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.AmqpReplyCode, FromInt64ToString},
+	}, extraLabelsKey{AMQP}},
+	{[]dictionary{
+		// The custom protocols declared in the configuration are reported as UNSUPPORTED.
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.CustomStatus, String},
 	}, extraLabelsKey{UNSUPPORTED}},
 }
 
@@ -238,6 +308,15 @@ var spanProtocol = []extraLabelsParam{
 		{constlabels.SpanHttpResponseHeaders, constlabels.ResponsePayload, String},
 		{constlabels.SpanHttpResponseBody, constlabels.STR_EMPTY, StrEmpty},
 	}, extraLabelsKey{HTTP}},
+	{[]dictionary{
+		{constlabels.SpanGrpcPath, constlabels.HttpUrl, String},
+		{constlabels.SpanGrpcStatusCode, constlabels.GrpcStatus, Int64},
+		{constlabels.SpanHttpStatusCode, constlabels.HttpStatusCode, Int64},
+		{constlabels.SpanHttpTraceId, constlabels.HttpApmTraceId, String},
+		{constlabels.SpanHttpTraceType, constlabels.HttpApmTraceType, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{GRPC}},
 	{[]dictionary{
 		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
@@ -272,6 +351,40 @@ var spanProtocol = []extraLabelsParam{
 		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
 	}, extraLabelsKey{ROCKETMQ}},
+	{[]dictionary{
+		{constlabels.SpanPostgresqlSql, constlabels.Sql, String},
+		{constlabels.SpanPostgresqlSqlState, constlabels.SqlState, String},
+		{constlabels.SpanPostgresqlErrorMsg, constlabels.SqlErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.SpanMongodbCommand, constlabels.MongodbCommand, String},
+		{constlabels.SpanMongodbDatabase, constlabels.MongodbDatabase, String},
+		{constlabels.SpanMongodbCollection, constlabels.MongodbCollection, String},
+		{constlabels.SpanMongodbErrorCode, constlabels.MongodbErrCode, Int64},
+		{constlabels.SpanMongodbErrorMsg, constlabels.MongodbErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.SpanMemcachedCommand, constlabels.MemcachedCommand, String},
+		{constlabels.SpanMemcachedHits, constlabels.MemcachedHits, Int64},
+		{constlabels.SpanMemcachedMisses, constlabels.MemcachedMisses, Int64},
+		{constlabels.SpanMemcachedErrorMsg, constlabels.MemcachedErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{MEMCACHED}},
+	{[]dictionary{
+		{constlabels.SpanAmqpMethod, constlabels.AmqpMethod, String},
+		{constlabels.SpanAmqpExchange, constlabels.AmqpExchange, String},
+		{constlabels.SpanAmqpRoutingKey, constlabels.AmqpRoutingKey, String},
+		{constlabels.SpanAmqpQueue, constlabels.AmqpQueue, String},
+		{constlabels.SpanAmqpReplyCode, constlabels.AmqpReplyCode, Int64},
+		{constlabels.SpanAmqpErrorMsg, constlabels.AmqpErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{AMQP}},
 	{[]dictionary{
 		/*
 		 * Currently we add payload span for all protocols everywhere as http\dubbo\redis has it's own key.
@@ -295,7 +408,7 @@ var topologyProtocol = []extraLabelsParam{
 		{constlabels.StatusCode, constlabels.SqlErrCode, FromInt64ToString},
 	}, extraLabelsKey{MYSQL}},
 	{[]dictionary{
-		{constlabels.StatusCode, constlabels.HttpStatusCode, FromInt64ToString},
+		{constlabels.StatusCode, constlabels.GrpcStatus, FromInt64ToString},
 	}, extraLabelsKey{GRPC}},
 	{[]dictionary{
 		{constlabels.StatusCode, constlabels.DnsRcode, FromInt64ToString},
@@ -310,7 +423,19 @@ var topologyProtocol = []extraLabelsParam{
 		{constlabels.StatusCode, constlabels.RocketMQErrCode, FromInt64ToString},
 	}, extraLabelsKey{ROCKETMQ}},
 	{[]dictionary{
-		{constlabels.StatusCode, constlabels.STR_EMPTY, StrEmpty},
+		{constlabels.StatusCode, constlabels.SqlState, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.MongodbErrCode, FromInt64ToString},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.STR_EMPTY, FromProtocolErrorToStatus},
+	}, extraLabelsKey{MEMCACHED}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.AmqpReplyCode, FromInt64ToString},
+	}, extraLabelsKey{AMQP}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.CustomStatus, String},
 	}, extraLabelsKey{UNSUPPORTED}},
 }
 
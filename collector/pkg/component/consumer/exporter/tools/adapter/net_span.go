@@ -0,0 +1,181 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// SpanInfo describes how a single request is exported as an OpenTelemetry span.
+type SpanInfo struct {
+	Name      string
+	Kind      trace.SpanKind
+	StartTime time.Time
+	EndTime   time.Time
+	// IsError is true if the request failed, and StatusMessage describes the error.
+	IsError       bool
+	StatusMessage string
+	// TraceId and ParentSpanId are parsed from the trace headers of the request. They are
+	// invalid if there are no headers or the ids are not compatible with OpenTelemetry.
+	TraceId      trace.TraceID
+	ParentSpanId trace.SpanID
+	// Attributes follow the semantic conventions of OpenTelemetry, which are exported besides
+	// the labels of the span.
+	Attributes []attribute.KeyValue
+}
+
+// errorMessageLabels are the labels describing the errors of the protocols.
+var errorMessageLabels = []string{
+	constlabels.SqlErrMsg,
+	constlabels.RedisErrMsg,
+	constlabels.MongodbErrMsg,
+	constlabels.MemcachedErrMsg,
+	constlabels.RocketMQErrMsg,
+	constlabels.AmqpErrMsg,
+}
+
+func newSpanInfo(dataGroup *model.DataGroup, duration int64) *SpanInfo {
+	labels := dataGroup.Labels
+	isServer := labels.GetBoolValue(constlabels.IsServer)
+	span := &SpanInfo{
+		Kind:         trace.SpanKindClient,
+		StartTime:    time.Unix(0, int64(dataGroup.Timestamp)),
+		EndTime:      time.Unix(0, int64(dataGroup.Timestamp)+duration),
+		IsError:      labels.GetBoolValue(constlabels.IsError),
+		TraceId:      parseTraceId(labels.GetStringValue(constlabels.HttpApmTraceId)),
+		ParentSpanId: parseSpanId(labels.GetStringValue(constlabels.HttpApmParentSpanId)),
+		Attributes:   make([]attribute.KeyValue, 0, 8),
+	}
+	if isServer {
+		span.Kind = trace.SpanKindServer
+	}
+
+	// The host is where the agent observes the request, which is the server for the server-side spans.
+	hostIp, hostPort, peerIp, peerPort := constlabels.SrcIp, constlabels.SrcPort, constlabels.DstIp, constlabels.DstPort
+	if isServer {
+		hostIp, hostPort, peerIp, peerPort = peerIp, peerPort, hostIp, hostPort
+	}
+	span.addString(semconv.NetHostIPKey, labels.GetStringValue(hostIp))
+	span.addInt(semconv.NetHostPortKey, labels.GetIntValue(hostPort))
+	span.addString(semconv.NetPeerIPKey, labels.GetStringValue(peerIp))
+	span.addInt(semconv.NetPeerPortKey, labels.GetIntValue(peerPort))
+
+	protocol := labels.GetStringValue(constlabels.Protocol)
+	contentKey := labels.GetStringValue(constlabels.ContentKey)
+	span.Name = contentKey
+	// The attributes already exported as labels of the span, like http.method, are not added again.
+	switch protocol {
+	case constvalues.ProtocolHttp, constvalues.ProtocolHttp2:
+		span.addString(semconv.HTTPTargetKey, labels.GetStringValue(constlabels.HttpUrl))
+		if protocol == constvalues.ProtocolHttp2 {
+			span.Attributes = append(span.Attributes, semconv.HTTPFlavorHTTP20)
+		} else {
+			span.Attributes = append(span.Attributes, semconv.HTTPFlavorHTTP11)
+		}
+		span.Name = labels.GetStringValue(constlabels.HttpMethod) + " " + contentKey
+		if span.IsError {
+			span.StatusMessage = fmt.Sprintf("HTTP status code %d", labels.GetIntValue(constlabels.HttpStatusCode))
+		}
+	case constvalues.ProtocolGrpc:
+		// The path is "/{service}/{method}".
+		path := strings.TrimPrefix(labels.GetStringValue(constlabels.HttpUrl), "/")
+		span.Attributes = append(span.Attributes, semconv.RPCSystemKey.String("grpc"))
+		if pos := strings.LastIndex(path, "/"); pos > 0 {
+			span.addString(semconv.RPCServiceKey, path[:pos])
+			span.addString(semconv.RPCMethodKey, path[pos+1:])
+		}
+		span.Attributes = append(span.Attributes, semconv.RPCGRPCStatusCodeKey.Int64(labels.GetIntValue(constlabels.GrpcStatus)))
+		span.Name = path
+		if span.IsError {
+			span.StatusMessage = fmt.Sprintf("gRPC status code %d", labels.GetIntValue(constlabels.GrpcStatus))
+		}
+	case constvalues.ProtocolDubbo:
+		span.Attributes = append(span.Attributes, semconv.RPCSystemKey.String("dubbo"))
+	case constvalues.ProtocolMysql:
+		span.Attributes = append(span.Attributes, semconv.DBSystemMySQL)
+		span.addString(semconv.DBStatementKey, labels.GetStringValue(constlabels.Sql))
+	case constvalues.ProtocolPostgresql:
+		span.Attributes = append(span.Attributes, semconv.DBSystemPostgreSQL)
+		span.addString(semconv.DBStatementKey, labels.GetStringValue(constlabels.Sql))
+	case constvalues.ProtocolRedis:
+		span.Attributes = append(span.Attributes, semconv.DBSystemRedis)
+		span.addString(semconv.DBOperationKey, labels.GetStringValue(constlabels.RedisCommand))
+	case constvalues.ProtocolMongodb:
+		span.Attributes = append(span.Attributes, semconv.DBSystemMongoDB)
+		span.addString(semconv.DBOperationKey, labels.GetStringValue(constlabels.MongodbCommand))
+		span.addString(semconv.DBNameKey, labels.GetStringValue(constlabels.MongodbDatabase))
+		span.addString(semconv.DBMongoDBCollectionKey, labels.GetStringValue(constlabels.MongodbCollection))
+	case constvalues.ProtocolMemcached:
+		span.Attributes = append(span.Attributes, semconv.DBSystemMemcached)
+		span.addString(semconv.DBOperationKey, labels.GetStringValue(constlabels.MemcachedCommand))
+	case constvalues.ProtocolKafka:
+		span.Attributes = append(span.Attributes, semconv.MessagingSystemKey.String("kafka"))
+		if topic := labels.GetStringValue(constlabels.KafkaTopic); topic != "" {
+			span.Attributes = append(span.Attributes, semconv.MessagingDestinationKey.String(topic),
+				semconv.MessagingDestinationKindTopic)
+		}
+	case constvalues.ProtocolRocketMQ:
+		span.Attributes = append(span.Attributes, semconv.MessagingSystemKey.String("rocketmq"))
+	case constvalues.ProtocolAmqp:
+		span.Attributes = append(span.Attributes, semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingProtocolKey.String("AMQP"))
+		span.addString(semconv.MessagingDestinationKey, labels.GetStringValue(constlabels.AmqpExchange))
+		span.addString(semconv.MessagingRabbitmqRoutingKeyKey, labels.GetStringValue(constlabels.AmqpRoutingKey))
+	}
+	if strings.TrimSpace(span.Name) == "" {
+		span.Name = protocol
+	}
+	if span.IsError && span.StatusMessage == "" {
+		for _, key := range errorMessageLabels {
+			if msg := labels.GetStringValue(key); msg != "" {
+				span.StatusMessage = msg
+				break
+			}
+		}
+	}
+	return span
+}
+
+func (s *SpanInfo) addString(key attribute.Key, value string) {
+	if value != "" {
+		s.Attributes = append(s.Attributes, key.String(value))
+	}
+}
+
+func (s *SpanInfo) addInt(key attribute.Key, value int64) {
+	if value != 0 {
+		s.Attributes = append(s.Attributes, key.Int64(value))
+	}
+}
+
+// parseTraceId returns the trace id in hex, which is padded with zeros if it is 64-bit like
+// the ids of Zipkin. The ids not in hex, like the ids of SkyWalking, are invalid.
+func parseTraceId(id string) trace.TraceID {
+	if id == "" || len(id) > 32 {
+		return trace.TraceID{}
+	}
+	traceId, err := trace.TraceIDFromHex(strings.Repeat("0", 32-len(id)) + strings.ToLower(id))
+	if err != nil {
+		return trace.TraceID{}
+	}
+	return traceId
+}
+
+func parseSpanId(id string) trace.SpanID {
+	if id == "" || len(id) > 16 {
+		return trace.SpanID{}
+	}
+	spanId, err := trace.SpanIDFromHex(strings.Repeat("0", 16-len(id)) + strings.ToLower(id))
+	if err != nil {
+		return trace.SpanID{}
+	}
+	return spanId
+}
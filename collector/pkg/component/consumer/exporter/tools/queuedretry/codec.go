@@ -0,0 +1,104 @@
+package queuedretry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// persistedDataGroup is how a data group is stored on the disk. The labels are split by types
+// because model.AttributeMap doesn't keep the types in JSON.
+type persistedDataGroup struct {
+	Name         string            `json:"name"`
+	Timestamp    uint64            `json:"timestamp"`
+	StringLabels map[string]string `json:"string_labels,omitempty"`
+	IntLabels    map[string]int64  `json:"int_labels,omitempty"`
+	BoolLabels   map[string]bool   `json:"bool_labels,omitempty"`
+	Metrics      []persistedMetric `json:"metrics,omitempty"`
+}
+
+type persistedMetric struct {
+	Name      string           `json:"name"`
+	Int       *int64           `json:"int,omitempty"`
+	Histogram *model.Histogram `json:"histogram,omitempty"`
+	// Summary keeps the sum, the count, the quantiles and the sketch of the summary.
+	Summary *model.Summary `json:"summary,omitempty"`
+}
+
+// encode marshals the data group.
+func encode(dataGroup *model.DataGroup) ([]byte, error) {
+	p := persistedDataGroup{
+		Name:      dataGroup.Name,
+		Timestamp: dataGroup.Timestamp,
+		Metrics:   make([]persistedMetric, 0, len(dataGroup.Metrics)),
+	}
+	if dataGroup.Labels != nil {
+		for k, v := range dataGroup.Labels.GetValues() {
+			switch v.Type() {
+			case model.StringAttributeValueType:
+				if p.StringLabels == nil {
+					p.StringLabels = make(map[string]string)
+				}
+				p.StringLabels[k] = v.ToString()
+			case model.IntAttributeValueType:
+				if p.IntLabels == nil {
+					p.IntLabels = make(map[string]int64)
+				}
+				p.IntLabels[k] = dataGroup.Labels.GetIntValue(k)
+			case model.BooleanAttributeValueType:
+				if p.BoolLabels == nil {
+					p.BoolLabels = make(map[string]bool)
+				}
+				p.BoolLabels[k] = dataGroup.Labels.GetBoolValue(k)
+			}
+		}
+	}
+	for _, metric := range dataGroup.Metrics {
+		m := persistedMetric{Name: metric.Name}
+		switch metric.DataType() {
+		case model.IntMetricType:
+			value := metric.GetInt().Value
+			m.Int = &value
+		case model.HistogramMetricType:
+			m.Histogram = metric.GetHistogram()
+		case model.SummaryMetricType:
+			m.Summary = metric.GetSummary()
+		default:
+			return nil, fmt.Errorf("the metric %s of type %d can't be persisted", metric.Name, metric.DataType())
+		}
+		p.Metrics = append(p.Metrics, m)
+	}
+	return json.Marshal(&p)
+}
+
+func decode(data []byte) (*model.DataGroup, error) {
+	var p persistedDataGroup
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	labels := model.NewAttributeMap()
+	for k, v := range p.StringLabels {
+		labels.AddStringValue(k, v)
+	}
+	for k, v := range p.IntLabels {
+		labels.AddIntValue(k, v)
+	}
+	for k, v := range p.BoolLabels {
+		labels.AddBoolValue(k, v)
+	}
+	metrics := make([]*model.Metric, 0, len(p.Metrics))
+	for _, m := range p.Metrics {
+		switch {
+		case m.Int != nil:
+			metrics = append(metrics, model.NewIntMetric(m.Name, *m.Int))
+		case m.Histogram != nil:
+			metrics = append(metrics, model.NewHistogramMetric(m.Name, m.Histogram))
+		case m.Summary != nil:
+			metrics = append(metrics, model.NewSummaryMetric(m.Name, m.Summary))
+		default:
+			return nil, fmt.Errorf("the metric %s has no value", m.Name)
+		}
+	}
+	return model.NewDataGroup(p.Name, labels, p.Timestamp, metrics...), nil
+}
@@ -0,0 +1,63 @@
+package queuedretry
+
+import (
+	"errors"
+	"time"
+)
+
+// Config is the "retry_queue" section of an exporter, e.g. "exporters.kafkaexporter.retry_queue".
+type Config struct {
+	// Enabled wraps the exporter with a persistent retry queue.
+	Enabled bool `mapstructure:"enabled"`
+	// Directory is where the data groups are persisted. Each exporter uses a sub-directory named
+	// after itself. Mount a volume of the host here to keep the data when the pod is re-created.
+	Directory string `mapstructure:"directory"`
+	// MaxQueueSize is the max number of the data groups persisted. The oldest ones are dropped if
+	// the queue is full.
+	MaxQueueSize int `mapstructure:"max_queue_size"`
+	// The data groups are buffered in memory and written to the disk in batches, which are synced
+	// every FlushInterval or once MaxBatchSize data groups are buffered.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	MaxBatchSize  int           `mapstructure:"max_batch_size"`
+	// MaxAge is how long a data group is kept in the queue before it is dropped.
+	MaxAge time.Duration `mapstructure:"max_age"`
+	// InitialInterval is the backoff after the first failed replay, which is doubled for each
+	// failure until MaxInterval.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	MaxInterval     time.Duration `mapstructure:"max_interval"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enabled:         false,
+		Directory:       "/tmp/kindling/retry_queue",
+		MaxQueueSize:    10000,
+		FlushInterval:   time.Second,
+		MaxBatchSize:    500,
+		MaxAge:          24 * time.Hour,
+		InitialInterval: time.Second,
+		MaxInterval:     time.Minute,
+	}
+}
+
+func (cfg *Config) validate() error {
+	if cfg.Directory == "" {
+		return errors.New("directory is empty")
+	}
+	if cfg.MaxQueueSize <= 0 {
+		return errors.New("max_queue_size must be positive")
+	}
+	if cfg.FlushInterval <= 0 {
+		return errors.New("flush_interval must be positive")
+	}
+	if cfg.MaxBatchSize <= 0 {
+		return errors.New("max_batch_size must be positive")
+	}
+	if cfg.InitialInterval <= 0 {
+		return errors.New("initial_interval must be positive")
+	}
+	if cfg.MaxInterval < cfg.InitialInterval {
+		cfg.MaxInterval = cfg.InitialInterval
+	}
+	return nil
+}
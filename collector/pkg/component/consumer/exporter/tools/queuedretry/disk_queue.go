@@ -0,0 +1,233 @@
+package queuedretry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileSuffix    = ".json"
+	tmpFileSuffix = ".tmp"
+)
+
+// diskQueue persists each batch of entries as a file in the directory, one entry per line. The
+// files are named after a sequence which is the time in nanoseconds when the batch is pushed, so
+// the entries are replayed in order after restarts and their ages are known.
+type diskQueue struct {
+	dir     string
+	maxSize int
+
+	mutex   sync.Mutex
+	batches []batchFile
+	// entries is the total count of the entries in the batches.
+	entries int
+	lastSeq int64
+}
+
+type batchFile struct {
+	seq   int64
+	count int
+}
+
+// openDiskQueue loads the batches left in the directory. The oldest batches are removed if
+// there are more than maxSize entries, and the number of the entries removed is returned.
+func openDiskQueue(dir string, maxSize int) (*diskQueue, int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, 0, err
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	q := &diskQueue{dir: dir, maxSize: maxSize}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() {
+			continue
+		}
+		// The temporary files are left if the collector exits while writing them.
+		if strings.HasSuffix(name, tmpFileSuffix) {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseInt(strings.TrimSuffix(name, fileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		// The unreadable batches are counted as one entry, which is dropped as corrupted when replayed.
+		count := 1
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			if lines := len(splitLines(data)); lines > 0 {
+				count = lines
+			}
+		}
+		q.batches = append(q.batches, batchFile{seq: seq, count: count})
+		q.entries += count
+	}
+	sort.Slice(q.batches, func(i, j int) bool { return q.batches[i].seq < q.batches[j].seq })
+	if len(q.batches) > 0 {
+		q.lastSeq = q.batches[len(q.batches)-1].seq
+	}
+	return q, q.trim(), nil
+}
+
+// push writes the entries to a temporary file, syncs it once and renames it, so a partial batch
+// is never seen. The oldest batches are removed if the queue is full, and the number of the
+// entries removed is returned.
+func (q *diskQueue) push(entries [][]byte) (int, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	seq := time.Now().UnixNano()
+	if seq <= q.lastSeq {
+		seq = q.lastSeq + 1
+	}
+	if err := q.writeBatch(seq, entries); err != nil {
+		return 0, err
+	}
+	q.lastSeq = seq
+	q.batches = append(q.batches, batchFile{seq: seq, count: len(entries)})
+	q.entries += len(entries)
+	return q.trim(), nil
+}
+
+// writeBatch replaces the file of the batch atomically.
+func (q *diskQueue) writeBatch(seq int64, entries [][]byte) error {
+	path := q.path(seq)
+	if err := writeFile(path+tmpFileSuffix, bytes.Join(entries, []byte{'\n'})); err != nil {
+		_ = os.Remove(path + tmpFileSuffix)
+		return err
+	}
+	if err := os.Rename(path+tmpFileSuffix, path); err != nil {
+		_ = os.Remove(path + tmpFileSuffix)
+		return err
+	}
+	return nil
+}
+
+func writeFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// trim removes the oldest batches while the queue is still full without them. A queue could hold
+// less than a batch more than maxSize entries. It must be called with the lock held.
+func (q *diskQueue) trim() int {
+	dropped := 0
+	for len(q.batches) > 0 && q.entries > q.maxSize {
+		oldest := q.batches[0]
+		_ = os.Remove(q.path(oldest.seq))
+		q.batches = q.batches[1:]
+		q.entries -= oldest.count
+		dropped += oldest.count
+	}
+	return dropped
+}
+
+// peek returns the entries of the oldest batch. ok is false if the queue is empty, and err is not
+// nil if the batch can't be read.
+func (q *diskQueue) peek() (seq int64, entries [][]byte, ok bool, err error) {
+	q.mutex.Lock()
+	if len(q.batches) == 0 {
+		q.mutex.Unlock()
+		return 0, nil, false, nil
+	}
+	seq = q.batches[0].seq
+	q.mutex.Unlock()
+	data, err := os.ReadFile(q.path(seq))
+	if err != nil {
+		return seq, nil, true, err
+	}
+	return seq, splitLines(data), true, nil
+}
+
+// remove deletes the batch and returns the number of its entries. It returns 0 if the batch has
+// been removed because the queue was full.
+func (q *diskQueue) remove(seq int64) int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for i, batch := range q.batches {
+		if batch.seq == seq {
+			q.batches = append(q.batches[:i], q.batches[i+1:]...)
+			q.entries -= batch.count
+			_ = os.Remove(q.path(seq))
+			return batch.count
+		}
+	}
+	return 0
+}
+
+// commit removes the first consumed entries of the batch returned by peek, and the batch itself if
+// all its entries are consumed. It does nothing if the batch has been removed because the queue
+// was full.
+func (q *diskQueue) commit(seq int64, entries [][]byte, consumed int) error {
+	if consumed >= len(entries) {
+		q.remove(seq)
+		return nil
+	}
+	if consumed == 0 {
+		return nil
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for i, batch := range q.batches {
+		if batch.seq != seq {
+			continue
+		}
+		if err := q.writeBatch(seq, entries[consumed:]); err != nil {
+			return err
+		}
+		q.batches[i].count = len(entries) - consumed
+		q.entries -= consumed
+		return nil
+	}
+	return nil
+}
+
+// size returns the number of the entries.
+func (q *diskQueue) size() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.entries
+}
+
+func (q *diskQueue) path(seq int64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", seq, fileSuffix))
+}
+
+func splitLines(data []byte) [][]byte {
+	lines := bytes.Split(data, []byte{'\n'})
+	ret := lines[:0]
+	for _, line := range lines {
+		if len(line) > 0 {
+			ret = append(ret, line)
+		}
+	}
+	return ret
+}
+
+// seqTime returns the time when the batch is pushed.
+func seqTime(seq int64) time.Time {
+	return time.Unix(0, seq)
+}
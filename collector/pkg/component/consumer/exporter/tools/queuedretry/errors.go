@@ -0,0 +1,37 @@
+package queuedretry
+
+import "errors"
+
+// permanentError is the error of the data that will never be accepted by the backend, so they
+// are not retried.
+type permanentError struct {
+	err error
+}
+
+// NewPermanentError marks an error returned by Consume as not retryable, like the errors of
+// encoding the data. The other errors are retried.
+func NewPermanentError(err error) error {
+	return permanentError{err: err}
+}
+
+func (e permanentError) Error() string {
+	return "permanent error: " + e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// IsPermanent returns true if the error or any error it wraps is a permanent error.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// UnsupportedExporter is implemented by the exporters whose Consume can't tell whether the data are
+// accepted by the backend, e.g. because they export asynchronously in batches. The retry queue can't
+// be enabled for them.
+type UnsupportedExporter interface {
+	// RetryQueueUnsupported returns the reason why the retry queue is not supported.
+	RetryQueueUnsupported() string
+}
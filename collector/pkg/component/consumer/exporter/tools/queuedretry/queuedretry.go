@@ -0,0 +1,287 @@
+package queuedretry
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// QueuedRetryExporter wraps an exporter whose backend may be unavailable. The data groups that
+// fail to be exported are persisted to a bounded queue on the disk, and they are replayed in
+// order when the backend is back, even if the collector restarts during the outage.
+//
+// The data groups are persisted instead of being exported directly while the queue is not
+// empty, so the exporter is not called for each data group during the outage. They are buffered
+// in memory and written to the disk in batches by another goroutine, so Consume never waits for
+// the disk.
+type QueuedRetryExporter struct {
+	name      string
+	cfg       *Config
+	next      exporter.Exporter
+	queue     *diskQueue
+	telemetry *component.TelemetryTools
+
+	// pending are the encoded data groups not written to the disk yet, and writing is the number of
+	// the ones being written. They are queued before the new data groups.
+	pending      [][]byte
+	writing      int
+	pendingMutex sync.Mutex
+
+	enqueued int64
+	replayed int64
+	// The counts of the data groups dropped for each reason.
+	droppedFull        int64
+	droppedExpired     int64
+	droppedRejected    int64
+	droppedUnsupported int64
+	droppedCorrupted   int64
+	droppedWriteFailed int64
+
+	flushCh  chan struct{}
+	wakeCh   chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New wraps the exporter named name. The data groups are persisted in the sub-directory
+// named after the exporter, and those left by the last run are replayed.
+func New(name string, cfg *Config, next exporter.Exporter, telemetry *component.TelemetryTools) (*QueuedRetryExporter, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	queue, dropped, err := openDiskQueue(filepath.Join(cfg.Directory, name), cfg.MaxQueueSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the retry queue of %s: %w", name, err)
+	}
+	e := &QueuedRetryExporter{
+		name:        name,
+		cfg:         cfg,
+		next:        next,
+		queue:       queue,
+		telemetry:   telemetry,
+		droppedFull: int64(dropped),
+		flushCh:     make(chan struct{}, 1),
+		wakeCh:      make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+	}
+	if size := queue.size(); size > 0 {
+		telemetry.Logger.Infof("%d data groups of %s are left in the retry queue and will be replayed", size, name)
+		e.wake()
+	}
+	newSelfMetrics(telemetry.MeterProvider, e)
+	e.wg.Add(2)
+	go e.flushLoop()
+	go e.replayLoop()
+	return e, nil
+}
+
+func (e *QueuedRetryExporter) Consume(dataGroup *model.DataGroup) error {
+	if dataGroup == nil {
+		return nil
+	}
+	if e.size() == 0 {
+		err := e.next.Consume(dataGroup)
+		if err == nil {
+			return nil
+		}
+		if IsPermanent(err) {
+			atomic.AddInt64(&e.droppedRejected, 1)
+			return err
+		}
+		e.telemetry.Logger.Debug("Failed to export the data group, which will be retried",
+			zap.String("exporter", e.name), zap.Error(err))
+	}
+	return e.persist(dataGroup)
+}
+
+// persist buffers the data group until the next flush. The oldest ones are dropped if the buffer
+// is full while the disk is slow.
+func (e *QueuedRetryExporter) persist(dataGroup *model.DataGroup) error {
+	data, err := encode(dataGroup)
+	if err != nil {
+		atomic.AddInt64(&e.droppedUnsupported, 1)
+		return NewPermanentError(err)
+	}
+	e.pendingMutex.Lock()
+	e.pending = append(e.pending, data)
+	if len(e.pending) > e.cfg.MaxQueueSize {
+		e.pending = e.pending[1:]
+		atomic.AddInt64(&e.droppedFull, 1)
+	}
+	full := len(e.pending) >= e.cfg.MaxBatchSize
+	e.pendingMutex.Unlock()
+	atomic.AddInt64(&e.enqueued, 1)
+	if full {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// size returns the number of the data groups buffered and persisted.
+func (e *QueuedRetryExporter) size() int {
+	e.pendingMutex.Lock()
+	buffered := len(e.pending) + e.writing
+	e.pendingMutex.Unlock()
+	return buffered + e.queue.size()
+}
+
+// flushLoop writes the data groups buffered to the disk every flush interval or once a batch is
+// full. The data groups left are written before it exits.
+func (e *QueuedRetryExporter) flushLoop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.flush()
+		case <-e.flushCh:
+			e.flush()
+		case <-e.stopCh:
+			e.flush()
+			return
+		}
+	}
+}
+
+func (e *QueuedRetryExporter) flush() {
+	e.pendingMutex.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.writing = len(batch)
+	e.pendingMutex.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	dropped, err := e.queue.push(batch)
+	e.pendingMutex.Lock()
+	e.writing = 0
+	e.pendingMutex.Unlock()
+	if err != nil {
+		atomic.AddInt64(&e.droppedWriteFailed, int64(len(batch)))
+		e.telemetry.Logger.Warnf("Failed to persist %d data groups of %s: %v", len(batch), e.name, err)
+		return
+	}
+	if dropped > 0 {
+		atomic.AddInt64(&e.droppedFull, int64(dropped))
+		e.telemetry.Logger.Warnf("The retry queue of %s is full, and %d oldest data groups are dropped", e.name, dropped)
+	}
+	e.wake()
+}
+
+func (e *QueuedRetryExporter) wake() {
+	select {
+	case e.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// replayLoop replays the data groups until the queue is empty, and waits with exponential
+// backoff if the exporter fails.
+func (e *QueuedRetryExporter) replayLoop() {
+	defer e.wg.Done()
+	backoff := e.cfg.InitialInterval
+	for {
+		select {
+		case <-e.wakeCh:
+		case <-e.stopCh:
+			return
+		}
+		for !e.replay() {
+			e.telemetry.Logger.Debugf("The backend of %s is still unavailable, retry in %v", e.name, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-e.stopCh:
+				return
+			}
+			backoff *= 2
+			if backoff > e.cfg.MaxInterval {
+				backoff = e.cfg.MaxInterval
+			}
+		}
+		backoff = e.cfg.InitialInterval
+	}
+}
+
+// replay exports the data groups in the queue in order. It returns false if the exporter
+// fails, or true if the queue is empty.
+func (e *QueuedRetryExporter) replay() bool {
+	for {
+		select {
+		case <-e.stopCh:
+			return true
+		default:
+		}
+		seq, entries, ok, err := e.queue.peek()
+		if !ok {
+			return true
+		}
+		if err != nil {
+			e.telemetry.Logger.Warn("Failed to read the retry queue", zap.String("exporter", e.name), zap.Error(err))
+			atomic.AddInt64(&e.droppedCorrupted, int64(e.queue.remove(seq)))
+			continue
+		}
+		if e.cfg.MaxAge > 0 && time.Since(seqTime(seq)) > e.cfg.MaxAge {
+			atomic.AddInt64(&e.droppedExpired, int64(e.queue.remove(seq)))
+			continue
+		}
+		consumed, done := e.replayBatch(entries)
+		if err = e.queue.commit(seq, entries, consumed); err != nil {
+			e.telemetry.Logger.Warn("Failed to update the retry queue", zap.String("exporter", e.name), zap.Error(err))
+		}
+		if !done {
+			return false
+		}
+	}
+}
+
+// replayBatch exports the entries of a batch in order. It returns the number of the entries
+// consumed, and false if the exporter fails or the exporter is shut down before they are all
+// consumed.
+func (e *QueuedRetryExporter) replayBatch(entries [][]byte) (int, bool) {
+	for i, data := range entries {
+		select {
+		case <-e.stopCh:
+			return i, false
+		default:
+		}
+		dataGroup, err := decode(data)
+		if err != nil {
+			e.telemetry.Logger.Warn("Failed to decode the retry queue", zap.String("exporter", e.name), zap.Error(err))
+			atomic.AddInt64(&e.droppedCorrupted, 1)
+			continue
+		}
+		if err = e.next.Consume(dataGroup); err != nil {
+			if !IsPermanent(err) {
+				return i, false
+			}
+			atomic.AddInt64(&e.droppedRejected, 1)
+		} else {
+			atomic.AddInt64(&e.replayed, 1)
+		}
+	}
+	return len(entries), true
+}
+
+// Shutdown stops replaying and writes the data groups buffered to the disk. The data groups left
+// are kept on the disk for the next run.
+func (e *QueuedRetryExporter) Shutdown() error {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+	e.wg.Wait()
+	unregisterSelfMetrics(e)
+	return nil
+}
@@ -0,0 +1,251 @@
+package queuedretry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// backendStub is an exporter whose backend can be turned down.
+type backendStub struct {
+	mutex      sync.Mutex
+	down       bool
+	dataGroups []*model.DataGroup
+}
+
+func (b *backendStub) Consume(dataGroup *model.DataGroup) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.down {
+		return errors.New("backend is unavailable")
+	}
+	b.dataGroups = append(b.dataGroups, dataGroup)
+	return nil
+}
+
+func (b *backendStub) setDown(down bool) {
+	b.mutex.Lock()
+	b.down = down
+	b.mutex.Unlock()
+}
+
+func (b *backendStub) contentKeys() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	ret := make([]string, 0, len(b.dataGroups))
+	for _, dataGroup := range b.dataGroups {
+		ret = append(ret, dataGroup.Labels.GetStringValue(constlabels.ContentKey))
+	}
+	return ret
+}
+
+func newTestConfig(t *testing.T) *Config {
+	cfg := NewDefaultConfig()
+	cfg.Enabled = true
+	cfg.Directory = t.TempDir()
+	cfg.InitialInterval = 10 * time.Millisecond
+	cfg.MaxInterval = 10 * time.Millisecond
+	cfg.FlushInterval = 10 * time.Millisecond
+	return cfg
+}
+
+func newTrace(contentKey string) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.ContentKey, contentKey)
+	labels.AddIntValue(constlabels.HttpStatusCode, 500)
+	labels.AddBoolValue(constlabels.IsError, true)
+	return model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 1000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 1e9))
+}
+
+func TestReplayAfterBackendRecovers(t *testing.T) {
+	cfg := newTestConfig(t)
+	backend := &backendStub{down: true}
+	e, err := New("kafkaexporter", cfg, backend, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e.Shutdown()
+
+	for _, key := range []string{"/a", "/b", "/c"} {
+		assert.NoError(t, e.Consume(newTrace(key)))
+	}
+	assert.Equal(t, 3, e.size())
+	assert.Empty(t, backend.contentKeys())
+
+	backend.setDown(false)
+	// The data groups are queued behind the persisted ones to keep the order.
+	assert.NoError(t, e.Consume(newTrace("/d")))
+	assert.Eventually(t, func() bool { return e.size() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/a", "/b", "/c", "/d"}, backend.contentKeys())
+
+	got := backend.dataGroups[0]
+	assert.Equal(t, int64(500), got.Labels.GetIntValue(constlabels.HttpStatusCode))
+	assert.True(t, got.Labels.GetBoolValue(constlabels.IsError))
+	metric, ok := got.GetMetric(constvalues.RequestTotalTime)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1e9), metric.GetInt().Value)
+	assert.Equal(t, int64(4), e.enqueued)
+	assert.Equal(t, int64(4), e.replayed)
+
+	// The data groups are exported directly once the queue is empty.
+	assert.NoError(t, e.Consume(newTrace("/e")))
+	assert.Equal(t, "/e", backend.contentKeys()[4])
+	assert.Equal(t, int64(4), e.enqueued)
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	cfg := newTestConfig(t)
+	backend := &backendStub{down: true}
+	e, err := New("kafkaexporter", cfg, backend, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	assert.NoError(t, e.Consume(newTrace("/a")))
+	assert.NoError(t, e.Consume(newTrace("/b")))
+	assert.NoError(t, e.Shutdown())
+
+	// The data groups buffered are written in one batch when shutting down.
+	files, _ := filepath.Glob(filepath.Join(cfg.Directory, "kafkaexporter", "*"+fileSuffix))
+	assert.Len(t, files, 1)
+	// A temporary file left by a crash is removed.
+	tmpFile := filepath.Join(cfg.Directory, "kafkaexporter", "1.json"+tmpFileSuffix)
+	require.NoError(t, os.WriteFile(tmpFile, []byte("{"), 0644))
+
+	backend = &backendStub{}
+	e, err = New("kafkaexporter", cfg, backend, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e.Shutdown()
+	assert.Eventually(t, func() bool { return e.size() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/a", "/b"}, backend.contentKeys())
+	assert.NoFileExists(t, tmpFile)
+}
+
+func TestPersistInBatches(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.FlushInterval = time.Hour
+	cfg.MaxBatchSize = 3
+	// The backend accepts only one data group after it recovers.
+	backend := &backendStub{down: true}
+	var mutex sync.Mutex
+	accepted := 0
+	flaky := consumerFunc(func(dataGroup *model.DataGroup) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if accepted == 1 {
+			return errors.New("backend is unavailable")
+		}
+		if err := backend.Consume(dataGroup); err != nil {
+			return err
+		}
+		accepted++
+		return nil
+	})
+	e, err := New("kafkaexporter", cfg, flaky, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e.Shutdown()
+
+	dir := filepath.Join(cfg.Directory, "kafkaexporter")
+	assert.NoError(t, e.Consume(newTrace("/a")))
+	assert.NoError(t, e.Consume(newTrace("/b")))
+	// Consume doesn't write the disk.
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+fileSuffix))
+	assert.Empty(t, files)
+	assert.Equal(t, 2, e.size())
+
+	// The batch is written once it is full.
+	assert.NoError(t, e.Consume(newTrace("/c")))
+	assert.Eventually(t, func() bool { return e.queue.size() == 3 }, 5*time.Second, 10*time.Millisecond)
+	files, _ = filepath.Glob(filepath.Join(dir, "*"+fileSuffix))
+	assert.Len(t, files, 1)
+
+	// The batch is rewritten with the entries left if the backend fails in the middle of it.
+	backend.setDown(false)
+	assert.Eventually(t, func() bool { return e.queue.size() == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/a"}, backend.contentKeys())
+	queue, _, err := openDiskQueue(dir, cfg.MaxQueueSize)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.size())
+}
+
+func TestDropWhenQueueIsFull(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.MaxQueueSize = 2
+	backend := &backendStub{down: true}
+	e, err := New("kafkaexporter", cfg, backend, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e.Shutdown()
+	for _, key := range []string{"/a", "/b", "/c"} {
+		assert.NoError(t, e.Consume(newTrace(key)))
+	}
+	assert.Equal(t, 2, e.size())
+	assert.Equal(t, int64(1), e.droppedFull)
+
+	backend.setDown(false)
+	assert.Eventually(t, func() bool { return e.size() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/b", "/c"}, backend.contentKeys())
+}
+
+func TestDropExpiredAndPermanentErrors(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.MaxAge = time.Nanosecond
+	backend := &backendStub{down: true}
+	e, err := New("kafkaexporter", cfg, backend, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e.Shutdown()
+	assert.NoError(t, e.Consume(newTrace("/a")))
+	backend.setDown(false)
+	assert.Eventually(t, func() bool { return e.size() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, backend.contentKeys())
+	assert.Equal(t, int64(1), e.droppedExpired)
+
+	rejecting := consumerFunc(func(*model.DataGroup) error {
+		return NewPermanentError(errors.New("invalid data"))
+	})
+	e2, err := New("rejecting", newTestConfig(t), rejecting, component.NewDefaultTelemetryTools())
+	require.NoError(t, err)
+	defer e2.Shutdown()
+	assert.True(t, IsPermanent(e2.Consume(newTrace("/a"))))
+	assert.Equal(t, 0, e2.size())
+	assert.Equal(t, int64(1), e2.droppedRejected)
+}
+
+type consumerFunc func(dataGroup *model.DataGroup) error
+
+func (f consumerFunc) Consume(dataGroup *model.DataGroup) error {
+	return f(dataGroup)
+}
+
+func TestCodecWithSummary(t *testing.T) {
+	sketch := ddsketch.New(ddsketch.DefaultRelativeAccuracy)
+	for i := 1; i <= 100; i++ {
+		sketch.Add(float64(i * 1000))
+	}
+	dataGroup := newTrace("summary")
+	dataGroup.AddMetric(model.NewSummaryMetric(constvalues.RequestTotalTime+"_summary", &model.Summary{
+		Sum:    5050000,
+		Count:  100,
+		Sketch: sketch,
+	}))
+	data, err := encode(dataGroup)
+	require.NoError(t, err)
+	decoded, err := decode(data)
+	require.NoError(t, err)
+	require.Len(t, decoded.Metrics, 2)
+	summary := decoded.Metrics[1].GetSummary()
+	require.NotNil(t, summary)
+	assert.Equal(t, int64(5050000), summary.Sum)
+	assert.Equal(t, uint64(100), summary.Count)
+	assert.Equal(t, ddsketch.DefaultQuantiles, summary.GetQuantiles())
+	assert.Equal(t, sketch, summary.Sketch)
+	assert.InEpsilon(t, 99000, summary.Sketch.Quantile(0.99), 0.01)
+}
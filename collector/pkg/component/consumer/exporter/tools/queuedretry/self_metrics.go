@@ -0,0 +1,70 @@
+package queuedretry
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	queueSizeMetric       = "kindling_telemetry_queuedretry_queue_size"
+	dataGroupsTotalMetric = "kindling_telemetry_queuedretry_data_groups_total"
+	droppedTotalMetric    = "kindling_telemetry_queuedretry_dropped_total"
+)
+
+var (
+	once sync.Once
+	// exporters are all the exporters wrapped, which are observed by the same instruments.
+	exporters      = make(map[*QueuedRetryExporter]struct{})
+	exportersMutex sync.Mutex
+)
+
+func newSelfMetrics(meterProvider metric.MeterProvider, e *QueuedRetryExporter) {
+	exportersMutex.Lock()
+	exporters[e] = struct{}{}
+	exportersMutex.Unlock()
+	once.Do(func() {
+		meter := metric.Must(meterProvider.Meter("kindling"))
+		meter.NewInt64GaugeObserver(queueSizeMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				observe(func(e *QueuedRetryExporter, exporterLabel attribute.KeyValue) {
+					result.Observe(int64(e.size()), exporterLabel)
+				})
+			}, metric.WithDescription("The current number of the data groups buffered and persisted in the retry queue"))
+		meter.NewInt64CounterObserver(dataGroupsTotalMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				observe(func(e *QueuedRetryExporter, exporterLabel attribute.KeyValue) {
+					result.Observe(atomic.LoadInt64(&e.enqueued), exporterLabel, attribute.String("result", "enqueued"))
+					result.Observe(atomic.LoadInt64(&e.replayed), exporterLabel, attribute.String("result", "replayed"))
+				})
+			}, metric.WithDescription("The total count of the data groups enqueued and replayed by the retry queue"))
+		meter.NewInt64CounterObserver(droppedTotalMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				observe(func(e *QueuedRetryExporter, exporterLabel attribute.KeyValue) {
+					result.Observe(atomic.LoadInt64(&e.droppedFull), exporterLabel, attribute.String("reason", "queue_full"))
+					result.Observe(atomic.LoadInt64(&e.droppedExpired), exporterLabel, attribute.String("reason", "expired"))
+					result.Observe(atomic.LoadInt64(&e.droppedRejected), exporterLabel, attribute.String("reason", "rejected"))
+					result.Observe(atomic.LoadInt64(&e.droppedUnsupported), exporterLabel, attribute.String("reason", "unsupported"))
+					result.Observe(atomic.LoadInt64(&e.droppedCorrupted), exporterLabel, attribute.String("reason", "corrupted"))
+					result.Observe(atomic.LoadInt64(&e.droppedWriteFailed), exporterLabel, attribute.String("reason", "write_failed"))
+				})
+			}, metric.WithDescription("The total count of the data groups dropped by the retry queue"))
+	})
+}
+
+func observe(f func(e *QueuedRetryExporter, exporterLabel attribute.KeyValue)) {
+	exportersMutex.Lock()
+	defer exportersMutex.Unlock()
+	for e := range exporters {
+		f(e, attribute.String("exporter", e.name))
+	}
+}
+
+func unregisterSelfMetrics(e *QueuedRetryExporter) {
+	exportersMutex.Lock()
+	delete(exporters, e)
+	exportersMutex.Unlock()
+}
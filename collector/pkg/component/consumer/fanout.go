@@ -0,0 +1,57 @@
+package consumer
+
+import (
+	"go.uber.org/multierr"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// fanOutConsumer sends every DataGroup to all of its consumers in order.
+// The DataGroup is shared by the consumers, so they should not modify it.
+type fanOutConsumer []Consumer
+
+// NewFanOut returns a Consumer that forwards DataGroups to all the consumers.
+// If there is only one consumer, it is returned directly.
+func NewFanOut(consumers ...Consumer) Consumer {
+	if len(consumers) == 1 {
+		return consumers[0]
+	}
+	return fanOutConsumer(consumers)
+}
+
+func (f fanOutConsumer) Consume(dataGroup *model.DataGroup) error {
+	var retErr error
+	for _, c := range f {
+		retErr = multierr.Append(retErr, c.Consume(dataGroup))
+	}
+	return retErr
+}
+
+// cloningFanOutConsumer sends every DataGroup to all of its consumers in order, but
+// each consumer except the first one receives its own copy.
+type cloningFanOutConsumer []Consumer
+
+// NewCloningFanOut returns a Consumer that forwards DataGroups to all the consumers
+// without sharing them. It is used when an analyzer is declared by several pipelines,
+// because the processors of each pipeline modify the DataGroups in place.
+// The copies are made before any consumer is called.
+// If there is only one consumer, it is returned directly.
+func NewCloningFanOut(consumers ...Consumer) Consumer {
+	if len(consumers) == 1 {
+		return consumers[0]
+	}
+	return cloningFanOutConsumer(consumers)
+}
+
+func (f cloningFanOutConsumer) Consume(dataGroup *model.DataGroup) error {
+	dataGroups := make([]*model.DataGroup, len(f))
+	dataGroups[0] = dataGroup
+	for i := 1; i < len(f); i++ {
+		dataGroups[i] = dataGroup.Clone()
+	}
+	var retErr error
+	for i, c := range f {
+		retErr = multierr.Append(retErr, c.Consume(dataGroups[i]))
+	}
+	return retErr
+}
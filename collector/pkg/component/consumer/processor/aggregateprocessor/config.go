@@ -6,12 +6,22 @@ type Config struct {
 
 	AggregateKindMap map[string][]AggregatedKindConfig `mapstructure:"aggregate_kind_map"`
 	SamplingRate     *SampleConfig                     `mapstructure:"sampling_rate"`
+	// MaxSeries is the max number of the series of each metric group within one interval.
+	// Once it is reached, the new label combinations are folded into the series whose string labels
+	// are "__overflow__". 0 means no limit.
+	MaxSeries int `mapstructure:"max_series"`
 }
 
 type AggregatedKindConfig struct {
 	OutputName         string  `mapstructure:"output_name"`
 	Kind               string  `mapstructure:"kind"`
 	ExplicitBoundaries []int64 `mapstructure:"explicit_boundaries"`
+	// RelativeAccuracy is only used by the "ddsketch" kind. The quantiles calculated are within
+	// this relative error, e.g. 0.01 means 1%.
+	RelativeAccuracy float64 `mapstructure:"relative_accuracy"`
+	// Quantiles is only used by the "ddsketch" kind. They are the quantiles all the exporters
+	// export for the summary. [0.5, 0.9, 0.99] is used if it is empty.
+	Quantiles []float64 `mapstructure:"quantiles"`
 }
 
 type SampleConfig struct {
@@ -38,6 +48,7 @@ func NewDefaultConfig() *Config {
 			SlowData:   100,
 			ErrorData:  100,
 		},
+		MaxSeries: 50000,
 	}
 	return ret
 }
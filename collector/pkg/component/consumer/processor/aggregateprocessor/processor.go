@@ -2,6 +2,7 @@ package aggregateprocessor
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,6 +13,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/cpuanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
@@ -33,20 +35,32 @@ type AggregateProcessor struct {
 	netRequestLabelSelectors *aggregator.LabelSelectors
 	tcpLabelSelectors        *aggregator.LabelSelectors
 	stopCh                   chan struct{}
+	tickerDone               chan struct{}
+	stopOnce                 sync.Once
 	ticker                   *time.Ticker
 }
 
 func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
 	cfg := config.(*Config)
+	aggregatedConfig := toAggregatedConfig(cfg.AggregateKindMap)
+	aggregatedConfig.MaxSeries = cfg.MaxSeries
+	defaultAggregator := defaultaggregator.NewDefaultAggregator(aggregatedConfig)
+	defaultAggregator.RegisterSelfMetrics(Type, telemetry.MeterProvider)
+	netRequestLabelSelectors := newNetRequestLabelSelectors()
+	appendPodDimensionSelectors(netRequestLabelSelectors, kubernetes.PodDimensions())
+	if err := netRequestLabelSelectors.CheckSize(); err != nil {
+		telemetry.Logger.Panic("Too many pod_labels and pod_annotations of the k8sprocessor", zap.String("componentType", Type), zap.Error(err))
+	}
 	p := &AggregateProcessor{
 		cfg:          cfg,
 		telemetry:    telemetry,
 		nextConsumer: nextConsumer,
 
-		aggregator:               defaultaggregator.NewDefaultAggregator(toAggregatedConfig(cfg.AggregateKindMap)),
-		netRequestLabelSelectors: newNetRequestLabelSelectors(),
+		aggregator:               defaultAggregator,
+		netRequestLabelSelectors: netRequestLabelSelectors,
 		tcpLabelSelectors:        newTcpLabelSelectors(),
 		stopCh:                   make(chan struct{}),
+		tickerDone:               make(chan struct{}),
 		ticker:                   time.NewTicker(time.Duration(cfg.TickerInterval) * time.Second),
 	}
 	go p.runTicker()
@@ -83,6 +97,13 @@ func newKindConfig(rawConfig *AggregatedKindConfig) (kindConfig defaultaggregato
 			Kind:               kind,
 			ExplicitBoundaries: boundaries,
 		}
+	case defaultaggregator.DDSketchKind:
+		return defaultaggregator.KindConfig{
+			OutputName:       rawConfig.OutputName,
+			Kind:             kind,
+			RelativeAccuracy: rawConfig.RelativeAccuracy,
+			Quantiles:        rawConfig.Quantiles,
+		}
 	default:
 		return defaultaggregator.KindConfig{
 			OutputName: rawConfig.OutputName,
@@ -91,21 +112,40 @@ func newKindConfig(rawConfig *AggregatedKindConfig) (kindConfig defaultaggregato
 	}
 }
 
-// TODO: Graceful shutdown
+// Shutdown stops dumping the aggregated metrics periodically and sends the metrics aggregated
+// since the last tick to the next consumer, which is shut down after the processors.
+func (p *AggregateProcessor) Shutdown() error {
+	p.stopOnce.Do(func() {
+		p.ticker.Stop()
+		close(p.stopCh)
+		<-p.tickerDone
+		p.dump()
+	})
+	if a, ok := p.aggregator.(*defaultaggregator.DefaultAggregator); ok {
+		a.UnregisterSelfMetrics()
+	}
+	return nil
+}
+
 func (p *AggregateProcessor) runTicker() {
+	defer close(p.tickerDone)
 	for {
 		select {
 		case <-p.stopCh:
 			return
 		case <-p.ticker.C:
-			aggResults := p.aggregator.Dump()
-			for _, agg := range aggResults {
-				err := p.nextConsumer.Consume(agg)
-				if err != nil {
-					p.telemetry.Logger.Warn("Error happened when consuming aggregated recordersMap",
-						zap.Error(err))
-				}
-			}
+			p.dump()
+		}
+	}
+}
+
+func (p *AggregateProcessor) dump() {
+	aggResults := p.aggregator.Dump()
+	for _, agg := range aggResults {
+		err := p.nextConsumer.Consume(agg)
+		if err != nil {
+			p.telemetry.Logger.Warn("Error happened when consuming aggregated recordersMap",
+				zap.Error(err))
 		}
 	}
 }
@@ -146,6 +186,8 @@ func newNetRequestLabelSelectors() *aggregator.LabelSelectors {
 	return aggregator.NewLabelSelectors(
 		aggregator.LabelSelector{Name: constlabels.Pid, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.Comm, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessSystemdUnit, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessUser, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.Protocol, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.IsServer, VType: aggregator.BooleanType},
 		aggregator.LabelSelector{Name: constlabels.ContainerId, VType: aggregator.StringType},
@@ -159,6 +201,7 @@ func newNetRequestLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.SrcIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SrcContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNamespace, VType: aggregator.StringType},
@@ -172,19 +215,44 @@ func newNetRequestLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DnatPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DstContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.DstContainerImage, VType: aggregator.StringType},
 
 		aggregator.LabelSelector{Name: constlabels.IsError, VType: aggregator.BooleanType},
 		aggregator.LabelSelector{Name: constlabels.IsSlow, VType: aggregator.BooleanType},
 		aggregator.LabelSelector{Name: constlabels.HttpStatusCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.GrpcStatus, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DnsRcode, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.SqlErrCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.SqlState, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.MongodbErrCode, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.ContentKey, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DnsDomain, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.KafkaTopic, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.RocketMQErrCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.AmqpReplyCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.CustomStatus, VType: aggregator.StringType},
 	)
 }
 
+// appendPodDimensionSelectors selects the pod labels and annotations of both sides attached by
+// the k8sprocessor. The keys are converted into the names of the labels like the exporters do.
+func appendPodDimensionSelectors(selectors *aggregator.LabelSelectors, keys []string) {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		name := constlabels.PodDimensionName(key)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		selectors.AppendSelectors(
+			aggregator.LabelSelector{Name: constlabels.SrcLabelPrefix + name, VType: aggregator.StringType},
+			aggregator.LabelSelector{Name: constlabels.DstLabelPrefix + name, VType: aggregator.StringType})
+	}
+}
+
 func newTcpLabelSelectors() *aggregator.LabelSelectors {
 	return aggregator.NewLabelSelectors(
 		aggregator.LabelSelector{Name: constlabels.SrcNode, VType: aggregator.StringType},
@@ -198,6 +266,7 @@ func newTcpLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.SrcPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SrcContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNamespace, VType: aggregator.StringType},
@@ -211,6 +280,7 @@ func newTcpLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DnatPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DstContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.DstContainerImage, VType: aggregator.StringType},
 	)
 }
 
@@ -218,6 +288,8 @@ func newTcpConnectLabelSelectors() *aggregator.LabelSelectors {
 	return aggregator.NewLabelSelectors(
 		aggregator.LabelSelector{Name: constlabels.Pid, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.Comm, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessSystemdUnit, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProcessUser, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcNamespace, VType: aggregator.StringType},
@@ -228,6 +300,7 @@ func newTcpConnectLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.SrcIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.SrcContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SrcContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNode, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNodeIp, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstNamespace, VType: aggregator.StringType},
@@ -241,6 +314,7 @@ func newTcpConnectLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DnatPort, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.DstContainerId, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.DstContainerImage, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.Errno, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.Success, VType: aggregator.BooleanType},
 	)
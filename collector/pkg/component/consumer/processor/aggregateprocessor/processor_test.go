@@ -0,0 +1,121 @@
+package aggregateprocessor
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type collector struct {
+	mutex      sync.Mutex
+	dataGroups []*model.DataGroup
+}
+
+func (c *collector) Consume(dataGroup *model.DataGroup) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func (c *collector) aggregated() []*model.DataGroup {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ret := make([]*model.DataGroup, 0)
+	for _, dataGroup := range c.dataGroups {
+		if dataGroup.Name == constnames.AggregatedNetRequestMetricGroup {
+			ret = append(ret, dataGroup)
+		}
+	}
+	return ret
+}
+
+func TestLabelSelectors_Size(t *testing.T) {
+	// The selectors after the max size of the label keys are dropped silently.
+	assert.NoError(t, newNetRequestLabelSelectors().CheckSize())
+	assert.NoError(t, newTcpLabelSelectors().CheckSize())
+	assert.NoError(t, newTcpConnectLabelSelectors().CheckSize())
+}
+
+func TestAggregateProcessor_PodDimensions(t *testing.T) {
+	kubernetes.SetPodDimensions([]string{"app.kubernetes.io/version"}, []string{"team"})
+	defer kubernetes.SetPodDimensions(nil, nil)
+	cfg := NewDefaultConfig()
+	cfg.TickerInterval = 1
+	next := &collector{}
+	p := New(cfg, component.NewDefaultTelemetryTools(), next)
+
+	for _, isServer := range []bool{true, false} {
+		labels := model.NewAttributeMap()
+		labels.AddBoolValue(constlabels.IsServer, isServer)
+		labels.AddStringValue(constlabels.Protocol, "http")
+		labels.AddStringValue(constlabels.SrcNamespace, "default")
+		labels.AddStringValue(constlabels.DstNamespace, "default")
+		labels.AddStringValue(constlabels.SrcLabelPrefix+"team", "frontend")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"app_kubernetes_io_version", "v1.2.0")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"team", "payment")
+		labels.AddStringValue(constlabels.DstLabelPrefix+"ignored", "true")
+		_ = p.Consume(model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0,
+			model.NewIntMetric(constvalues.RequestTotalTime, 100)))
+	}
+	assert.Eventually(t, func() bool { return len(next.aggregated()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// The exporters see the pod dimensions in the entity and topology request metrics.
+	netAdapter := adapter.NewNetAdapter(nil, &adapter.NetAdapterConfig{PodDimensions: kubernetes.PodDimensions()})
+	for _, aggregated := range next.aggregated() {
+		assert.False(t, aggregated.Labels.HasAttribute(constlabels.DstLabelPrefix+"ignored"))
+		results, err := netAdapter.Adapt(aggregated, adapter.AttributeMap)
+		if !assert.NoError(t, err) || !assert.NotEmpty(t, results) {
+			continue
+		}
+		attrs := results[0].AttrsMap
+		if aggregated.Labels.GetBoolValue(constlabels.IsServer) {
+			assert.Equal(t, "v1.2.0", attrs.GetStringValue("label_app_kubernetes_io_version"))
+			assert.Equal(t, "payment", attrs.GetStringValue("label_team"))
+		} else {
+			assert.Equal(t, "frontend", attrs.GetStringValue("src_label_team"))
+			assert.Equal(t, "payment", attrs.GetStringValue("dst_label_team"))
+		}
+	}
+}
+
+func TestNew_TooManyPodDimensions(t *testing.T) {
+	var podLabels []string
+	for i := 0; i < 20; i++ {
+		podLabels = append(podLabels, "label"+strconv.Itoa(i))
+	}
+	kubernetes.SetPodDimensions(podLabels, nil)
+	defer kubernetes.SetPodDimensions(nil, nil)
+	assert.Panics(t, func() { New(NewDefaultConfig(), component.NewDefaultTelemetryTools(), &collector{}) })
+}
+
+func TestAggregateProcessor_ShutdownFlushes(t *testing.T) {
+	cfg := NewDefaultConfig()
+	// The ticker never fires during the test.
+	cfg.TickerInterval = 3600
+	next := &collector{}
+	p := New(cfg, component.NewDefaultTelemetryTools(), next).(*AggregateProcessor)
+
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.Protocol, "http")
+	_ = p.Consume(model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0,
+		model.NewIntMetric(constvalues.RequestTotalTime, 100)))
+	assert.Empty(t, next.aggregated())
+
+	assert.NoError(t, p.Shutdown())
+	assert.Len(t, next.aggregated(), 1)
+	// The metrics are dumped only once.
+	assert.NoError(t, p.Shutdown())
+	assert.Len(t, next.aggregated(), 1)
+}
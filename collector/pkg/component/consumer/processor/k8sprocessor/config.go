@@ -1,6 +1,7 @@
 package k8sprocessor
 
 import (
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/container"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 )
 
@@ -15,9 +16,37 @@ type Config struct {
 	// The default value is false. It should be enabled if the ReplicaSet
 	// is used to control pods in the third-party CRD except for Deployment.
 	EnableFetchReplicaSet bool `mapstructure:"enable_fetch_replicaset"`
+	// EnableEndpointSlice controls whether to associate pods with services through the
+	// EndpointSlices instead of matching the selectors of services with the labels of all
+	// pods, which is expensive in big clusters. The addresses of the EndpointSlices, such as
+	// the external endpoints of selector-less services, are also resolved to the services.
+	// The default value is false. The agent needs the permission to list and watch the
+	// EndpointSlices of "discovery.k8s.io".
+	EnableEndpointSlice bool `mapstructure:"enable_endpoint_slice"`
+	// EnableResolveOwnerChain controls whether to walk the owner references of pods up to
+	// the top-level controller, such as Pod->Job->CronJob or Pod->ReplicaSet->Rollout.
+	// The default value is false. The agent needs the permission to list and watch the
+	// kinds met in the owner chains.
+	EnableResolveOwnerChain bool `mapstructure:"enable_resolve_owner_chain"`
+	// OwnerChainStopKinds are the kinds at which the walk stops even if they are
+	// controlled by others. The default value is [Deployment, StatefulSet, DaemonSet]
+	// if it is not set.
+	OwnerChainStopKinds []string `mapstructure:"owner_chain_stop_kinds"`
+	// PodLabels and PodAnnotations are the keys of the pod labels and annotations that
+	// are attached to the data as "src_label_<name>" and "dst_label_<name>", where the
+	// name is the key with the characters other than [a-zA-Z0-9_] replaced by "_".
+	// They are empty by default, which means no label or annotation is attached.
+	PodLabels      []string `mapstructure:"pod_labels"`
+	PodAnnotations []string `mapstructure:"pod_annotations"`
 	// Set "Enable" false if you want to run the agent in the non-Kubernetes environment.
 	// Otherwise, the agent will panic if it can't connect to the API-server.
 	Enable bool `mapstructure:"enable"`
+	// ContainerRuntime configures querying the container runtime for the metadata of the
+	// containers when "Enable" is false, e.g. on the plain Docker or containerd hosts.
+	// The containers are labeled with the compose project as the namespace, and the compose
+	// service or the container itself as the workload. The container labels of PodLabels
+	// are attached the same way as the pod labels.
+	ContainerRuntime container.Config `mapstructure:"container_runtime"`
 }
 
 var DefaultConfig Config = Config{
@@ -25,4 +54,5 @@ var DefaultConfig Config = Config{
 	KubeConfigDir:     "/root/.kube/config",
 	GraceDeletePeriod: 60,
 	Enable:            true,
+	ContainerRuntime:  container.DefaultConfig,
 }
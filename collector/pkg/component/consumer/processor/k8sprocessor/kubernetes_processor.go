@@ -2,12 +2,14 @@ package k8sprocessor
 
 import (
 	"strconv"
+	"sync"
 
 	"go.uber.org/zap"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/container"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
@@ -19,6 +21,9 @@ const (
 	loopbackIp  = "127.0.0.1"
 )
 
+// containerProviderOnce makes the processors of all the pipelines share one container provider.
+var containerProviderOnce sync.Once
+
 type K8sMetadataProcessor struct {
 	config        *Config
 	metadata      *kubernetes.K8sMetaDataCache
@@ -33,8 +38,20 @@ func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools
 	if !ok {
 		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", K8sMetadata))
 	}
+	RegisterPodDimensions(config)
 	if !config.Enable {
 		telemetry.Logger.Info("The kubernetes processor is disabled by the configuration. Won't connect to the API-server and no Kubernetes metadata will be fetched.")
+		if config.ContainerRuntime.Enable {
+			containerProviderOnce.Do(func() {
+				provider, err := container.NewProvider(&config.ContainerRuntime, config.PodLabels, telemetry.Logger)
+				if err != nil {
+					telemetry.Logger.Panicf("Failed to initialize the container runtime of [%s]: %v", K8sMetadata, err)
+					return
+				}
+				kubernetes.MetaDataCache.SetContainerProvider(provider)
+				telemetry.Logger.Info("The metadata of the containers will be fetched from the container runtime.", zap.String("runtime", config.ContainerRuntime.Runtime))
+			})
+		}
 		return &K8sMetadataProcessor{
 			config:       config,
 			metadata:     kubernetes.MetaDataCache,
@@ -48,6 +65,9 @@ func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools
 		kubernetes.WithKubeConfigDir(config.KubeConfigDir),
 		kubernetes.WithGraceDeletePeriod(config.GraceDeletePeriod),
 		kubernetes.WithFetchReplicaSet(config.EnableFetchReplicaSet),
+		kubernetes.WithEndpointSlice(config.EnableEndpointSlice),
+		kubernetes.WithResolveOwnerChain(config.EnableResolveOwnerChain, config.OwnerChainStopKinds),
+		kubernetes.WithPodDimensions(config.PodLabels, config.PodAnnotations),
 	)
 	err := kubernetes.InitK8sHandler(options...)
 	if err != nil {
@@ -72,8 +92,25 @@ func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools
 	}
 }
 
+// RegisterPodDimensions registers the keys of the pod labels and annotations attached by the
+// processor, which the consumers after it read by kubernetes.PodDimensions. Only the labels are
+// attached when the metadata are fetched from the container runtime.
+func RegisterPodDimensions(config *Config) {
+	switch {
+	case config.Enable:
+		kubernetes.SetPodDimensions(config.PodLabels, config.PodAnnotations)
+	case config.ContainerRuntime.Enable:
+		kubernetes.SetPodDimensions(config.PodLabels, nil)
+	default:
+		kubernetes.SetPodDimensions(nil, nil)
+	}
+}
+
 func (p *K8sMetadataProcessor) Consume(dataGroup *model.DataGroup) error {
 	if !p.config.Enable {
+		if p.config.ContainerRuntime.Enable {
+			p.addContainerMetaData(dataGroup.Labels)
+		}
 		return p.nextConsumer.Consume(dataGroup)
 	}
 	name := dataGroup.Name
@@ -167,6 +204,9 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.Attr
 					labelMap.UpdateAddStringValue(constlabels.DstNode, nodeName)
 				}
 			}
+		} else if resInfo, ok := p.metadata.GetContainerByIpPort(dstIp, uint32(dstPort)); ok {
+			// DstIp is IP of an endpoint of the service, e.g. a pod behind a headless service
+			addContainerMetaInfoLabelDST(labelMap, resInfo)
 		}
 	} else if resInfo, ok := p.metadata.GetContainerByIpPort(dstIp, uint32(dstPort)); ok {
 		// DstIp is IP of a container
@@ -221,6 +261,25 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForServerLabel(labelMap *model.Attr
 	}
 }
 
+// addContainerMetaData is used to add the metadata of the containers found by the container
+// runtime when the Kubernetes metadata is disabled. Only the container where the event happens
+// is known, which is the client if the data is from the client side and the server otherwise.
+func (p *K8sMetadataProcessor) addContainerMetaData(labelMap *model.AttributeMap) {
+	containerId := labelMap.GetStringValue(constlabels.ContainerId)
+	if containerId == "" {
+		return
+	}
+	containerInfo, ok := p.metadata.GetByContainerId(containerId)
+	if !ok {
+		return
+	}
+	if labelMap.GetBoolValue(constlabels.IsServer) {
+		addContainerMetaInfoLabelDST(labelMap, containerInfo)
+	} else {
+		addContainerMetaInfoLabelSRC(labelMap, containerInfo)
+	}
+}
+
 // addK8sMetaDataViaIp is used to add k8s metadata to tcp metrics.
 // There is also a piece of code for removing "port" in this method, which
 // should be moved into a processor that is used for relabeling tcp metrics later.
@@ -316,6 +375,9 @@ func (p *K8sMetadataProcessor) addK8sMetaDataViaIpDST(labelMap *model.AttributeM
 			if ok {
 				addContainerMetaInfoLabelDST(labelMap, resInfo)
 			}
+		} else if resInfo, ok := p.metadata.GetContainerByIpPort(dstIp, uint32(dstPort)); ok {
+			// DstIp is IP of an endpoint of the service, e.g. a pod behind a headless service
+			addContainerMetaInfoLabelDST(labelMap, resInfo)
 		}
 		return
 	}
@@ -349,6 +411,9 @@ func (p *K8sMetadataProcessor) addK8sMetaDataViaIpDST(labelMap *model.AttributeM
 func addContainerMetaInfoLabelSRC(labelMap *model.AttributeMap, containerInfo *kubernetes.K8sContainerInfo) {
 	labelMap.UpdateAddStringValue(constlabels.SrcContainer, containerInfo.Name)
 	labelMap.UpdateAddStringValue(constlabels.SrcContainerId, containerInfo.ContainerId)
+	if containerInfo.Image != "" {
+		labelMap.UpdateAddStringValue(constlabels.SrcContainerImage, containerInfo.Image)
+	}
 	addPodMetaInfoLabelSRC(labelMap, containerInfo.RefPodInfo)
 }
 
@@ -363,11 +428,17 @@ func addPodMetaInfoLabelSRC(labelMap *model.AttributeMap, podInfo *kubernetes.K8
 	if podInfo.ServiceInfo != nil {
 		labelMap.UpdateAddStringValue(constlabels.SrcService, podInfo.ServiceInfo.ServiceName)
 	}
+	for name, value := range podInfo.Dimensions {
+		labelMap.UpdateAddStringValue(constlabels.SrcLabelPrefix+name, value)
+	}
 }
 
 func addContainerMetaInfoLabelDST(labelMap *model.AttributeMap, containerInfo *kubernetes.K8sContainerInfo) {
 	labelMap.UpdateAddStringValue(constlabels.DstContainer, containerInfo.Name)
 	labelMap.UpdateAddStringValue(constlabels.DstContainerId, containerInfo.ContainerId)
+	if containerInfo.Image != "" {
+		labelMap.UpdateAddStringValue(constlabels.DstContainerImage, containerInfo.Image)
+	}
 	addPodMetaInfoLabelDST(labelMap, containerInfo.RefPodInfo)
 }
 
@@ -381,4 +452,7 @@ func addPodMetaInfoLabelDST(labelMap *model.AttributeMap, podInfo *kubernetes.K8
 	if labelMap.GetStringValue(constlabels.DstIp) == "" {
 		labelMap.UpdateAddStringValue(constlabels.DstIp, podInfo.Ip)
 	}
+	for name, value := range podInfo.Dimensions {
+		labelMap.UpdateAddStringValue(constlabels.DstLabelPrefix+name, value)
+	}
 }
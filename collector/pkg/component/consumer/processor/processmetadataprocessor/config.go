@@ -0,0 +1,25 @@
+package processmetadataprocessor
+
+type Config struct {
+	// ProcRoot is where the proc filesystem of the host is mounted.
+	// The default value is "/proc".
+	ProcRoot string `mapstructure:"proc_root"`
+	// CacheSize is the maximum number of the processes cached. The default value is 10000.
+	CacheSize int `mapstructure:"cache_size"`
+	// ExitDelay controls how long the processes are kept after receiving the procexit events.
+	// The unit is seconds, and the default value is 10 seconds.
+	ExitDelay int `mapstructure:"exit_delay"`
+	// FillWorkload controls whether to use the systemd unit or the executable as the workload
+	// of the processes running outside the containers. The workload kind is "systemd" or "process".
+	// The default value is true.
+	FillWorkload bool `mapstructure:"fill_workload"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		ProcRoot:     "/proc",
+		CacheSize:    10000,
+		ExitDelay:    10,
+		FillWorkload: true,
+	}
+}
@@ -0,0 +1,105 @@
+package processmetadataprocessor
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/process"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	Type = "processmetadataprocessor"
+
+	// SystemdKind is the workload kind of the processes managed by systemd.
+	SystemdKind = "systemd"
+	// ProcessKind is the workload kind of the other processes, named by their executables.
+	ProcessKind = "process"
+)
+
+// ProcessMetadataProcessor adds the metadata of the process where the event happens, which is
+// found by the label "pid". It should be placed after k8smetadataprocessor, so that the workload
+// is only filled for the processes running outside the containers.
+// The processes are cached and expired by the procexit events received by processanalyzer.
+type ProcessMetadataProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+	cache        *process.Cache
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Type))
+	}
+	cache, err := process.InitCache(cfg.ProcRoot, cfg.CacheSize, time.Duration(cfg.ExitDelay)*time.Second)
+	if err != nil {
+		telemetry.Logger.Panicf("Failed to initialize [%s]: %v", Type, err)
+		return nil
+	}
+	return &ProcessMetadataProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		cache:        cache,
+	}
+}
+
+func (p *ProcessMetadataProcessor) Consume(dataGroup *model.DataGroup) error {
+	pid := dataGroup.Labels.GetIntValue(constlabels.Pid)
+	if info, ok := p.cache.Get(int(pid)); ok {
+		addProcessLabels(dataGroup.Labels, info)
+		if p.cfg.FillWorkload {
+			fillWorkload(dataGroup.Labels, info)
+		}
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
+
+func addProcessLabels(labelMap *model.AttributeMap, info *process.Info) {
+	labelMap.UpdateAddStringValue(constlabels.ProcessCmdline, info.Cmdline)
+	labelMap.UpdateAddStringValue(constlabels.ProcessExe, info.Exe)
+	labelMap.UpdateAddStringValue(constlabels.ProcessCgroup, info.CgroupPath)
+	labelMap.UpdateAddStringValue(constlabels.ProcessSystemdUnit, info.SystemdUnit)
+	labelMap.UpdateAddStringValue(constlabels.ProcessUser, info.User)
+	if !info.StartTime.IsZero() {
+		labelMap.UpdateAddIntValue(constlabels.ProcessStartTime, info.StartTime.Unix())
+	}
+}
+
+// fillWorkload sets the workload of the process if it runs outside the containers, which is
+// the server if the data is from the server side and the client otherwise.
+func fillWorkload(labelMap *model.AttributeMap, info *process.Info) {
+	if labelMap.GetStringValue(constlabels.ContainerId) != "" {
+		return
+	}
+	workloadKind, workloadName := constlabels.SrcWorkloadKind, constlabels.SrcWorkloadName
+	if labelMap.GetBoolValue(constlabels.IsServer) {
+		workloadKind, workloadName = constlabels.DstWorkloadKind, constlabels.DstWorkloadName
+	}
+	if labelMap.GetStringValue(workloadName) != "" {
+		return
+	}
+	if info.SystemdUnit != "" {
+		labelMap.UpdateAddStringValue(workloadKind, SystemdKind)
+		labelMap.UpdateAddStringValue(workloadName, info.SystemdUnit)
+		return
+	}
+	executable := info.Exe
+	if executable == "" {
+		executable = strings.SplitN(info.Cmdline, " ", 2)[0]
+	}
+	if executable == "" {
+		return
+	}
+	labelMap.UpdateAddStringValue(workloadKind, ProcessKind)
+	labelMap.UpdateAddStringValue(workloadName, filepath.Base(executable))
+}
@@ -0,0 +1,96 @@
+package processmetadataprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/process"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type collector struct {
+	dataGroups []*model.DataGroup
+}
+
+func (c *collector) Consume(dataGroup *model.DataGroup) error {
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// newProcessor creates the processor reading a proc filesystem containing a process managed
+// by systemd with pid 100 and a process started in a shell with pid 200.
+func newProcessor(t *testing.T, next *collector) *ProcessMetadataProcessor {
+	procRoot := t.TempDir()
+	writeFile(t, filepath.Join(procRoot, "stat"), "btime 1697600000\n")
+	writeFile(t, filepath.Join(procRoot, "100", "cmdline"), "/usr/sbin/mysqld\x00")
+	writeFile(t, filepath.Join(procRoot, "100", "cgroup"), "0::/system.slice/mysql.service\n")
+	writeFile(t, filepath.Join(procRoot, "100", "status"), "Uid:\t0\t0\t0\t0\n")
+	writeFile(t, filepath.Join(procRoot, "100", "stat"), "100 (mysqld) S 1 100 100 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 200 0")
+	writeFile(t, filepath.Join(procRoot, "200", "cmdline"), "/opt/app/bin/backup\x00--all\x00")
+	writeFile(t, filepath.Join(procRoot, "200", "cgroup"), "0::/\n")
+	cache, err := process.NewCache(procRoot, 10, 0)
+	require.NoError(t, err)
+	return &ProcessMetadataProcessor{
+		cfg:          NewDefaultConfig(),
+		telemetry:    component.NewDefaultTelemetryTools(),
+		nextConsumer: next,
+		cache:        cache,
+	}
+}
+
+func newDataGroup(pid int64, isServer bool, containerId string) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, pid)
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	labels.AddStringValue(constlabels.ContainerId, containerId)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0)
+}
+
+func TestProcessMetadataProcessor_Consume(t *testing.T) {
+	next := &collector{}
+	p := newProcessor(t, next)
+
+	_ = p.Consume(newDataGroup(100, true, ""))
+	_ = p.Consume(newDataGroup(200, false, ""))
+	_ = p.Consume(newDataGroup(100, true, "1a2b3c4d5e6f"))
+	_ = p.Consume(newDataGroup(300, true, ""))
+	require.Len(t, next.dataGroups, 4)
+
+	labels := next.dataGroups[0].Labels
+	assert.Equal(t, "/usr/sbin/mysqld", labels.GetStringValue(constlabels.ProcessCmdline))
+	assert.Equal(t, "/system.slice/mysql.service", labels.GetStringValue(constlabels.ProcessCgroup))
+	assert.Equal(t, "mysql.service", labels.GetStringValue(constlabels.ProcessSystemdUnit))
+	assert.Equal(t, "0", labels.GetStringValue(constlabels.ProcessUser))
+	assert.Equal(t, int64(1697600002), labels.GetIntValue(constlabels.ProcessStartTime))
+	assert.Equal(t, SystemdKind, labels.GetStringValue(constlabels.DstWorkloadKind))
+	assert.Equal(t, "mysql.service", labels.GetStringValue(constlabels.DstWorkloadName))
+
+	// The client is named by its executable.
+	labels = next.dataGroups[1].Labels
+	assert.Equal(t, "/opt/app/bin/backup --all", labels.GetStringValue(constlabels.ProcessCmdline))
+	assert.Equal(t, ProcessKind, labels.GetStringValue(constlabels.SrcWorkloadKind))
+	assert.Equal(t, "backup", labels.GetStringValue(constlabels.SrcWorkloadName))
+	assert.False(t, labels.HasAttribute(constlabels.ProcessStartTime))
+
+	// The workload of the containers is left to the other processors.
+	labels = next.dataGroups[2].Labels
+	assert.Equal(t, "mysql.service", labels.GetStringValue(constlabels.ProcessSystemdUnit))
+	assert.False(t, labels.HasAttribute(constlabels.DstWorkloadName))
+
+	// The process not found.
+	labels = next.dataGroups[3].Labels
+	assert.False(t, labels.HasAttribute(constlabels.ProcessCmdline))
+	assert.False(t, labels.HasAttribute(constlabels.DstWorkloadName))
+}
@@ -0,0 +1,46 @@
+package relabelprocessor
+
+const (
+	KeepAction      = "keep"
+	DropAction      = "drop"
+	ReplaceAction   = "replace"
+	CopyAction      = "copy"
+	RenameAction    = "rename"
+	HashAction      = "hash"
+	LabelDropAction = "labeldrop"
+)
+
+type Config struct {
+	// Rules are applied to each data group in order. The data group is not passed to the next
+	// consumer once it is dropped by any rule.
+	Rules []RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig follows the relabel_config of Prometheus, with an additional "if" expression.
+type RuleConfig struct {
+	// Action is one of "keep", "drop", "replace", "copy", "rename", "hash" and "labeldrop".
+	Action string `mapstructure:"action"`
+	// If is a boolean expression like `dst_namespace == "kube-system"`. The rule is only applied to the
+	// data groups matching it. See the package expression for the syntax.
+	If string `mapstructure:"if"`
+	// SourceLabels are the labels whose values are joined with Separator and matched against Regex.
+	SourceLabels []string `mapstructure:"source_labels"`
+	// Separator is ";" by default.
+	Separator string `mapstructure:"separator"`
+	// Regex is fully anchored. It is "(.*)" by default. For "labeldrop", it is matched against the
+	// label names instead.
+	Regex string `mapstructure:"regex"`
+	// TargetLabel is the label written by "replace", "copy", "rename" and "hash".
+	TargetLabel string `mapstructure:"target_label"`
+	// Replacement is the value written by "replace". The capture groups of Regex could be referred
+	// as "$1". It is "$1" by default.
+	Replacement string `mapstructure:"replacement"`
+	// Modulus is used by "hash". The hash is taken modulo it if it is positive.
+	Modulus uint64 `mapstructure:"modulus"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Rules: []RuleConfig{},
+	}
+}
@@ -0,0 +1,55 @@
+package relabelprocessor
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const Type = "relabelprocessor"
+
+// RelabelProcessor filters the data groups and modifies their labels with the Prometheus-style relabel
+// rules, e.g. dropping the requests to "kube-system", keeping only the HTTP and gRPC requests, or hashing
+// the sensitive labels. The labels are modified in place.
+// It is placed after aggregateprocessor, which drops the labels not in its selectors, including the ones
+// written by the rules.
+type RelabelProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	rules []*rule
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Type))
+	}
+	p := &RelabelProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		rules:        make([]*rule, 0, len(cfg.Rules)),
+	}
+	for i := range cfg.Rules {
+		r, err := newRule(&cfg.Rules[i])
+		if err != nil {
+			telemetry.Logger.Panic("Invalid relabel rule", zap.String("componentType", Type), zap.Int("index", i), zap.Error(err))
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p
+}
+
+func (p *RelabelProcessor) Consume(dataGroup *model.DataGroup) error {
+	for _, r := range p.rules {
+		if !r.apply(dataGroup) {
+			return nil
+		}
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
@@ -0,0 +1,112 @@
+package relabelprocessor
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type collector struct {
+	dataGroups []*model.DataGroup
+}
+
+func (c *collector) Consume(dataGroup *model.DataGroup) error {
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func newDataGroup(namespace string, protocol string, port int64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.DstNamespace, namespace)
+	labels.AddStringValue(constlabels.DstPod, "pod-1")
+	labels.AddStringValue(constlabels.Protocol, protocol)
+	labels.AddIntValue(constlabels.DstPort, port)
+	labels.AddStringValue(constlabels.ContentKey, "/api/users/123")
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	return model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, 0)
+}
+
+func TestRelabelProcessor_Filter(t *testing.T) {
+	next := &collector{}
+	p := New(&Config{Rules: []RuleConfig{
+		{Action: DropAction, If: `dst_namespace == "kube-system"`},
+		{Action: KeepAction, If: `protocol in [http, grpc]`},
+		{Action: DropAction, SourceLabels: []string{constlabels.Protocol, constlabels.DstPort}, Regex: "grpc;9.*"},
+	}}, component.NewDefaultTelemetryTools(), next).(*RelabelProcessor)
+	assert.Len(t, p.rules, 3)
+
+	_ = p.Consume(newDataGroup("kube-system", "http", 80))
+	_ = p.Consume(newDataGroup("default", "mysql", 3306))
+	_ = p.Consume(newDataGroup("default", "grpc", 9090))
+	_ = p.Consume(newDataGroup("default", "grpc", 8080))
+	_ = p.Consume(newDataGroup("default", "http", 80))
+	if assert.Len(t, next.dataGroups, 2) {
+		assert.Equal(t, int64(8080), next.dataGroups[0].Labels.GetIntValue(constlabels.DstPort))
+		assert.Equal(t, "http", next.dataGroups[1].Labels.GetStringValue(constlabels.Protocol))
+	}
+}
+
+func TestRelabelProcessor_Labels(t *testing.T) {
+	next := &collector{}
+	p := New(&Config{Rules: []RuleConfig{
+		{Action: ReplaceAction, SourceLabels: []string{constlabels.ContentKey}, Regex: "(/api/[^/]+)/.*",
+			TargetLabel: constlabels.ContentKey, Replacement: "$1/*"},
+		{Action: CopyAction, SourceLabels: []string{constlabels.DstPort}, TargetLabel: "port"},
+		{Action: RenameAction, SourceLabels: []string{constlabels.DstPod}, TargetLabel: "pod"},
+		{Action: HashAction, SourceLabels: []string{constlabels.SrcIp}},
+		{Action: HashAction, SourceLabels: []string{constlabels.DstNamespace, "pod"}, TargetLabel: "shard", Modulus: 4},
+		{Action: LabelDropAction, Regex: "dst_.*"},
+		{Action: ReplaceAction, If: `protocol == "grpc"`, TargetLabel: "rpc", Replacement: "true"},
+	}}, component.NewDefaultTelemetryTools(), next)
+
+	_ = p.Consume(newDataGroup("default", "http", 80))
+	labels := next.dataGroups[0].Labels
+	assert.Equal(t, "/api/users/*", labels.GetStringValue(constlabels.ContentKey))
+	assert.Equal(t, int64(80), labels.GetIntValue("port"))
+	assert.Equal(t, "pod-1", labels.GetStringValue("pod"))
+	assert.Equal(t, strconv.FormatUint(hash("10.0.0.1"), 16), labels.GetStringValue(constlabels.SrcIp))
+	assert.Equal(t, strconv.FormatUint(hash("default;pod-1")%4, 10), labels.GetStringValue("shard"))
+	assert.False(t, labels.HasAttribute(constlabels.DstNamespace))
+	assert.False(t, labels.HasAttribute(constlabels.DstPod))
+	assert.False(t, labels.HasAttribute(constlabels.DstPort))
+	assert.False(t, labels.HasAttribute("rpc"))
+	assert.Equal(t, 6, labels.Size())
+}
+
+func TestRelabelProcessor_HashKeepsType(t *testing.T) {
+	next := &collector{}
+	p := New(&Config{Rules: []RuleConfig{
+		{Action: HashAction, SourceLabels: []string{constlabels.DstPort}},
+		{Action: HashAction, SourceLabels: []string{constlabels.DstPod}, TargetLabel: constlabels.SrcPort, Modulus: 4},
+	}}, component.NewDefaultTelemetryTools(), next)
+
+	group := newDataGroup("default", "http", 80)
+	group.Labels.AddIntValue(constlabels.SrcPort, 50000)
+	_ = p.Consume(group)
+	labels := next.dataGroups[0].Labels
+	assert.Equal(t, int64(hash("80")&math.MaxInt64), labels.GetIntValue(constlabels.DstPort))
+	assert.Equal(t, int64(hash("pod-1")%4), labels.GetIntValue(constlabels.SrcPort))
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() {
+		New(&struct{}{}, component.NewDefaultTelemetryTools(), &collector{})
+	})
+	// The invalid rules are not ignored, or the data expected to be dropped would be exported.
+	for _, rule := range []RuleConfig{
+		{Action: DropAction, SourceLabels: []string{constlabels.Protocol}, Regex: "grpc(;9.*"},
+		{Action: "unknown"},
+		{Action: KeepAction},
+	} {
+		assert.Panics(t, func() {
+			New(&Config{Rules: []RuleConfig{rule}}, component.NewDefaultTelemetryTools(), &collector{})
+		}, rule.Action)
+	}
+}
@@ -0,0 +1,203 @@
+package relabelprocessor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/expression"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const (
+	defaultSeparator   = ";"
+	defaultRegex       = "(.*)"
+	defaultReplacement = "$1"
+)
+
+type rule struct {
+	action       string
+	condition    *expression.Expression
+	sourceLabels []string
+	separator    string
+	regex        *regexp.Regexp
+	// hasRegex is true if the regex is configured instead of the default one.
+	hasRegex    bool
+	targetLabel string
+	replacement string
+	modulus     uint64
+}
+
+func newRule(cfg *RuleConfig) (*rule, error) {
+	r := &rule{
+		action:       cfg.Action,
+		sourceLabels: cfg.SourceLabels,
+		separator:    cfg.Separator,
+		hasRegex:     cfg.Regex != "",
+		targetLabel:  cfg.TargetLabel,
+		replacement:  cfg.Replacement,
+		modulus:      cfg.Modulus,
+	}
+	if cfg.If != "" {
+		condition, err := expression.Compile(cfg.If)
+		if err != nil {
+			return nil, fmt.Errorf("action %q: %w", cfg.Action, err)
+		}
+		r.condition = condition
+	}
+	if r.separator == "" {
+		r.separator = defaultSeparator
+	}
+	pattern := cfg.Regex
+	if pattern == "" {
+		pattern = defaultRegex
+	}
+	regex, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("action %q: invalid regex: %w", cfg.Action, err)
+	}
+	r.regex = regex
+	if r.replacement == "" {
+		r.replacement = defaultReplacement
+	}
+
+	switch cfg.Action {
+	case KeepAction, DropAction:
+		if r.condition == nil && len(r.sourceLabels) == 0 {
+			return nil, fmt.Errorf("action %q: either if or source_labels is required", cfg.Action)
+		}
+	case ReplaceAction:
+		if r.targetLabel == "" {
+			return nil, fmt.Errorf("action %q: target_label is required", cfg.Action)
+		}
+	case CopyAction, RenameAction:
+		if len(r.sourceLabels) != 1 || r.targetLabel == "" {
+			return nil, fmt.Errorf("action %q: exactly one source label and target_label are required", cfg.Action)
+		}
+	case HashAction:
+		if len(r.sourceLabels) == 0 {
+			return nil, fmt.Errorf("action %q: source_labels is required", cfg.Action)
+		}
+		if r.modulus > 0 && r.targetLabel == "" {
+			return nil, fmt.Errorf("action %q: target_label is required when modulus is set", cfg.Action)
+		}
+	case LabelDropAction:
+		if !r.hasRegex && len(r.sourceLabels) == 0 {
+			return nil, fmt.Errorf("action %q: either regex or source_labels is required", cfg.Action)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q", cfg.Action)
+	}
+	return r, nil
+}
+
+// apply modifies the labels of the data group in place, and returns false if it should be dropped.
+func (r *rule) apply(g *model.DataGroup) bool {
+	if r.condition != nil && !r.condition.Match(g) {
+		// The data groups not matching the condition are dropped by the "keep" rules.
+		return r.action != KeepAction
+	}
+	switch r.action {
+	case KeepAction:
+		return len(r.sourceLabels) == 0 || r.regex.MatchString(r.joinSourceValues(g.Labels))
+	case DropAction:
+		return len(r.sourceLabels) > 0 && !r.regex.MatchString(r.joinSourceValues(g.Labels))
+	case ReplaceAction:
+		source := r.joinSourceValues(g.Labels)
+		indexes := r.regex.FindStringSubmatchIndex(source)
+		if indexes == nil {
+			return true
+		}
+		target := string(r.regex.ExpandString(nil, r.replacement, source, indexes))
+		if target == "" {
+			g.Labels.RemoveAttribute(r.targetLabel)
+		} else {
+			g.Labels.AddStringValue(r.targetLabel, target)
+		}
+	case CopyAction:
+		copyLabel(g.Labels, r.sourceLabels[0], r.targetLabel)
+	case RenameAction:
+		if copyLabel(g.Labels, r.sourceLabels[0], r.targetLabel) && r.sourceLabels[0] != r.targetLabel {
+			g.Labels.RemoveAttribute(r.sourceLabels[0])
+		}
+	case HashAction:
+		if r.targetLabel == "" {
+			for _, label := range r.sourceLabels {
+				if g.Labels.HasAttribute(label) {
+					setHash(g.Labels, label, hash(labelValue(g.Labels, label)), 16)
+				}
+			}
+			return true
+		}
+		sum := hash(r.joinSourceValues(g.Labels))
+		if r.modulus > 0 {
+			setHash(g.Labels, r.targetLabel, sum%r.modulus, 10)
+		} else {
+			setHash(g.Labels, r.targetLabel, sum, 16)
+		}
+	case LabelDropAction:
+		for _, label := range r.sourceLabels {
+			g.Labels.RemoveAttribute(label)
+		}
+		if r.hasRegex {
+			for name := range g.Labels.GetValues() {
+				if r.regex.MatchString(name) {
+					g.Labels.RemoveAttribute(name)
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (r *rule) joinSourceValues(labels *model.AttributeMap) string {
+	values := make([]string, len(r.sourceLabels))
+	for i, label := range r.sourceLabels {
+		values[i] = labelValue(labels, label)
+	}
+	return strings.Join(values, r.separator)
+}
+
+func labelValue(labels *model.AttributeMap, label string) string {
+	if value, ok := labels.GetValues()[label]; ok {
+		return value.ToString()
+	}
+	return ""
+}
+
+// copyLabel copies the value of the source label with its type, and returns false if the source
+// label doesn't exist.
+func copyLabel(labels *model.AttributeMap, source string, target string) bool {
+	value, ok := labels.GetValues()[source]
+	if !ok {
+		return false
+	}
+	switch value.Type() {
+	case model.IntAttributeValueType:
+		labels.AddIntValue(target, labels.GetIntValue(source))
+	case model.BooleanAttributeValueType:
+		labels.AddBoolValue(target, labels.GetBoolValue(source))
+	default:
+		labels.AddStringValue(target, labels.GetStringValue(source))
+	}
+	return true
+}
+
+// setHash writes the hash to the label keeping its type if it exists. The hash of an int label is an int
+// without the sign bit, and a new label is a string formatted in the base.
+func setHash(labels *model.AttributeMap, label string, sum uint64, base int) {
+	if value, ok := labels.GetValues()[label]; ok && value.Type() == model.IntAttributeValueType {
+		labels.AddIntValue(label, int64(sum&math.MaxInt64))
+		return
+	}
+	labels.AddStringValue(label, strconv.FormatUint(sum, base))
+}
+
+func hash(value string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(value))
+	return h.Sum64()
+}
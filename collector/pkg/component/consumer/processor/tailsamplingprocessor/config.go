@@ -0,0 +1,44 @@
+package tailsamplingprocessor
+
+const (
+	LatencyPolicy    = "latency"
+	ErrorPolicy      = "error"
+	ContentKeyPolicy = "content_key"
+	RateLimitPolicy  = "rate_limit"
+)
+
+type Config struct {
+	// DecisionWait is how long the traces of a group are buffered before deciding whether to keep
+	// them. The unit is second.
+	DecisionWait int `mapstructure:"decision_wait"`
+	// MaxGroups is the maximum number of groups buffered. The oldest group is decided in advance
+	// when it is exceeded.
+	MaxGroups int            `mapstructure:"max_groups"`
+	Policies  []PolicyConfig `mapstructure:"policies"`
+}
+
+type PolicyConfig struct {
+	Name string `mapstructure:"name"`
+	// Type is one of "latency", "error", "content_key" and "rate_limit".
+	Type string `mapstructure:"type"`
+	// ThresholdMs is used by the latency policy. The group is kept if any of its traces takes
+	// longer than it. The unit is millisecond.
+	ThresholdMs int64 `mapstructure:"threshold_ms"`
+	// ContentKeys are used by the content_key policy. The group is kept if the content_key of any
+	// of its traces is one of them.
+	ContentKeys []string `mapstructure:"content_keys"`
+	// GroupsPerSecond is used by the rate_limit policy. At most GroupsPerSecond groups are kept
+	// per second for each endpoint, which is the content_key of the first trace in the group.
+	GroupsPerSecond int `mapstructure:"groups_per_second"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		DecisionWait: 10,
+		MaxGroups:    50000,
+		Policies: []PolicyConfig{
+			{Name: "slow", Type: LatencyPolicy, ThresholdMs: 500},
+			{Name: "error", Type: ErrorPolicy},
+		},
+	}
+}
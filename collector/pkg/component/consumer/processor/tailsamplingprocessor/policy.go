@@ -0,0 +1,100 @@
+package tailsamplingprocessor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// policy decides whether a group of traces should be kept.
+type policy interface {
+	sample(group *traceGroup, now time.Time) bool
+}
+
+func newPolicy(cfg *PolicyConfig) (policy, error) {
+	switch cfg.Type {
+	case LatencyPolicy:
+		if cfg.ThresholdMs <= 0 {
+			return nil, fmt.Errorf("policy %q: threshold_ms must be positive", cfg.Name)
+		}
+		return &latencyPolicy{threshold: cfg.ThresholdMs * int64(time.Millisecond)}, nil
+	case ErrorPolicy:
+		return &errorPolicy{}, nil
+	case ContentKeyPolicy:
+		if len(cfg.ContentKeys) == 0 {
+			return nil, fmt.Errorf("policy %q: content_keys is empty", cfg.Name)
+		}
+		p := &contentKeyPolicy{contentKeys: make(map[string]bool)}
+		for _, key := range cfg.ContentKeys {
+			p.contentKeys[key] = true
+		}
+		return p, nil
+	case RateLimitPolicy:
+		if cfg.GroupsPerSecond <= 0 {
+			return nil, fmt.Errorf("policy %q: groups_per_second must be positive", cfg.Name)
+		}
+		return &rateLimitPolicy{limit: cfg.GroupsPerSecond, counts: make(map[string]int)}, nil
+	default:
+		return nil, fmt.Errorf("policy %q: unknown type %q", cfg.Name, cfg.Type)
+	}
+}
+
+type latencyPolicy struct {
+	// The unit is nanosecond.
+	threshold int64
+}
+
+func (p *latencyPolicy) sample(group *traceGroup, _ time.Time) bool {
+	for _, trace := range group.traces {
+		if metric, ok := trace.GetMetric(constvalues.RequestTotalTime); ok && metric.GetInt().Value > p.threshold {
+			return true
+		}
+	}
+	return false
+}
+
+type errorPolicy struct{}
+
+func (p *errorPolicy) sample(group *traceGroup, _ time.Time) bool {
+	for _, trace := range group.traces {
+		if trace.Labels.GetBoolValue(constlabels.IsError) {
+			return true
+		}
+	}
+	return false
+}
+
+type contentKeyPolicy struct {
+	contentKeys map[string]bool
+}
+
+func (p *contentKeyPolicy) sample(group *traceGroup, _ time.Time) bool {
+	for _, trace := range group.traces {
+		if p.contentKeys[trace.Labels.GetStringValue(constlabels.ContentKey)] {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitPolicy keeps at most limit groups per second for each endpoint.
+type rateLimitPolicy struct {
+	limit  int
+	second int64
+	counts map[string]int
+}
+
+func (p *rateLimitPolicy) sample(group *traceGroup, now time.Time) bool {
+	if second := now.Unix(); second != p.second {
+		p.second = second
+		p.counts = make(map[string]int)
+	}
+	endpoint := group.traces[0].Labels.GetStringValue(constlabels.ContentKey)
+	if p.counts[endpoint] >= p.limit {
+		return false
+	}
+	p.counts[endpoint]++
+	return true
+}
@@ -0,0 +1,178 @@
+package tailsamplingprocessor
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type = "tailsamplingprocessor"
+
+// TailSamplingProcessor buffers the single request traces for a decision window and keeps or drops
+// them in groups, so the related hops of one slow request are kept together. The traces are grouped
+// by their trace ids from APM, or by their connections if there is no trace id.
+// The policies are evaluated in order and a group is kept once any of them samples it. All the
+// other data groups are passed through.
+//
+// It should be placed after aggregateprocessor, whose sampling_rate should keep all the traces.
+type TailSamplingProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	policies []policy
+	mutex    sync.Mutex
+	groups   map[string]*traceGroup
+	// queue holds the keys of the groups in the order they arrived.
+	queue    []string
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+type traceGroup struct {
+	arrival time.Time
+	traces  []*model.DataGroup
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Type))
+	}
+	p, err := newTailSamplingProcessor(cfg, telemetry, nextConsumer)
+	if err != nil {
+		telemetry.Logger.Panic("Invalid tail sampling policy", zap.String("componentType", Type), zap.Error(err))
+	}
+	go p.runTicker()
+	return p
+}
+
+// newTailSamplingProcessor returns an error if any policy is invalid, so no traces are dropped by
+// a policy ignored silently.
+func newTailSamplingProcessor(cfg *Config, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) (*TailSamplingProcessor, error) {
+	p := &TailSamplingProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		policies:     make([]policy, 0, len(cfg.Policies)),
+		groups:       make(map[string]*traceGroup),
+		queue:        make([]string, 0),
+		stopCh:       make(chan struct{}),
+	}
+	for i := range cfg.Policies {
+		policy, err := newPolicy(&cfg.Policies[i])
+		if err != nil {
+			return nil, err
+		}
+		p.policies = append(p.policies, policy)
+	}
+	return p, nil
+}
+
+func (p *TailSamplingProcessor) runTicker() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case now := <-ticker.C:
+			p.decide(now)
+		}
+	}
+}
+
+func (p *TailSamplingProcessor) Consume(dataGroup *model.DataGroup) error {
+	if dataGroup.Name != constnames.SingleNetRequestMetricGroup {
+		return p.nextConsumer.Consume(dataGroup)
+	}
+	key := groupKey(dataGroup.Labels)
+	now := time.Now()
+	p.mutex.Lock()
+	group, ok := p.groups[key]
+	if !ok {
+		group = &traceGroup{arrival: now, traces: make([]*model.DataGroup, 0, 1)}
+		p.groups[key] = group
+		p.queue = append(p.queue, key)
+	}
+	// The data group is reused by the previous components after it is consumed.
+	group.traces = append(group.traces, dataGroup.Clone())
+	var evicted []*model.DataGroup
+	if p.cfg.MaxGroups > 0 && len(p.groups) > p.cfg.MaxGroups {
+		evicted = p.decideOldest(now)
+	}
+	p.mutex.Unlock()
+	return p.send(evicted)
+}
+
+// groupKey returns the trace id of APM if exists, otherwise the connection of the trace.
+func groupKey(labels *model.AttributeMap) string {
+	if traceId := labels.GetStringValue(constlabels.HttpApmTraceId); traceId != "" {
+		return traceId
+	}
+	return labels.GetStringValue(constlabels.SrcIp) + ":" + strconv.FormatInt(labels.GetIntValue(constlabels.SrcPort), 10) +
+		"->" + labels.GetStringValue(constlabels.DstIp) + ":" + strconv.FormatInt(labels.GetIntValue(constlabels.DstPort), 10)
+}
+
+// decide sends the traces of the groups whose decision window has passed if they are sampled.
+func (p *TailSamplingProcessor) decide(now time.Time) {
+	deadline := now.Add(-time.Duration(p.cfg.DecisionWait) * time.Second)
+	kept := make([]*model.DataGroup, 0)
+	p.mutex.Lock()
+	for len(p.queue) > 0 && !p.groups[p.queue[0]].arrival.After(deadline) {
+		kept = append(kept, p.decideOldest(now)...)
+	}
+	p.mutex.Unlock()
+	if err := p.send(kept); err != nil {
+		p.telemetry.Logger.Warn("Error happened when consuming sampled traces", zap.Error(err))
+	}
+}
+
+// decideOldest removes the oldest group and returns its traces if it is sampled.
+// The mutex must be held.
+func (p *TailSamplingProcessor) decideOldest(now time.Time) []*model.DataGroup {
+	key := p.queue[0]
+	p.queue = p.queue[1:]
+	group := p.groups[key]
+	delete(p.groups, key)
+	for _, policy := range p.policies {
+		if policy.sample(group, now) {
+			return group.traces
+		}
+	}
+	return nil
+}
+
+// Shutdown stops the ticker and sends the sampled traces of all the groups waiting for their decisions.
+func (p *TailSamplingProcessor) Shutdown() error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+	now := time.Now()
+	kept := make([]*model.DataGroup, 0)
+	p.mutex.Lock()
+	for len(p.queue) > 0 {
+		kept = append(kept, p.decideOldest(now)...)
+	}
+	p.mutex.Unlock()
+	return p.send(kept)
+}
+
+func (p *TailSamplingProcessor) send(traces []*model.DataGroup) error {
+	var retErr error
+	for _, trace := range traces {
+		if err := p.nextConsumer.Consume(trace); err != nil {
+			retErr = err
+		}
+	}
+	return retErr
+}
@@ -0,0 +1,137 @@
+package tailsamplingprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type collector struct {
+	dataGroups []*model.DataGroup
+}
+
+func (c *collector) Consume(dataGroup *model.DataGroup) error {
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func (c *collector) contentKeys() []string {
+	keys := make([]string, 0)
+	for _, dataGroup := range c.dataGroups {
+		keys = append(keys, dataGroup.Labels.GetStringValue(constlabels.ContentKey))
+	}
+	return keys
+}
+
+func newTrace(traceId string, srcPort int64, contentKey string, durationMs int64, isError bool) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.HttpApmTraceId, traceId)
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.SrcPort, srcPort)
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	labels.AddStringValue(constlabels.ContentKey, contentKey)
+	labels.AddBoolValue(constlabels.IsError, isError)
+	return model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 0,
+		model.NewIntMetric(constvalues.RequestTotalTime, durationMs*int64(time.Millisecond)))
+}
+
+func TestTailSampling(t *testing.T) {
+	next := &collector{}
+	p, err := newTailSamplingProcessor(&Config{
+		DecisionWait: 10,
+		Policies: []PolicyConfig{
+			{Name: "slow", Type: LatencyPolicy, ThresholdMs: 500},
+			{Name: "error", Type: ErrorPolicy},
+			{Name: "login", Type: ContentKeyPolicy, ContentKeys: []string{"/login"}},
+			{Name: "sample", Type: RateLimitPolicy, GroupsPerSecond: 1},
+		},
+	}, component.NewDefaultTelemetryTools(), next)
+	assert.NoError(t, err)
+	assert.Len(t, p.policies, 4)
+
+	// The fast hop of a slow request is kept together with the slow one.
+	_ = p.Consume(newTrace("trace-1", 1000, "/gateway", 600, false))
+	_ = p.Consume(newTrace("trace-1", 1001, "/order", 10, false))
+	// The groups without trace ids are grouped by connections.
+	_ = p.Consume(newTrace("", 2000, "/login", 10, false))
+	_ = p.Consume(newTrace("", 2001, "/pay", 10, true))
+	// Only one group of /user is kept by the rate limit.
+	_ = p.Consume(newTrace("trace-2", 3000, "/user", 10, false))
+	_ = p.Consume(newTrace("trace-3", 3001, "/user", 10, false))
+	// The other data groups are passed through.
+	_ = p.Consume(model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, model.NewAttributeMap(), 0))
+	assert.Len(t, next.dataGroups, 1)
+
+	p.decide(time.Now())
+	assert.Len(t, next.dataGroups, 1)
+	p.decide(time.Now().Add(11 * time.Second))
+	assert.Equal(t, []string{"", "/gateway", "/order", "/login", "/pay", "/user"}, next.contentKeys())
+	assert.Empty(t, p.groups)
+	assert.Empty(t, p.queue)
+}
+
+func TestTailSamplingMaxGroups(t *testing.T) {
+	next := &collector{}
+	p, err := newTailSamplingProcessor(&Config{
+		DecisionWait: 10,
+		MaxGroups:    2,
+		Policies:     []PolicyConfig{{Name: "error", Type: ErrorPolicy}},
+	}, component.NewDefaultTelemetryTools(), next)
+	assert.NoError(t, err)
+
+	_ = p.Consume(newTrace("trace-1", 1000, "/a", 10, true))
+	_ = p.Consume(newTrace("trace-2", 1001, "/b", 10, false))
+	_ = p.Consume(newTrace("trace-3", 1002, "/c", 10, true))
+	// The oldest group is decided in advance.
+	assert.Equal(t, []string{"/a"}, next.contentKeys())
+	_ = p.Consume(newTrace("trace-4", 1003, "/d", 10, true))
+	assert.Equal(t, []string{"/a"}, next.contentKeys())
+	assert.Len(t, p.groups, 2)
+}
+
+func TestTailSamplingShutdown(t *testing.T) {
+	next := &collector{}
+	p := New(&Config{
+		DecisionWait: 10,
+		Policies:     []PolicyConfig{{Name: "error", Type: ErrorPolicy}},
+	}, component.NewDefaultTelemetryTools(), next).(*TailSamplingProcessor)
+
+	_ = p.Consume(newTrace("trace-1", 1000, "/a", 10, true))
+	_ = p.Consume(newTrace("trace-2", 1001, "/b", 10, false))
+	// The groups waiting for their decisions are decided at once.
+	assert.NoError(t, p.Shutdown())
+	assert.Equal(t, []string{"/a"}, next.contentKeys())
+	assert.Empty(t, p.groups)
+	_, open := <-p.stopCh
+	assert.False(t, open)
+	// Shutting down again doesn't panic.
+	assert.NoError(t, p.Shutdown())
+}
+
+func TestTailSamplingInvalidPolicy(t *testing.T) {
+	for _, policy := range []PolicyConfig{
+		{Name: "unknown", Type: "unknown"},
+		{Name: "slow", Type: LatencyPolicy},
+		{Name: "login", Type: ContentKeyPolicy},
+	} {
+		_, err := newTailSamplingProcessor(&Config{
+			DecisionWait: 10,
+			Policies:     []PolicyConfig{{Name: "error", Type: ErrorPolicy}, policy},
+		}, component.NewDefaultTelemetryTools(), &collector{})
+		assert.Error(t, err, policy.Name)
+	}
+	assert.Panics(t, func() {
+		New(&Config{
+			DecisionWait: 10,
+			Policies:     []PolicyConfig{{Name: "unknown", Type: "unknown"}},
+		}, component.NewDefaultTelemetryTools(), &collector{})
+	})
+}
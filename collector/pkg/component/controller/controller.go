@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
 )
 
 type ControllerAPI interface {
@@ -42,6 +43,8 @@ type ControlResponse struct {
 
 type ControllerFactory struct {
 	Controller ControllerAPI
+	// modules are the modules served by the controller.
+	modules map[string]bool
 }
 
 type ControllerConfig struct {
@@ -60,11 +63,17 @@ func (cf *ControllerFactory) ConstructConfig(viper *viper.Viper, tools *componen
 	}
 	if controllerConfig.Http != nil {
 		httpAPI := NewHttpAPI(tools)
+		cf.modules = make(map[string]bool, len(controllerConfig.Modules))
 		for _, module := range controllerConfig.Modules {
+			cf.modules[module] = true
 			switch module {
 			case ProfileModule:
 				profileController := NewProfileController(tools)
 				httpAPI.RegistController(profileController)
+			case UrlClusteringModule:
+				httpAPI.RegistController(NewUrlClusteringController())
+			case TapModule:
+				httpAPI.Handle(TapPath, NewTapHandler(tap.GetHub(), tools))
 			}
 		}
 		go http.ListenAndServe(controllerConfig.Http.Port, httpAPI)
@@ -73,6 +82,11 @@ func (cf *ControllerFactory) ConstructConfig(viper *viper.Viper, tools *componen
 	return nil
 }
 
+// HasModule returns true if the module is served by the controller.
+func (cf *ControllerFactory) HasModule(module string) bool {
+	return cf.modules[module]
+}
+
 func (cf *ControllerFactory) RegistModule(module string, subModules ...ExportSubModule) {
 	cf.Controller.RegistModule(module, subModules...)
 }
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
+)
+
+const (
+	TapModule = "tap"
+	TapPath   = "/debug/tap"
+
+	defaultTapLimit    = 100
+	defaultTapDuration = time.Minute
+	tapBufferSize      = 1024
+)
+
+// TapHandler streams the raw events or the data groups matching the filters of the query as
+// newline-delimited JSON, or server-sent events if "format=sse" or the client accepts
+// "text/event-stream". The query parameters are:
+//
+//	type:         "event" (default) or "datagroup"
+//	pid, container_id, port, protocol, name: the filters, see tap.Filter
+//	sample_rate:  the ratio of the matched data to stream, e.g. 0.1
+//	limit:        the max number of the data to stream, 100 by default and 0 means no limit
+//	duration:     how long to stream, e.g. "30s", 1m by default
+//
+// The stream ends when any of limit and duration is reached or the client disconnects.
+type TapHandler struct {
+	hub   *tap.Hub
+	tools *component.TelemetryTools
+}
+
+func NewTapHandler(hub *tap.Hub, tools *component.TelemetryTools) *TapHandler {
+	return &TapHandler{hub: hub, tools: tools}
+}
+
+type tapRequest struct {
+	kind     tap.Kind
+	filter   *tap.Filter
+	limit    int
+	duration time.Duration
+	sse      bool
+}
+
+func parseTapRequest(r *http.Request) (*tapRequest, error) {
+	query := r.URL.Query()
+	req := &tapRequest{
+		kind:     tap.EventKind,
+		filter:   &tap.Filter{},
+		limit:    defaultTapLimit,
+		duration: defaultTapDuration,
+		sse:      query.Get("format") == "sse" || r.Header.Get("Accept") == "text/event-stream",
+	}
+	switch kind := tap.Kind(query.Get("type")); kind {
+	case "", tap.EventKind:
+	case tap.DataGroupKind:
+		req.kind = kind
+	default:
+		return nil, fmt.Errorf("unknown type %q, valid types are %q and %q", kind, tap.EventKind, tap.DataGroupKind)
+	}
+	if format := query.Get("format"); format != "" && format != "sse" && format != "ndjson" {
+		return nil, fmt.Errorf("unknown format %q, valid formats are \"ndjson\" and \"sse\"", format)
+	}
+	var err error
+	if v := query.Get("pid"); v != "" {
+		pid, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid %q: %w", v, err)
+		}
+		req.filter.Pid = uint32(pid)
+	}
+	if v := query.Get("port"); v != "" {
+		port, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", v, err)
+		}
+		req.filter.Port = uint32(port)
+	}
+	req.filter.ContainerId = query.Get("container_id")
+	req.filter.Protocol = query.Get("protocol")
+	req.filter.Name = query.Get("name")
+	if v := query.Get("sample_rate"); v != "" {
+		if req.filter.SampleRate, err = strconv.ParseFloat(v, 64); err != nil || req.filter.SampleRate <= 0 || req.filter.SampleRate > 1 {
+			return nil, fmt.Errorf("invalid sample_rate %q, it must be in (0, 1]", v)
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if req.limit, err = strconv.Atoi(v); err != nil || req.limit < 0 {
+			return nil, fmt.Errorf("invalid limit %q", v)
+		}
+	}
+	if v := query.Get("duration"); v != "" {
+		if req.duration, err = time.ParseDuration(v); err != nil || req.duration <= 0 {
+			return nil, fmt.Errorf("invalid duration %q", v)
+		}
+	}
+	return req, nil
+}
+
+func (h *TapHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := parseTapRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	if req.sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	subscription := h.hub.Subscribe(req.kind, req.filter, tapBufferSize)
+	defer h.hub.Unsubscribe(subscription)
+	h.tools.Logger.Infof("Start tapping %s to %s", req.kind, r.RemoteAddr)
+	timer := time.NewTimer(req.duration)
+	defer timer.Stop()
+	count := 0
+	for req.limit == 0 || count < req.limit {
+		select {
+		case data := <-subscription.C():
+			if req.sse {
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", req.kind, data)
+			} else {
+				_, err = fmt.Fprintf(w, "%s\n", data)
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+			count++
+		case <-timer.C:
+			h.logStop(req, count, subscription)
+			return
+		case <-r.Context().Done():
+			h.logStop(req, count, subscription)
+			return
+		}
+	}
+	h.logStop(req, count, subscription)
+}
+
+func (h *TapHandler) logStop(req *tapRequest, count int, subscription *tap.Subscription) {
+	h.tools.Logger.Infof("Stop tapping %s after %d sent and %d dropped", req.kind, count, subscription.Dropped())
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
+)
+
+// publishUntilDone publishes the events until the request is done, because the subscription is
+// created after the request is received.
+func publishUntilDone(hub *tap.Hub, done <-chan struct{}, events ...*model.KindlingEvent) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			for _, evt := range events {
+				hub.PublishEvent(evt)
+			}
+		}
+	}
+}
+
+func TestTapHandler(t *testing.T) {
+	hub := tap.NewHub()
+	server := httptest.NewServer(NewTapHandler(hub, component.NewDefaultTelemetryTools()))
+	defer server.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go publishUntilDone(hub, done,
+		&model.KindlingEvent{Name: "syscall_exit-read", Ctx: model.Context{ThreadInfo: model.Thread{Pid: 1}}},
+		&model.KindlingEvent{Name: "syscall_exit-write", Ctx: model.Context{ThreadInfo: model.Thread{Pid: 2}}},
+	)
+
+	resp, err := http.Get(server.URL + "?pid=2&limit=3")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+	lines := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		assert.Contains(t, scanner.Text(), `"Name":"syscall_exit-write"`)
+		lines++
+	}
+	assert.Equal(t, 3, lines)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?name=syscall_exit-read&limit=1", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := new(strings.Builder)
+	scanner = bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		body.WriteString(scanner.Text() + "\n")
+	}
+	assert.True(t, strings.HasPrefix(body.String(), "event: event\ndata: {"), body.String())
+}
+
+func TestTapHandlerStopsAfterDuration(t *testing.T) {
+	server := httptest.NewServer(NewTapHandler(tap.NewHub(), component.NewDefaultTelemetryTools()))
+	defer server.Close()
+	start := time.Now()
+	resp, err := http.Get(server.URL + "?type=datagroup&duration=100ms")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	assert.False(t, scanner.Scan())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTapHandlerBadRequest(t *testing.T) {
+	server := httptest.NewServer(NewTapHandler(tap.NewHub(), component.NewDefaultTelemetryTools()))
+	defer server.Close()
+	for _, query := range []string{"type=unknown", "pid=abc", "port=70000", "sample_rate=2", "limit=-1", "duration=0s", "format=xml"} {
+		resp, err := http.Get(server.URL + "?" + query)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		resp.Body.Close()
+	}
+}
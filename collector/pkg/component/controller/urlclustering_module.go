@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+const UrlClusteringModule = "urlclustering"
+
+// UrlClustering exposes the URL templates learned by the "drain" clustering method.
+// The operation "templates" returns the templates in JSON and "reset" forgets them.
+type UrlClustering struct {
+	method *urlclustering.DrainClusteringMethod
+}
+
+func NewUrlClusteringController() *UrlClustering {
+	return &UrlClustering{
+		method: urlclustering.GetDrainClusteringMethod(),
+	}
+}
+
+func (u *UrlClustering) GetModuleKey() string {
+	return UrlClusteringModule
+}
+
+func (u *UrlClustering) RegistSubModules(_ ...ExportSubModule) {
+}
+
+func (u *UrlClustering) HandRequest(req *ControlRequest) *ControlResponse {
+	switch req.Operation {
+	case "templates":
+		msg, err := json.Marshal(u.method.Templates())
+		if err != nil {
+			return &ControlResponse{
+				Code: NoOperation,
+				Msg:  err.Error(),
+			}
+		}
+		return &ControlResponse{
+			Code: NoError,
+			Msg:  string(msg),
+		}
+	case "reset":
+		u.method.Reset()
+		return &ControlResponse{
+			Code: NoError,
+			Msg:  "reset success",
+		}
+	default:
+		return &ControlResponse{
+			Code: NoOperation,
+			Msg:  fmt.Sprintf("unexpected operation:%s", req.Operation),
+		}
+	}
+}
+
+func (u *UrlClustering) GetOptions(_ *json.RawMessage) []Option {
+	return nil
+}
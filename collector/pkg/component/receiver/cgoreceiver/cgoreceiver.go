@@ -21,11 +21,16 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	analyzerpackage "github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/filereceiver"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
 )
 
 const (
 	Cgo = "cgoreceiver"
+
+	// tapFlushInterval is how often the recorded events are flushed into the tap file.
+	tapFlushInterval = time.Second
 )
 
 type CKindlingEventForGo C.struct_kindling_event_t_for_go
@@ -40,6 +45,11 @@ type CgoReceiver struct {
 	eventChannel    chan *model.KindlingEvent
 	stopCh          chan interface{}
 	stats           eventCounter
+	// tapWriter records the events into a file if the tap mode is enabled.
+	tapWriter   *filereceiver.EventWriter
+	tappedCount int
+	// tapHub streams the events to the "/debug/tap" endpoint of the HTTP controller.
+	tapHub *tap.Hub
 }
 
 func NewCgoReceiver(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
@@ -53,8 +63,18 @@ func NewCgoReceiver(config interface{}, telemetry *component.TelemetryTools, ana
 		telemetry:       telemetry,
 		eventChannel:    make(chan *model.KindlingEvent, 3e5),
 		stopCh:          make(chan interface{}, 1),
+		tapHub:          tap.GetHub(),
 	}
 	cgoReceiver.stats = newDynamicStats(cfg.SubscribeInfo)
+	if cfg.Tap.Enable {
+		tapWriter, err := filereceiver.NewEventWriter(cfg.Tap.FilePath)
+		if err != nil {
+			telemetry.Logger.Errorf("Failed to enable the tap mode: %v", err)
+		} else {
+			telemetry.Logger.Infof("Tap mode is enabled, and the events will be recorded into %s", cfg.Tap.FilePath)
+			cgoReceiver.tapWriter = tapWriter
+		}
+	}
 	newSelfMetrics(telemetry.MeterProvider, cgoReceiver)
 	return cgoReceiver
 }
@@ -98,11 +118,22 @@ func (r *CgoReceiver) startGetEvent() {
 
 func (r *CgoReceiver) consumeEvents() {
 	r.shutdownWG.Add(1)
+	// The tapped events are flushed into the file periodically in the same goroutine writing them.
+	var flushTap <-chan time.Time
+	if r.tapWriter != nil {
+		ticker := time.NewTicker(tapFlushInterval)
+		defer ticker.Stop()
+		flushTap = ticker.C
+	}
 	for {
 		select {
 		case <-r.stopCh:
 			r.shutdownWG.Done()
 			return
+		case <-flushTap:
+			if err := r.tapWriter.Flush(); err != nil {
+				r.telemetry.Logger.Warn("Failed to flush the recorded events: ", zap.Error(err))
+			}
 		case ev := <-r.eventChannel:
 			err := r.sendToNextConsumer(ev)
 			if err != nil {
@@ -117,6 +148,9 @@ func (r *CgoReceiver) Shutdown() error {
 	C.stopProfile()
 	close(r.stopCh)
 	r.shutdownWG.Wait()
+	if r.tapWriter != nil {
+		return r.tapWriter.Close()
+	}
 	return nil
 }
 
@@ -170,6 +204,10 @@ func (r *CgoReceiver) sendToNextConsumer(evt *model.KindlingEvent) error {
 	if ce := r.telemetry.Logger.Check(zapcore.DebugLevel, ""); ce != nil {
 		r.telemetry.Logger.Debug(fmt.Sprintf("Event Output: %+v", model.TextKindlingEvent(evt)))
 	}
+	if r.tapWriter != nil {
+		r.tapEvent(evt)
+	}
+	r.tapHub.PublishEvent(evt)
 	analyzers := r.analyzerManager.GetConsumableAnalyzers(evt.Name)
 	if analyzers == nil || len(analyzers) == 0 {
 		//r.telemetry.Logger.Info("analyzer not found for event ", zap.String("eventName", evt.Name))
@@ -184,6 +222,24 @@ func (r *CgoReceiver) sendToNextConsumer(evt *model.KindlingEvent) error {
 	return nil
 }
 
+// tapEvent records the event before it is consumed by the analyzers, which may modify it.
+func (r *CgoReceiver) tapEvent(evt *model.KindlingEvent) {
+	if r.cfg.Tap.MaxEvents > 0 && r.tappedCount >= r.cfg.Tap.MaxEvents {
+		return
+	}
+	if err := r.tapWriter.Write(evt); err != nil {
+		r.telemetry.Logger.Warn("Failed to record the event: ", zap.Error(err))
+		return
+	}
+	r.tappedCount++
+	if r.tappedCount == r.cfg.Tap.MaxEvents {
+		if err := r.tapWriter.Flush(); err != nil {
+			r.telemetry.Logger.Warn("Failed to flush the recorded events: ", zap.Error(err))
+		}
+		r.telemetry.Logger.Infof("%d events have been recorded, and the following ones will be skipped", r.tappedCount)
+	}
+}
+
 func (r *CgoReceiver) suppressEventsComm() {
 	comms := r.cfg.ProcessFilterInfo.Comms
 	if len(comms) > 0 {
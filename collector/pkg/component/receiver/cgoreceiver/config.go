@@ -3,6 +3,7 @@ package cgoreceiver
 type Config struct {
 	SubscribeInfo     []SubEvent    `mapstructure:"subscribe"`
 	ProcessFilterInfo ProcessFilter `mapstructure:"process_filter"`
+	Tap               TapConfig     `mapstructure:"tap"`
 }
 
 type SubEvent struct {
@@ -15,6 +16,16 @@ type ProcessFilter struct {
 	Comms []string `mapstructure:"comms"`
 }
 
+// TapConfig controls whether to record the received events into a file, which
+// could be replayed by filereceiver later.
+type TapConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// FilePath must not exist, so the tap mode is not enabled if a previous recording is there.
+	FilePath string `mapstructure:"file_path"`
+	// MaxEvents is the maximum number of events recorded. 0 means no limit.
+	MaxEvents int `mapstructure:"max_events"`
+}
+
 func NewDefaultConfig() *Config {
 	return &Config{
 		SubscribeInfo: []SubEvent{
@@ -82,5 +93,10 @@ func NewDefaultConfig() *Config {
 		ProcessFilterInfo: ProcessFilter{
 			Comms: []string{"kindling-collec", "containerd", "dockerd", "containerd-shim"},
 		},
+		Tap: TapConfig{
+			Enable:    false,
+			FilePath:  "/tmp/kindling/events.jsonl",
+			MaxEvents: 1000000,
+		},
 	}
 }
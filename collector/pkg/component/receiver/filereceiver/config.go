@@ -0,0 +1,17 @@
+package filereceiver
+
+type Config struct {
+	// FilePath is the path of the file recorded by the "tap" mode of cgoreceiver.
+	FilePath string `mapstructure:"file_path"`
+	// ReplaySpeed controls how fast the events are replayed.
+	// 1 means replaying the events at the pace they were captured, 2 means twice as fast, etc.
+	// 0 means replaying the events as fast as possible.
+	ReplaySpeed float64 `mapstructure:"replay_speed"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		FilePath:    "/tmp/kindling/events.jsonl",
+		ReplaySpeed: 0,
+	}
+}
@@ -0,0 +1,85 @@
+package filereceiver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// EventWriter writes KindlingEvents into a file, one JSON object per line.
+// It is not safe for concurrent use.
+type EventWriter struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewEventWriter creates the file to write the events into. It fails if the file exists
+// so that a previous recording is never truncated.
+func NewEventWriter(path string) (*EventWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("can't create the directory of %s: %w", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("can't create the events file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	return &EventWriter{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+func (w *EventWriter) Write(evt *model.KindlingEvent) error {
+	return w.encoder.Encode(evt)
+}
+
+// Flush writes the buffered events into the file.
+func (w *EventWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+// Close flushes the buffered events and closes the file.
+func (w *EventWriter) Close() error {
+	if err := w.writer.Flush(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// EventReader reads the KindlingEvents written by EventWriter.
+type EventReader struct {
+	file    *os.File
+	decoder *json.Decoder
+}
+
+func NewEventReader(path string) (*EventReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open the events file: %w", err)
+	}
+	return &EventReader{
+		file:    file,
+		decoder: json.NewDecoder(bufio.NewReader(file)),
+	}, nil
+}
+
+// Read returns the next event in the file. io.EOF is returned if there are no more events.
+func (r *EventReader) Read() (*model.KindlingEvent, error) {
+	evt := new(model.KindlingEvent)
+	if err := r.decoder.Decode(evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+func (r *EventReader) Close() error {
+	return r.file.Close()
+}
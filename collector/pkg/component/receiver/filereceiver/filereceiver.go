@@ -0,0 +1,117 @@
+package filereceiver
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	analyzerpackage "github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/tap"
+)
+
+const Type = "filereceiver"
+
+// FileReceiver replays the KindlingEvents recorded by the "tap" mode of cgoreceiver.
+// It makes it possible to run the analyzers without the probe.
+type FileReceiver struct {
+	cfg             *Config
+	analyzerManager *analyzerpackage.Manager
+	telemetry       *component.TelemetryTools
+	reader          *EventReader
+	shutdownWG      sync.WaitGroup
+	stopCh          chan struct{}
+	// tapHub streams the events to the "/debug/tap" endpoint of the HTTP controller.
+	tapHub *tap.Hub
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panicf("Cannot convert [%s] config", Type)
+	}
+	return &FileReceiver{
+		cfg:             cfg,
+		analyzerManager: analyzerManager,
+		telemetry:       telemetry,
+		stopCh:          make(chan struct{}),
+		tapHub:          tap.GetHub(),
+	}
+}
+
+func (r *FileReceiver) Start() error {
+	r.telemetry.Logger.Infof("Start FileReceiver and replay the events from %s", r.cfg.FilePath)
+	reader, err := NewEventReader(r.cfg.FilePath)
+	if err != nil {
+		return err
+	}
+	r.reader = reader
+	r.shutdownWG.Add(1)
+	go r.replay()
+	return nil
+}
+
+func (r *FileReceiver) replay() {
+	defer r.shutdownWG.Done()
+	var firstTimestamp uint64
+	var startTime time.Time
+	var count int
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		default:
+		}
+		evt, err := r.reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				r.telemetry.Logger.Infof("Finished replaying %d events from %s", count, r.cfg.FilePath)
+			} else {
+				r.telemetry.Logger.Error("Failed to read events, stop replaying", zap.Error(err))
+			}
+			return
+		}
+		if r.cfg.ReplaySpeed > 0 {
+			if count == 0 {
+				firstTimestamp = evt.Timestamp
+				startTime = time.Now()
+			} else if evt.Timestamp > firstTimestamp {
+				offset := time.Duration(float64(evt.Timestamp-firstTimestamp) / r.cfg.ReplaySpeed)
+				if wait := time.Until(startTime.Add(offset)); wait > 0 {
+					select {
+					case <-r.stopCh:
+						return
+					case <-time.After(wait):
+					}
+				}
+			}
+		}
+		count++
+		r.sendToNextConsumer(evt)
+	}
+}
+
+func (r *FileReceiver) sendToNextConsumer(evt *model.KindlingEvent) {
+	r.tapHub.PublishEvent(evt)
+	analyzers := r.analyzerManager.GetConsumableAnalyzers(evt.Name)
+	for _, analyzer := range analyzers {
+		err := analyzer.ConsumeEvent(evt)
+		if err != nil {
+			r.telemetry.Logger.Warn("Error sending event to next consumer: ", zap.Error(err))
+		}
+	}
+}
+
+func (r *FileReceiver) Shutdown() error {
+	close(r.stopCh)
+	r.shutdownWG.Wait()
+	if r.reader != nil {
+		return r.reader.Close()
+	}
+	return nil
+}
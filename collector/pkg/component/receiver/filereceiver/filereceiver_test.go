@@ -0,0 +1,113 @@
+package filereceiver
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type recordAnalyzer struct {
+	mu     sync.Mutex
+	events []*model.KindlingEvent
+}
+
+func (a *recordAnalyzer) Start() error    { return nil }
+func (a *recordAnalyzer) Shutdown() error { return nil }
+func (a *recordAnalyzer) Type() analyzer.Type {
+	return "recordanalyzer"
+}
+func (a *recordAnalyzer) ConsumableEvents() []string {
+	return []string{constnames.ReadEvent, constnames.WriteEvent}
+}
+func (a *recordAnalyzer) ConsumeEvent(evt *model.KindlingEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, evt)
+	return nil
+}
+func (a *recordAnalyzer) getEvents() []*model.KindlingEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.events
+}
+
+func newEvent(name string, timestamp uint64, data string) *model.KindlingEvent {
+	evt := &model.KindlingEvent{
+		Timestamp:    timestamp,
+		Name:         name,
+		Category:     model.Category_CAT_NET,
+		ParamsNumber: 1,
+	}
+	evt.UserAttributes[0] = model.KeyValue{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: []byte(data)}
+	evt.Ctx.ThreadInfo = model.Thread{Pid: 100, Tid: 101, Comm: "java", ContainerId: "abc"}
+	evt.Ctx.FdInfo = model.Fd{Num: 3, Protocol: model.L4Proto_TCP, Sip: []uint32{16777343}, Dip: []uint32{16777343}, Sport: 45678, Dport: 8080}
+	return evt
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	events := []*model.KindlingEvent{
+		newEvent(constnames.WriteEvent, 1000, "GET / HTTP/1.1\r\n"),
+		newEvent(constnames.ReadEvent, 2000, "HTTP/1.1 200 OK\r\n"),
+		newEvent(constnames.TcpCloseEvent, 3000, ""),
+	}
+	writer, err := NewEventWriter(path)
+	assert.NoError(t, err)
+	for _, evt := range events {
+		assert.NoError(t, writer.Write(evt))
+	}
+	assert.NoError(t, writer.Close())
+
+	recorder := &recordAnalyzer{}
+	manager, err := analyzer.NewManager(recorder)
+	assert.NoError(t, err)
+	cfg := &Config{FilePath: path, ReplaySpeed: 1}
+	r := New(cfg, component.NewDefaultTelemetryTools(), manager)
+	assert.NoError(t, r.Start())
+	assert.Eventually(t, func() bool {
+		return len(recorder.getEvents()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, r.Shutdown())
+
+	// The tcp_close event is not consumable by the analyzer.
+	assert.Equal(t, events[:2], recorder.getEvents())
+}
+
+func TestStartWithoutFile(t *testing.T) {
+	manager, _ := analyzer.NewManager(&recordAnalyzer{})
+	cfg := &Config{FilePath: filepath.Join(t.TempDir(), "not-exist.jsonl")}
+	r := New(cfg, component.NewDefaultTelemetryTools(), manager)
+	assert.Error(t, r.Start())
+	assert.NoError(t, r.Shutdown())
+}
+
+func TestNewEventWriterWithExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	writer, err := NewEventWriter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(newEvent(constnames.WriteEvent, 1000, "GET / HTTP/1.1\r\n")))
+	// The flushed events are readable before the writer is closed.
+	assert.NoError(t, writer.Flush())
+	reader, err := NewEventReader(path)
+	assert.NoError(t, err)
+	defer reader.Close()
+	_, err = reader.Read()
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	// The previous recording is not truncated.
+	_, err = NewEventWriter(path)
+	assert.Error(t, err)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
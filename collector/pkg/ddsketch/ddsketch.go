@@ -0,0 +1,225 @@
+package ddsketch
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// DefaultQuantiles are the quantiles exported for the sketches if no quantiles are configured.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99}
+
+const (
+	DefaultRelativeAccuracy = 0.01
+	// defaultMaxBins bounds the memory of a sketch. With the relative accuracy of 1%, 2048 bins cover
+	// the values from 1ns to more than 100 years.
+	defaultMaxBins = 2048
+)
+
+// DDSketch is a mergeable quantile sketch with the relative-error guarantee. See the paper
+// "DDSketch: A Fast and Fully-Mergeable Quantile Sketch with Relative-Error Guarantees" for details.
+//
+// A value v is counted into the bin with the index ceil(log(v)/log(gamma)), where
+// gamma = (1+α)/(1-α) and α is the relative accuracy, so any quantile returned is within α of the
+// real value. The non-positive values are counted as zeros.
+// When there are more than maxBins bins, the lowest bins are collapsed, so only the low quantiles
+// lose the accuracy.
+//
+// This sketch is not thread-safe.
+type DDSketch struct {
+	relativeAccuracy float64
+	gamma            float64
+	logGamma         float64
+	maxBins          int
+
+	bins      map[int]uint64
+	zeroCount uint64
+	count     uint64
+	min       float64
+	max       float64
+}
+
+// New returns an empty sketch. DefaultRelativeAccuracy is used if relativeAccuracy is not in (0, 1).
+func New(relativeAccuracy float64) *DDSketch {
+	if relativeAccuracy <= 0 || relativeAccuracy >= 1 {
+		relativeAccuracy = DefaultRelativeAccuracy
+	}
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{
+		relativeAccuracy: relativeAccuracy,
+		gamma:            gamma,
+		logGamma:         math.Log(gamma),
+		maxBins:          defaultMaxBins,
+		bins:             make(map[int]uint64),
+		min:              math.Inf(1),
+		max:              math.Inf(-1),
+	}
+}
+
+func (s *DDSketch) RelativeAccuracy() float64 {
+	return s.relativeAccuracy
+}
+
+func (s *DDSketch) Count() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.count
+}
+
+func (s *DDSketch) Add(value float64) {
+	s.addWithCount(value, 1)
+}
+
+func (s *DDSketch) addWithCount(value float64, count uint64) {
+	if count == 0 {
+		return
+	}
+	if value <= 0 {
+		value = 0
+		s.zeroCount += count
+	} else {
+		s.bins[s.index(value)] += count
+		s.collapse()
+	}
+	s.count += count
+	s.min = math.Min(s.min, value)
+	s.max = math.Max(s.max, value)
+}
+
+func (s *DDSketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value returns the estimation of the values in the bin, whose relative error is at most α.
+func (s *DDSketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (1 + s.gamma)
+}
+
+// collapse merges the lowest bins into one if there are too many bins.
+func (s *DDSketch) collapse() {
+	if len(s.bins) <= s.maxBins {
+		return
+	}
+	indexes := s.sortedIndexes()
+	target := indexes[len(indexes)-s.maxBins]
+	for _, index := range indexes[:len(indexes)-s.maxBins] {
+		s.bins[target] += s.bins[index]
+		delete(s.bins, index)
+	}
+}
+
+func (s *DDSketch) sortedIndexes() []int {
+	indexes := make([]int, 0, len(s.bins))
+	for index := range s.bins {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Merge adds all the values of the other sketch into this one. If their relative accuracies are
+// different, the values of the other sketch are estimated first, so the error may be larger.
+func (s *DDSketch) Merge(other *DDSketch) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	if s.gamma == other.gamma {
+		for index, count := range other.bins {
+			s.bins[index] += count
+		}
+		s.collapse()
+		s.zeroCount += other.zeroCount
+		s.count += other.count
+		s.min = math.Min(s.min, other.min)
+		s.max = math.Max(s.max, other.max)
+		return
+	}
+	s.addWithCount(0, other.zeroCount)
+	for index, count := range other.bins {
+		s.addWithCount(other.value(index), count)
+	}
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+}
+
+// Quantile returns the estimation of the q-quantile, where q is in [0, 1].
+// 0 is returned if the sketch is nil or empty.
+func (s *DDSketch) Quantile(q float64) float64 {
+	if s == nil || s.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return s.min
+	}
+	if q >= 1 {
+		return s.max
+	}
+	rank := q * float64(s.count-1)
+	if rank < float64(s.zeroCount) {
+		return 0
+	}
+	cumulative := float64(s.zeroCount)
+	for _, index := range s.sortedIndexes() {
+		cumulative += float64(s.bins[index])
+		if cumulative > rank {
+			// The estimation could be out of the observed range for the extreme bins.
+			return math.Max(s.min, math.Min(s.max, s.value(index)))
+		}
+	}
+	return s.max
+}
+
+// Copy returns a deep copy of the sketch, or nil if the sketch is nil.
+func (s *DDSketch) Copy() *DDSketch {
+	if s == nil {
+		return nil
+	}
+	ret := *s
+	ret.bins = make(map[int]uint64, len(s.bins))
+	for index, count := range s.bins {
+		ret.bins[index] = count
+	}
+	return &ret
+}
+
+// sketchJSON is how a sketch is marshaled. Min and Max are absent if the sketch is empty because
+// JSON can't carry the infinities.
+type sketchJSON struct {
+	RelativeAccuracy float64
+	ZeroCount        uint64
+	Min              *float64 `json:",omitempty"`
+	Max              *float64 `json:",omitempty"`
+	Bins             map[int]uint64
+}
+
+func (s *DDSketch) MarshalJSON() ([]byte, error) {
+	ret := sketchJSON{
+		RelativeAccuracy: s.relativeAccuracy,
+		ZeroCount:        s.zeroCount,
+		Bins:             s.bins,
+	}
+	if s.count > 0 {
+		ret.Min, ret.Max = &s.min, &s.max
+	}
+	return json.Marshal(&ret)
+}
+
+func (s *DDSketch) UnmarshalJSON(data []byte) error {
+	var value sketchJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = *New(value.RelativeAccuracy)
+	s.zeroCount = value.ZeroCount
+	s.count = value.ZeroCount
+	for index, count := range value.Bins {
+		s.bins[index] = count
+		s.count += count
+	}
+	if value.Min != nil && value.Max != nil {
+		s.min, s.max = *value.Min, *value.Max
+	}
+	s.collapse()
+	return nil
+}
@@ -0,0 +1,108 @@
+package ddsketch
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func assertRelativeError(t *testing.T, want float64, got float64, accuracy float64) {
+	t.Helper()
+	if math.Abs(got-want) > want*accuracy+1e-9 {
+		t.Errorf("got %f, want %f within relative accuracy %f", got, want, accuracy)
+	}
+}
+
+func TestDDSketch_Quantile(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sketch := New(0.01)
+	values := make([]float64, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		// Latencies from 1ms to about 20s with a long tail.
+		value := math.Exp(r.NormFloat64()*1.5+17) + 1e6
+		values = append(values, value)
+		sketch.Add(value)
+	}
+	sort.Float64s(values)
+	assert.Equal(t, uint64(10000), sketch.Count())
+	for _, q := range []float64{0.01, 0.5, 0.9, 0.99, 0.999} {
+		assertRelativeError(t, exactQuantile(values, q), sketch.Quantile(q), 0.01)
+	}
+	assert.Equal(t, values[0], sketch.Quantile(0))
+	assert.Equal(t, values[len(values)-1], sketch.Quantile(1))
+}
+
+func TestDDSketch_Merge(t *testing.T) {
+	all := New(0.02)
+	merged := New(0.02)
+	values := make([]float64, 0, 2000)
+	for i := 0; i < 10; i++ {
+		part := New(0.02)
+		for j := 0; j < 200; j++ {
+			value := float64((i*200 + j) * 1000)
+			values = append(values, value)
+			part.Add(value)
+			all.Add(value)
+		}
+		merged.Merge(part)
+	}
+	sort.Float64s(values)
+	assert.Equal(t, all.Count(), merged.Count())
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		assert.Equal(t, all.Quantile(q), merged.Quantile(q))
+		assertRelativeError(t, exactQuantile(values, q), merged.Quantile(q), 0.02)
+	}
+
+	// The sketches with different accuracies could be merged with a larger error.
+	other := New(0.05)
+	other.Add(1000)
+	other.Add(0)
+	copied := merged.Copy()
+	copied.Merge(other)
+	assert.Equal(t, merged.Count()+2, copied.Count())
+	assert.Equal(t, uint64(2000), merged.Count())
+}
+
+func TestDDSketch_Bounded(t *testing.T) {
+	sketch := New(0.01)
+	sketch.maxBins = 100
+	for i := 1; i <= 100000; i *= 2 {
+		for j := 0; j < 100; j++ {
+			sketch.Add(float64(i*100 + j))
+		}
+	}
+	assert.LessOrEqual(t, len(sketch.bins), 100)
+	// The high quantiles are still accurate after the lowest bins are collapsed.
+	assertRelativeError(t, float64(65536*100+98), sketch.Quantile(0.999), 0.01)
+	assert.Equal(t, 0.0, New(0).Quantile(0.5))
+	assert.Equal(t, DefaultRelativeAccuracy, New(2).RelativeAccuracy())
+}
+
+func TestDDSketch_JSON(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sketch := New(0.02)
+	sketch.Add(0)
+	for i := 0; i < 1000; i++ {
+		sketch.Add(r.ExpFloat64() * 1e6)
+	}
+	data, err := json.Marshal(sketch)
+	assert.NoError(t, err)
+	decoded := &DDSketch{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, sketch, decoded)
+
+	// The empty sketch has no min and max, which are infinite.
+	data, err = json.Marshal(New(0.02))
+	assert.NoError(t, err)
+	decoded = &DDSketch{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, New(0.02), decoded)
+}
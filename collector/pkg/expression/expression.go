@@ -0,0 +1,389 @@
+// Package expression implements the boolean expressions evaluated against the data groups, e.g.
+//
+//	dst_namespace == "kube-system"
+//	protocol in [http, grpc] && !is_server
+//	content_key =~ "/api/.*" || request_total_time > 1e9
+//
+// The identifiers refer to the labels of the data group. If there is no such label, the metric with the
+// same name is used, and the missing ones are treated as empty strings. "__name__" refers to the name of
+// the data group.
+//
+// Operators: "==", "!=", "<", "<=", ">", ">=", "=~" and "!~" (the regex is fully anchored), "in" and
+// "not in" followed by a list, "&&" ("and"), "||" ("or"), "!" ("not") and the parentheses. An identifier
+// alone is true if its value is true, a non-zero number, or a non-empty string other than "false".
+// The values are compared as numbers if both of them are numbers, otherwise as strings. The strings could
+// be double-quoted or single-quoted, and the bare words in a list are treated as strings.
+package expression
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// NameIdentifier refers to the name of the data group.
+const NameIdentifier = "__name__"
+
+// Expression is a compiled boolean expression. It is safe for concurrent use.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses the expression.
+func Compile(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != eofToken {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(source string) *Expression {
+	e, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Match reports whether the data group matches the expression.
+func (e *Expression) Match(g *model.DataGroup) bool {
+	return e.root.eval(g)
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+type value struct {
+	str   string
+	num   float64
+	isNum bool
+}
+
+func stringValue(s string) value {
+	return value{str: s}
+}
+
+func numberValue(n float64) value {
+	return value{str: strconv.FormatFloat(n, 'f', -1, 64), num: n, isNum: true}
+}
+
+func (v value) truthy() bool {
+	if v.isNum {
+		return v.num != 0
+	}
+	return v.str != "" && v.str != "false"
+}
+
+// compare returns -1, 0 or 1.
+func (v value) compare(other value) int {
+	if v.isNum && other.isNum {
+		switch {
+		case v.num < other.num:
+			return -1
+		case v.num > other.num:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case v.str < other.str:
+		return -1
+	case v.str > other.str:
+		return 1
+	}
+	return 0
+}
+
+type operand interface {
+	value(g *model.DataGroup) value
+}
+
+type literal value
+
+func (l literal) value(*model.DataGroup) value {
+	return value(l)
+}
+
+type identifier string
+
+func (i identifier) value(g *model.DataGroup) value {
+	name := string(i)
+	if name == NameIdentifier {
+		return stringValue(g.Name)
+	}
+	if attr, ok := g.Labels.GetValues()[name]; ok {
+		if attr.Type() == model.IntAttributeValueType {
+			return numberValue(float64(g.Labels.GetIntValue(name)))
+		}
+		return stringValue(attr.ToString())
+	}
+	if metric, ok := g.GetMetric(name); ok && metric.DataType() == model.IntMetricType {
+		return numberValue(float64(metric.GetInt().Value))
+	}
+	return stringValue("")
+}
+
+type node interface {
+	eval(g *model.DataGroup) bool
+}
+
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(g *model.DataGroup) bool { return n.left.eval(g) || n.right.eval(g) }
+
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(g *model.DataGroup) bool { return n.left.eval(g) && n.right.eval(g) }
+
+type notNode struct{ node node }
+
+func (n *notNode) eval(g *model.DataGroup) bool { return !n.node.eval(g) }
+
+type truthNode struct{ operand operand }
+
+func (n *truthNode) eval(g *model.DataGroup) bool { return n.operand.value(g).truthy() }
+
+type compareNode struct {
+	op          string
+	left, right operand
+}
+
+func (n *compareNode) eval(g *model.DataGroup) bool {
+	c := n.left.value(g).compare(n.right.value(g))
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+type regexNode struct {
+	operand operand
+	regex   *regexp.Regexp
+	negate  bool
+}
+
+func (n *regexNode) eval(g *model.DataGroup) bool {
+	return n.regex.MatchString(n.operand.value(g).str) != n.negate
+}
+
+type inNode struct {
+	operand operand
+	values  []value
+	negate  bool
+}
+
+func (n *inNode) eval(g *model.DataGroup) bool {
+	v := n.operand.value(g)
+	for _, candidate := range n.values {
+		if v.compare(candidate) == 0 {
+			return !n.negate
+		}
+	}
+	return n.negate
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != eofToken {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the operators or keywords.
+func (p *parser) accept(texts ...string) bool {
+	t := p.peek()
+	if t.kind != operatorToken && t.kind != identToken {
+		return false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q but got %s", text, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!", "not") {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node: n}, nil
+	}
+	if p.accept("(") {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case t.kind == operatorToken && (t.text == "==" || t.text == "!=" || t.text == "<" ||
+		t.text == "<=" || t.text == ">" || t.text == ">="):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: t.text, left: left, right: right}, nil
+	case t.kind == operatorToken && (t.text == "=~" || t.text == "!~"):
+		p.next()
+		pattern := p.next()
+		if pattern.kind != stringToken {
+			return nil, fmt.Errorf("expected a regex string after %q but got %s", t.text, pattern)
+		}
+		regex, err := regexp.Compile("^(?:" + pattern.text + ")$")
+		if err != nil {
+			return nil, err
+		}
+		return &regexNode{operand: left, regex: regex, negate: t.text == "!~"}, nil
+	case t.kind == identToken && t.text == "in":
+		p.next()
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{operand: left, values: values}, nil
+	case t.kind == identToken && t.text == "not" && p.tokens[p.pos+1].text == "in":
+		p.pos += 2
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{operand: left, values: values, negate: true}, nil
+	}
+	return &truthNode{operand: left}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case identToken:
+		switch t.text {
+		case "true", "false":
+			return literal(stringValue(t.text)), nil
+		case "and", "or", "not", "in":
+			return nil, fmt.Errorf("unexpected keyword %s", t)
+		}
+		return identifier(t.text), nil
+	case stringToken:
+		return literal(stringValue(t.text)), nil
+	case numberToken:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t)
+		}
+		return literal(numberValue(n)), nil
+	}
+	return nil, fmt.Errorf("expected a label, a string or a number but got %s", t)
+}
+
+func (p *parser) parseList() ([]value, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	values := make([]value, 0)
+	for !p.accept("]") {
+		if len(values) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		t := p.next()
+		switch t.kind {
+		case identToken, stringToken:
+			values = append(values, stringValue(t.text))
+		case numberToken:
+			n, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", t)
+			}
+			values = append(values, numberValue(n))
+		default:
+			return nil, fmt.Errorf("expected a list item but got %s", t)
+		}
+	}
+	return values, nil
+}
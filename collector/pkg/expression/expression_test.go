@@ -0,0 +1,81 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newDataGroup() *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.DstNamespace, "kube-system")
+	labels.AddStringValue(constlabels.Protocol, "http")
+	labels.AddStringValue(constlabels.ContentKey, "/api/orders")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	labels.AddBoolValue(constlabels.IsServer, true)
+	labels.AddBoolValue(constlabels.IsSlow, false)
+	return model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, 0,
+		model.NewIntMetric(constvalues.RequestTotalTime, 2e9))
+}
+
+func TestExpression_Match(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`dst_namespace == "kube-system"`, true},
+		{`dst_namespace != 'kube-system'`, false},
+		{`protocol in [http, grpc]`, true},
+		{`protocol not in ["http", "grpc"]`, false},
+		{`dst_port in [80, 8080]`, true},
+		{`dst_port == "8080"`, true},
+		{`dst_port >= 1024 && dst_port < 10000`, true},
+		{`content_key =~ "/api/.*"`, true},
+		{`content_key =~ "/api"`, false},
+		{`content_key !~ "/health.*"`, true},
+		{`is_server`, true},
+		{`is_slow or !is_server`, false},
+		{`not (is_slow || is_server)`, false},
+		{`is_server == true and is_slow == false`, true},
+		{`request_total_time > 1e9`, true},
+		{`request_total_time > 1e9 && protocol == "grpc" || dst_port == 8080`, true},
+		{`unknown_label == ""`, true},
+		{`unknown_label`, false},
+		{`__name__ == "aggregated_net_request_metric_group"`, true},
+		{`dst_port > -1`, true},
+	}
+	g := newDataGroup()
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			e, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			assert.Equal(t, tt.want, e.Match(g))
+		})
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	for _, expression := range []string{
+		``,
+		`protocol ==`,
+		`protocol == "http`,
+		`(protocol == "http"`,
+		`protocol in http`,
+		`protocol in [http grpc]`,
+		`content_key =~ path`,
+		`content_key =~ "(["`,
+		`protocol == "http" protocol`,
+		`protocol # "http"`,
+		`and`,
+	} {
+		_, err := Compile(expression)
+		assert.Error(t, err, expression)
+	}
+}
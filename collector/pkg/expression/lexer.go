@@ -0,0 +1,111 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+	identToken
+	stringToken
+	numberToken
+	operatorToken
+)
+
+type token struct {
+	kind tokenKind
+	// text is the unquoted value for the string tokens.
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == eofToken {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at position %d", t.text, t.pos)
+}
+
+// The longer operators must be listed before their prefixes.
+var operators = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","}
+
+func tokenize(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(input); {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(input) && input[end] != input[i] {
+				// Only the double-quoted strings support the escape sequences.
+				if c == '"' && input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			text, err := unquote(input[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: stringToken, text: text, pos: i})
+			i = end + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(input) && unicode.IsDigit(rune(input[i+1]))):
+			end := i + 1
+			for end < len(input) && isNumberChar(input[end], input[end-1]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: input[i:end], pos: i})
+			i = end
+		case isIdentChar(c, true):
+			end := i + 1
+			for end < len(input) && isIdentChar(rune(input[end]), false) {
+				end++
+			}
+			tokens = append(tokens, token{kind: identToken, text: input[i:end], pos: i})
+			i = end
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: operatorToken, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: eofToken, pos: len(input)}), nil
+}
+
+func unquote(quoted string) (string, error) {
+	if quoted[0] == '\'' {
+		return quoted[1 : len(quoted)-1], nil
+	}
+	return strconv.Unquote(quoted)
+}
+
+func isIdentChar(c rune, first bool) bool {
+	if c == '_' || unicode.IsLetter(c) {
+		return true
+	}
+	return !first && (unicode.IsDigit(c) || c == '.')
+}
+
+func isNumberChar(c byte, prev byte) bool {
+	return (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' ||
+		((c == '-' || c == '+') && (prev == 'e' || prev == 'E'))
+}
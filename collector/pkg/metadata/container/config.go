@@ -0,0 +1,38 @@
+// Package container provides the metadata of the containers running on the plain Docker
+// or containerd hosts, where the Kubernetes metadata is not available.
+package container
+
+const (
+	DockerRuntime = "docker"
+	CriRuntime    = "cri"
+
+	DefaultDockerEndpoint = "/var/run/docker.sock"
+	DefaultCriEndpoint    = "/run/containerd/containerd.sock"
+)
+
+type Config struct {
+	// Enable controls whether to query the container runtime for the metadata of the containers.
+	// It only takes effect when the Kubernetes metadata is disabled.
+	Enable bool `mapstructure:"enable"`
+	// Runtime is the API used to query the containers. "docker" uses the Docker Engine API,
+	// and "cri" uses the CRI API served by containerd or CRI-O.
+	Runtime string `mapstructure:"runtime"`
+	// Endpoint is the path of the unix socket of the runtime. The default value is
+	// "/var/run/docker.sock" for docker and "/run/containerd/containerd.sock" for cri.
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout is the timeout of each query. The unit is seconds, and the default value is 2 seconds.
+	Timeout int `mapstructure:"timeout"`
+	// CacheSize is the maximum number of the containers cached. The default value is 4096.
+	CacheSize int `mapstructure:"cache_size"`
+	// RetryInterval controls how long to wait before querying a container again if it is not found.
+	// The unit is seconds, and the default value is 60 seconds.
+	RetryInterval int `mapstructure:"retry_interval"`
+}
+
+var DefaultConfig = Config{
+	Enable:        false,
+	Runtime:       DockerRuntime,
+	Timeout:       2,
+	CacheSize:     4096,
+	RetryInterval: 60,
+}
@@ -0,0 +1,157 @@
+package container
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const criContainerStatusMethod = "/runtime.v1.RuntimeService/ContainerStatus"
+
+// criClient queries the containers with the CRI API over the unix socket.
+// Only the few fields we care about are encoded and decoded by hand, which saves
+// depending on the generated code of the whole CRI API.
+type criClient struct {
+	conn *grpc.ClientConn
+}
+
+func newCriClient(endpoint string) (*criClient, error) {
+	// The connection is established lazily, so the runtime doesn't need to be ready now.
+	conn, err := grpc.Dial("unix://"+endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the CRI endpoint %s: %w", endpoint, err)
+	}
+	return &criClient{conn: conn}, nil
+}
+
+func (c *criClient) inspect(ctx context.Context, containerId string) (*containerMeta, error) {
+	// ContainerStatusRequest{container_id = 1}
+	req := protowire.AppendTag(nil, 1, protowire.BytesType)
+	req = protowire.AppendString(req, containerId)
+	var resp []byte
+	if err := c.conn.Invoke(ctx, criContainerStatusMethod, req, &resp); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, errContainerNotFound
+		}
+		return nil, err
+	}
+	meta := &containerMeta{Labels: make(map[string]string)}
+	// ContainerStatusResponse{status = 1}
+	err := consumeMessage(resp, func(num protowire.Number, value []byte) error {
+		if num != 1 {
+			return nil
+		}
+		return decodeCriContainerStatus(value, meta)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the container status: %w", err)
+	}
+	if meta.Id == "" {
+		return nil, errContainerNotFound
+	}
+	return meta, nil
+}
+
+// decodeCriContainerStatus decodes
+// ContainerStatus{id = 1, metadata = 2, image = 8, labels = 12},
+// ContainerMetadata{name = 1} and ImageSpec{image = 1}.
+func decodeCriContainerStatus(b []byte, meta *containerMeta) error {
+	return consumeMessage(b, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			meta.Id = string(value)
+		case 2:
+			return consumeMessage(value, func(num protowire.Number, value []byte) error {
+				if num == 1 {
+					meta.Name = string(value)
+				}
+				return nil
+			})
+		case 8:
+			return consumeMessage(value, func(num protowire.Number, value []byte) error {
+				if num == 1 {
+					meta.Image = string(value)
+				}
+				return nil
+			})
+		case 12:
+			var key, val string
+			err := consumeMessage(value, func(num protowire.Number, value []byte) error {
+				switch num {
+				case 1:
+					key = string(value)
+				case 2:
+					val = string(value)
+				}
+				return nil
+			})
+			meta.Labels[key] = val
+			return err
+		}
+		return nil
+	})
+}
+
+// consumeMessage calls fn with the length-delimited fields of the message and skips the others.
+func consumeMessage(b []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *criClient) close() error {
+	return c.conn.Close()
+}
+
+// rawCodec passes the messages encoded by hand through gRPC.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec can't marshal %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec can't unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,112 @@
+package container
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func criContainerStatusResponse(id string) []byte {
+	var metadata, image, label, containerStatus []byte
+	metadata = appendString(metadata, 1, "redis")
+	// ContainerMetadata.attempt, which should be skipped.
+	metadata = protowire.AppendTag(metadata, 2, protowire.VarintType)
+	metadata = protowire.AppendVarint(metadata, 1)
+	image = appendString(image, 1, "docker.io/library/redis:7")
+	label = appendString(label, 1, composeProjectLabel)
+	label = appendString(label, 2, "shop")
+
+	containerStatus = appendString(containerStatus, 1, id)
+	containerStatus = appendMessage(containerStatus, 2, metadata)
+	// ContainerStatus.state
+	containerStatus = protowire.AppendTag(containerStatus, 3, protowire.VarintType)
+	containerStatus = protowire.AppendVarint(containerStatus, 1)
+	containerStatus = appendMessage(containerStatus, 8, image)
+	containerStatus = appendString(containerStatus, 9, "sha256:7614ae94")
+	containerStatus = appendMessage(containerStatus, 12, label)
+	return appendMessage(nil, 1, containerStatus)
+}
+
+// startFakeCri serves ContainerStatus of the container "1a2b3c4d5e6f" on a unix socket.
+func startFakeCri(t *testing.T) string {
+	endpoint := filepath.Join(t.TempDir(), "containerd.sock")
+	listener, err := net.Listen("unix", endpoint)
+	require.NoError(t, err)
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			if method != criContainerStatusMethod {
+				return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+			}
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			var id string
+			err := consumeMessage(req, func(num protowire.Number, value []byte) error {
+				if num == 1 {
+					id = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if id != "1a2b3c4d5e6f" {
+				return status.Errorf(codes.NotFound, "container %q not found", id)
+			}
+			return stream.SendMsg(criContainerStatusResponse("1a2b3c4d5e6f7a8b9c0d"))
+		}),
+	)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return endpoint
+}
+
+func TestCriClient_Inspect(t *testing.T) {
+	client, err := newCriClient(startFakeCri(t))
+	require.NoError(t, err)
+	defer client.close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	meta, err := client.inspect(ctx, "1a2b3c4d5e6f")
+	require.NoError(t, err)
+	assert.Equal(t, &containerMeta{
+		Id:     "1a2b3c4d5e6f7a8b9c0d",
+		Name:   "redis",
+		Image:  "docker.io/library/redis:7",
+		Labels: map[string]string{composeProjectLabel: "shop"},
+	}, meta)
+
+	_, err = client.inspect(ctx, "ffffffffffff")
+	assert.ErrorIs(t, err, errContainerNotFound)
+}
+
+func TestDecodeCriContainerStatus_Malformed(t *testing.T) {
+	meta := &containerMeta{Labels: make(map[string]string)}
+	// The length of the field is larger than the message.
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendVarint(b, 100)
+	assert.Error(t, decodeCriContainerStatus(b, meta))
+}
@@ -0,0 +1,72 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// dockerClient queries the containers with the Docker Engine API over the unix socket.
+type dockerClient struct {
+	httpClient *http.Client
+}
+
+type dockerContainer struct {
+	Id     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+func newDockerClient(endpoint string) *dockerClient {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", endpoint)
+		},
+	}
+	return &dockerClient{httpClient: &http.Client{Transport: transport}}
+}
+
+func (c *dockerClient) inspect(ctx context.Context, containerId string) (*containerMeta, error) {
+	// The host is ignored as the requests are sent to the unix socket.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/"+url.PathEscape(containerId)+"/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, errContainerNotFound
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status %d from docker: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var container dockerContainer
+	if err = json.NewDecoder(resp.Body).Decode(&container); err != nil {
+		return nil, fmt.Errorf("failed to decode the container: %w", err)
+	}
+	return &containerMeta{
+		Id:     container.Id,
+		Name:   strings.TrimPrefix(container.Name, "/"),
+		Image:  container.Config.Image,
+		Labels: container.Config.Labels,
+	}, nil
+}
+
+func (c *dockerClient) close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
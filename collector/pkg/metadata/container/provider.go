@@ -0,0 +1,202 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	// ComposeKind is the workload kind of the containers created by Docker Compose.
+	ComposeKind = "compose"
+	// ContainerKind is the workload kind of the standalone containers.
+	ContainerKind = "container"
+
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+	// The labels set by kubelet, with which the containers found by the CRI API
+	// are still attributed to their pods.
+	podNameLabel      = "io.kubernetes.pod.name"
+	podNamespaceLabel = "io.kubernetes.pod.namespace"
+
+	pendingQueueSize = 1024
+)
+
+var errContainerNotFound = errors.New("container not found")
+
+// containerMeta is the metadata of a container returned by the runtimes.
+type containerMeta struct {
+	Id     string
+	Name   string
+	Image  string
+	Labels map[string]string
+}
+
+// runtimeClient queries the metadata of the containers from the container runtime.
+type runtimeClient interface {
+	// inspect returns errContainerNotFound if the container doesn't exist.
+	// The id could be the prefix of the full id.
+	inspect(ctx context.Context, containerId string) (*containerMeta, error)
+	close() error
+}
+
+type cacheEntry struct {
+	// info is nil if the container is being queried or not found.
+	info *kubernetes.K8sContainerInfo
+	// queriedAt is used to query the containers not found again after the retry interval.
+	queriedAt time.Time
+}
+
+// Provider implements kubernetes.ContainerProvider. The containers not cached are queried
+// asynchronously, so they are not found until the queries are finished.
+type Provider struct {
+	client        runtimeClient
+	timeout       time.Duration
+	retryInterval time.Duration
+	// labelKeys are the keys of the container labels attached as the dimensions.
+	labelKeys []string
+	cache     *lru.Cache
+	pending   chan string
+	stopCh    chan struct{}
+	logger    *component.TelemetryLogger
+}
+
+// NewProvider creates the provider and starts querying the runtime in the background.
+// The container labels of labelKeys are attached to the data like the pod labels.
+func NewProvider(cfg *Config, labelKeys []string, logger *component.TelemetryLogger) (*Provider, error) {
+	var client runtimeClient
+	var err error
+	switch cfg.Runtime {
+	case DockerRuntime:
+		client = newDockerClient(endpointOrDefault(cfg.Endpoint, DefaultDockerEndpoint))
+	case CriRuntime:
+		client, err = newCriClient(endpointOrDefault(cfg.Endpoint, DefaultCriEndpoint))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported container runtime %q, must be %q or %q", cfg.Runtime, DockerRuntime, CriRuntime)
+	}
+	return newProvider(client, cfg, labelKeys, logger)
+}
+
+func newProvider(client runtimeClient, cfg *Config, labelKeys []string, logger *component.TelemetryLogger) (*Provider, error) {
+	cache, err := lru.New(cfg.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provider{
+		client:        client,
+		timeout:       time.Duration(cfg.Timeout) * time.Second,
+		retryInterval: time.Duration(cfg.RetryInterval) * time.Second,
+		labelKeys:     labelKeys,
+		cache:         cache,
+		pending:       make(chan string, pendingQueueSize),
+		stopCh:        make(chan struct{}),
+		logger:        logger,
+	}
+	go p.run()
+	return p, nil
+}
+
+func endpointOrDefault(endpoint string, defaultEndpoint string) string {
+	if endpoint == "" {
+		return defaultEndpoint
+	}
+	return endpoint
+}
+
+func (p *Provider) GetByContainerId(containerId string) (*kubernetes.K8sContainerInfo, bool) {
+	if containerId == "" {
+		return nil, false
+	}
+	if value, ok := p.cache.Get(containerId); ok {
+		entry := value.(*cacheEntry)
+		if entry.info != nil {
+			return entry.info, true
+		}
+		if time.Since(entry.queriedAt) < p.retryInterval {
+			return nil, false
+		}
+	}
+	// Mark the container as queried so that it is not queued again.
+	p.cache.Add(containerId, &cacheEntry{queriedAt: time.Now()})
+	select {
+	case p.pending <- containerId:
+	default:
+		// The queue is full. The container will be queried after the retry interval.
+	}
+	return nil, false
+}
+
+func (p *Provider) run() {
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case containerId := <-p.pending:
+			p.query(containerId)
+		}
+	}
+}
+
+func (p *Provider) query(containerId string) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	meta, err := p.client.inspect(ctx, containerId)
+	entry := &cacheEntry{queriedAt: time.Now()}
+	if err == nil {
+		entry.info = p.toContainerInfo(containerId, meta)
+	} else if !errors.Is(err, errContainerNotFound) {
+		p.logger.Warn("Failed to query the container metadata", zap.String("containerId", containerId), zap.Error(err))
+	}
+	p.cache.Add(containerId, entry)
+}
+
+// toContainerInfo converts the metadata to the Kubernetes metadata, so that the containers
+// are labeled the same way as the ones in Kubernetes. The compose project is used as the
+// namespace, and the compose service or the container itself is used as the workload.
+func (p *Provider) toContainerInfo(containerId string, meta *containerMeta) *kubernetes.K8sContainerInfo {
+	podInfo := &kubernetes.K8sPodInfo{
+		ContainerIds: []string{containerId},
+		Labels:       meta.Labels,
+		Namespace:    meta.Labels[composeProjectLabel],
+		WorkloadKind: ContainerKind,
+		WorkloadName: meta.Name,
+	}
+	if service, ok := meta.Labels[composeServiceLabel]; ok {
+		podInfo.WorkloadKind = ComposeKind
+		podInfo.WorkloadName = service
+	} else if podName, ok := meta.Labels[podNameLabel]; ok {
+		podInfo.PodName = podName
+		podInfo.Namespace = meta.Labels[podNamespaceLabel]
+	}
+	for _, key := range p.labelKeys {
+		if value, ok := meta.Labels[key]; ok {
+			if podInfo.Dimensions == nil {
+				podInfo.Dimensions = make(map[string]string)
+			}
+			podInfo.Dimensions[constlabels.PodDimensionName(key)] = value
+		}
+	}
+	return &kubernetes.K8sContainerInfo{
+		ContainerId: containerId,
+		Name:        meta.Name,
+		Image:       meta.Image,
+		RefPodInfo:  podInfo,
+	}
+}
+
+// Close stops querying the runtime.
+func (p *Provider) Close() error {
+	close(p.stopCh)
+	return p.client.close()
+}
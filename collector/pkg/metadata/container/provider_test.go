@@ -0,0 +1,136 @@
+package container
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+)
+
+const dockerContainerJson = `{
+  "Id": "1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b",
+  "Name": "/shop-web-1",
+  "Config": {
+    "Image": "nginx:1.25",
+    "Labels": {
+      "com.docker.compose.project": "shop",
+      "com.docker.compose.service": "web",
+      "app.kubernetes.io/version": "v1.2.0"
+    }
+  }
+}`
+
+// startFakeDocker serves the containers whose ids start with "1a2b3c4d" on a unix socket.
+func startFakeDocker(t *testing.T, requests *int32) string {
+	endpoint := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", endpoint)
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/containers/"), "/json")
+		if !strings.HasPrefix("1a2b3c4d5e6f7a8b", id) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "No such container: ` + id + `"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(dockerContainerJson))
+	})}
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	return endpoint
+}
+
+func TestProvider_Docker(t *testing.T) {
+	var requests int32
+	cfg := DefaultConfig
+	cfg.Endpoint = startFakeDocker(t, &requests)
+	cfg.RetryInterval = 3600
+	provider, err := NewProvider(&cfg, []string{"app.kubernetes.io/version", "not-exist"}, component.NewDefaultTelemetryTools().Logger)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	// The container is queried asynchronously.
+	_, ok := provider.GetByContainerId("1a2b3c4d5e6f")
+	assert.False(t, ok)
+	var info *kubernetes.K8sContainerInfo
+	require.Eventually(t, func() bool {
+		info, ok = provider.GetByContainerId("1a2b3c4d5e6f")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, &kubernetes.K8sContainerInfo{
+		ContainerId: "1a2b3c4d5e6f",
+		Name:        "shop-web-1",
+		Image:       "nginx:1.25",
+		RefPodInfo: &kubernetes.K8sPodInfo{
+			ContainerIds: []string{"1a2b3c4d5e6f"},
+			Labels: map[string]string{
+				"com.docker.compose.project": "shop",
+				"com.docker.compose.service": "web",
+				"app.kubernetes.io/version":  "v1.2.0",
+			},
+			Dimensions:   map[string]string{"app_kubernetes_io_version": "v1.2.0"},
+			Namespace:    "shop",
+			WorkloadKind: ComposeKind,
+			WorkloadName: "web",
+		},
+	}, info)
+
+	// The container not found is not queried again before the retry interval.
+	_, ok = provider.GetByContainerId("ffffffffffff")
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requests) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		_, ok = provider.GetByContainerId("ffffffffffff")
+		assert.False(t, ok)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestProvider_ToContainerInfo(t *testing.T) {
+	provider := &Provider{}
+	testCases := []struct {
+		name         string
+		labels       map[string]string
+		namespace    string
+		podName      string
+		workloadKind string
+		workloadName string
+	}{
+		{"standalone", map[string]string{}, "", "", ContainerKind, "redis"},
+		{"compose", map[string]string{composeProjectLabel: "shop", composeServiceLabel: "cache"}, "shop", "", ComposeKind, "cache"},
+		{"pod", map[string]string{podNamespaceLabel: "default", podNameLabel: "redis-0"}, "default", "redis-0", ContainerKind, "redis"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := provider.toContainerInfo("1a2b3c4d5e6f", &containerMeta{Name: "redis", Image: "redis:7", Labels: tc.labels})
+			assert.Equal(t, "redis", info.Name)
+			assert.Equal(t, "redis:7", info.Image)
+			assert.Equal(t, tc.namespace, info.RefPodInfo.Namespace)
+			assert.Equal(t, tc.podName, info.RefPodInfo.PodName)
+			assert.Equal(t, tc.workloadKind, info.RefPodInfo.WorkloadKind)
+			assert.Equal(t, tc.workloadName, info.RefPodInfo.WorkloadName)
+		})
+	}
+}
+
+func TestNewProvider_UnsupportedRuntime(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Runtime = "rkt"
+	_, err := NewProvider(&cfg, nil, component.NewDefaultTelemetryTools().Logger)
+	assert.Error(t, err)
+}
@@ -14,6 +14,20 @@ type config struct {
 	// The default value is false. It should be enabled if the ReplicaSet
 	// is used to control pods in the third-party CRD except for Deployment.
 	EnableFetchReplicaSet bool
+	// EnableEndpointSlice controls whether to associate pods with services through the
+	// EndpointSlices, which also makes the endpoints of selector-less services found.
+	// The default value is false, in which case the selectors of services are matched
+	// with the labels of all pods.
+	EnableEndpointSlice bool
+	// EnableResolveOwnerChain controls whether to walk the owner references of pods up to
+	// the top-level controller, e.g. Pod->Job->CronJob. The owners are watched on demand.
+	EnableResolveOwnerChain bool
+	// OwnerChainStopKinds are the kinds at which the walk stops even if they have controllers.
+	OwnerChainStopKinds []string
+	// PodLabels and PodAnnotations are the keys of the pod labels and annotations
+	// that are captured as dimensions of the pod. The others are ignored.
+	PodLabels      []string
+	PodAnnotations []string
 }
 
 type Option func(cfg *config)
@@ -47,3 +61,30 @@ func WithFetchReplicaSet(fetch bool) Option {
 		cfg.EnableFetchReplicaSet = fetch
 	}
 }
+
+// WithPodDimensions sets the keys of the pod labels and annotations that are captured
+// as dimensions of the pod.
+func WithPodDimensions(labels []string, annotations []string) Option {
+	return func(cfg *config) {
+		cfg.PodLabels = labels
+		cfg.PodAnnotations = annotations
+	}
+}
+
+// WithEndpointSlice sets whether to resolve services through the EndpointSlices.
+func WithEndpointSlice(enable bool) Option {
+	return func(cfg *config) {
+		cfg.EnableEndpointSlice = enable
+	}
+}
+
+// WithResolveOwnerChain sets whether to resolve the owner chain of pods and the kinds
+// at which the walk stops. DefaultOwnerChainStopKinds is used if stopKinds is nil.
+func WithResolveOwnerChain(enable bool, stopKinds []string) Option {
+	return func(cfg *config) {
+		cfg.EnableResolveOwnerChain = enable
+		if stopKinds != nil {
+			cfg.OwnerChainStopKinds = stopKinds
+		}
+	}
+}
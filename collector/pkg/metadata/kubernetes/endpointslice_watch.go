@@ -0,0 +1,304 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const podKind = "Pod"
+
+// useEndpointSlice is set when initializing the kubernetes handler and is read-only afterwards.
+// If it is true, pods are associated with services through the EndpointSlices instead of
+// matching the selectors of services with the labels of all pods.
+var useEndpointSlice = false
+
+// endpointSliceInfo contains the fields of an EndpointSlice that we care about.
+type endpointSliceInfo struct {
+	Namespace   string
+	ServiceName string
+	// Addresses could be IPv4 or IPv6. FQDN addresses are ignored.
+	Addresses []string
+	Ports     []uint32
+	// Pods are the names of the pods referenced by the endpoints.
+	Pods []string
+}
+
+type endpointSliceMap struct {
+	// Key is ${namespace}/${name} of the EndpointSlice.
+	slices map[string]*endpointSliceInfo
+	// serviceSlices indexes the EndpointSlices by the services they belong to.
+	// Key is ${namespace}/${serviceName}. Value is a map whose key is the same as slices.
+	serviceSlices map[string]map[string]*endpointSliceInfo
+	// podService is the service of the pod.
+	// Key is ${namespace}/${podName}. Value is the name of the service.
+	podService map[string]string
+	mut        sync.RWMutex
+}
+
+var globalEndpointSlices = newEndpointSliceMap()
+var endpointSliceUpdatedMutex sync.Mutex
+
+func newEndpointSliceMap() *endpointSliceMap {
+	return &endpointSliceMap{
+		slices:        make(map[string]*endpointSliceInfo),
+		serviceSlices: make(map[string]map[string]*endpointSliceInfo),
+		podService:    make(map[string]string),
+	}
+}
+
+func (m *endpointSliceMap) add(key string, info *endpointSliceInfo) {
+	m.mut.Lock()
+	if old, ok := m.slices[key]; ok {
+		m.unindexService(key, old)
+	}
+	m.slices[key] = info
+	serviceKey := mapKey(info.Namespace, info.ServiceName)
+	slices, ok := m.serviceSlices[serviceKey]
+	if !ok {
+		slices = make(map[string]*endpointSliceInfo)
+		m.serviceSlices[serviceKey] = slices
+	}
+	slices[key] = info
+	for _, pod := range info.Pods {
+		m.podService[mapKey(info.Namespace, pod)] = info.ServiceName
+	}
+	m.mut.Unlock()
+}
+
+func (m *endpointSliceMap) delete(key string) (*endpointSliceInfo, bool) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	info, ok := m.slices[key]
+	if !ok {
+		return nil, false
+	}
+	delete(m.slices, key)
+	m.unindexService(key, info)
+	for _, pod := range info.Pods {
+		podKey := mapKey(info.Namespace, pod)
+		// The pod may have been moved to another service.
+		if m.podService[podKey] == info.ServiceName {
+			delete(m.podService, podKey)
+		}
+	}
+	return info, true
+}
+
+// getServiceSlices returns all the EndpointSlices of the service.
+func (m *endpointSliceMap) getServiceSlices(namespace string, serviceName string) []*endpointSliceInfo {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	slices := m.serviceSlices[mapKey(namespace, serviceName)]
+	ret := make([]*endpointSliceInfo, 0, len(slices))
+	for _, info := range slices {
+		ret = append(ret, info)
+	}
+	return ret
+}
+
+// unindexService removes the EndpointSlice from the index of its service.
+// The caller must hold the write lock.
+func (m *endpointSliceMap) unindexService(key string, info *endpointSliceInfo) {
+	serviceKey := mapKey(info.Namespace, info.ServiceName)
+	slices, ok := m.serviceSlices[serviceKey]
+	if !ok {
+		return
+	}
+	delete(slices, key)
+	if len(slices) == 0 {
+		delete(m.serviceSlices, serviceKey)
+	}
+}
+
+// getPodServiceName returns the name of the service that the pod belongs to.
+func (m *endpointSliceMap) getPodServiceName(namespace string, podName string) (string, bool) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	serviceName, ok := m.podService[mapKey(namespace, podName)]
+	return serviceName, ok
+}
+
+// EndpointSliceWatch watches the EndpointSlices of discovery.k8s.io/v1 if the api-server
+// supports it, and watches the ones of discovery.k8s.io/v1beta1 otherwise.
+func EndpointSliceWatch(clientSet *kubernetes.Clientset) {
+	stopper := make(chan struct{})
+	defer close(stopper)
+
+	factory := informers.NewSharedInformerFactory(clientSet, 0)
+	var informer cache.SharedIndexInformer
+	if _, err := clientSet.Discovery().ServerResourcesForGroupVersion(discoveryv1.SchemeGroupVersion.String()); err == nil {
+		informer = factory.Discovery().V1().EndpointSlices().Informer()
+	} else {
+		informer = factory.Discovery().V1beta1().EndpointSlices().Informer()
+	}
+	defer runtime.HandleCrash()
+
+	go factory.Start(stopper)
+
+	if !cache.WaitForCacheSync(stopper, informer.HasSynced) {
+		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+		return
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    onAddEndpointSlice,
+		UpdateFunc: OnUpdateEndpointSlice,
+		DeleteFunc: onDeleteEndpointSlice,
+	})
+	// TODO: use workqueue to avoid blocking
+	<-stopper
+}
+
+// toEndpointSliceInfo converts the EndpointSlice of any supported version.
+// It returns false if obj is not an EndpointSlice or the EndpointSlice is not managed for a service.
+func toEndpointSliceInfo(obj interface{}) (key string, info *endpointSliceInfo, resourceVersion string, ok bool) {
+	if deletedState, isDeleted := obj.(cache.DeletedFinalStateUnknown); isDeleted {
+		obj = deletedState.Obj
+	}
+	switch slice := obj.(type) {
+	case *discoveryv1.EndpointSlice:
+		info = &endpointSliceInfo{
+			Namespace:   slice.Namespace,
+			ServiceName: slice.Labels[discoveryv1.LabelServiceName],
+		}
+		if slice.AddressType != discoveryv1.AddressTypeFQDN {
+			for _, endpoint := range slice.Endpoints {
+				info.addEndpoint(endpoint.Addresses, endpoint.TargetRef)
+			}
+		}
+		for _, port := range slice.Ports {
+			info.addPort(port.Port)
+		}
+		key, resourceVersion = mapKey(slice.Namespace, slice.Name), slice.ResourceVersion
+	case *discoveryv1beta1.EndpointSlice:
+		info = &endpointSliceInfo{
+			Namespace:   slice.Namespace,
+			ServiceName: slice.Labels[discoveryv1beta1.LabelServiceName],
+		}
+		if slice.AddressType != discoveryv1beta1.AddressTypeFQDN {
+			for _, endpoint := range slice.Endpoints {
+				info.addEndpoint(endpoint.Addresses, endpoint.TargetRef)
+			}
+		}
+		for _, port := range slice.Ports {
+			info.addPort(port.Port)
+		}
+		key, resourceVersion = mapKey(slice.Namespace, slice.Name), slice.ResourceVersion
+	default:
+		return "", nil, "", false
+	}
+	if info.ServiceName == "" {
+		return "", nil, "", false
+	}
+	return key, info, resourceVersion, true
+}
+
+func (info *endpointSliceInfo) addEndpoint(addresses []string, targetRef *corev1.ObjectReference) {
+	info.Addresses = append(info.Addresses, addresses...)
+	if targetRef != nil && targetRef.Kind == podKind && targetRef.Name != "" {
+		info.Pods = append(info.Pods, targetRef.Name)
+	}
+}
+
+func (info *endpointSliceInfo) addPort(port *int32) {
+	// A nil port means all ports, which can't be indexed.
+	if port != nil {
+		info.Ports = append(info.Ports, uint32(*port))
+	}
+}
+
+func onAddEndpointSlice(obj interface{}) {
+	key, info, _, ok := toEndpointSliceInfo(obj)
+	if !ok {
+		return
+	}
+	globalEndpointSlices.add(key, info)
+	// If the service is not found, the EndpointSlice will be applied when the service is added.
+	if serviceInfo, ok := globalServiceInfo.get(info.Namespace, info.ServiceName); ok {
+		applyEndpointSlice(info, serviceInfo)
+	}
+}
+
+func OnUpdateEndpointSlice(objOld interface{}, objNew interface{}) {
+	_, _, oldVersion, ok := toEndpointSliceInfo(objOld)
+	if !ok {
+		// The old one is not managed for a service, so there is nothing to delete.
+		onAddEndpointSlice(objNew)
+		return
+	}
+	if _, _, newVersion, ok := toEndpointSliceInfo(objNew); ok && newVersion == oldVersion {
+		return
+	}
+	endpointSliceUpdatedMutex.Lock()
+	onDeleteEndpointSlice(objOld)
+	onAddEndpointSlice(objNew)
+	endpointSliceUpdatedMutex.Unlock()
+}
+
+func onDeleteEndpointSlice(obj interface{}) {
+	key, _, _, ok := toEndpointSliceInfo(obj)
+	if !ok {
+		return
+	}
+	info, ok := globalEndpointSlices.delete(key)
+	if !ok {
+		return
+	}
+	if serviceInfo, ok := globalServiceInfo.get(info.Namespace, info.ServiceName); ok {
+		unapplyEndpointSlice(info, serviceInfo)
+	}
+}
+
+// applyEndpointSlice makes the addresses of the EndpointSlice found by GetServiceByIpPort
+// and associates the pods of the EndpointSlice with the service.
+func applyEndpointSlice(info *endpointSliceInfo, serviceInfo *K8sServiceInfo) {
+	for _, address := range info.Addresses {
+		for _, port := range info.Ports {
+			MetaDataCache.AddServiceByIpPort(address, port, serviceInfo)
+		}
+	}
+	for _, pod := range info.Pods {
+		if podInfo, ok := globalPodInfo.get(info.Namespace, pod); ok {
+			serviceInfo.WorkloadKind = podInfo.WorkloadKind
+			serviceInfo.WorkloadName = podInfo.WorkloadName
+			podInfo.ServiceInfo = serviceInfo
+		}
+	}
+}
+
+func unapplyEndpointSlice(info *endpointSliceInfo, serviceInfo *K8sServiceInfo) {
+	for _, address := range info.Addresses {
+		for _, port := range info.Ports {
+			// The address may have been taken by another service.
+			if current, ok := MetaDataCache.GetServiceByIpPort(address, port); ok && current == serviceInfo {
+				MetaDataCache.DeleteServiceByIpPort(address, port)
+			}
+		}
+	}
+	for _, pod := range info.Pods {
+		if podInfo, ok := globalPodInfo.get(info.Namespace, pod); ok && podInfo.ServiceInfo == serviceInfo {
+			podInfo.ServiceInfo = nil
+		}
+	}
+}
+
+// getServiceOfPod returns the service of the pod found in the EndpointSlices.
+func getServiceOfPod(namespace string, podName string) *K8sServiceInfo {
+	serviceName, ok := globalEndpointSlices.getPodServiceName(namespace, podName)
+	if !ok {
+		return nil
+	}
+	serviceInfo, ok := globalServiceInfo.get(namespace, serviceName)
+	if !ok {
+		return nil
+	}
+	return serviceInfo
+}
@@ -0,0 +1,168 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func initEndpointSliceTest(t *testing.T) {
+	globalPodInfo = newPodMap()
+	globalServiceInfo = newServiceMap()
+	globalRsInfo = newReplicaSetMap()
+	globalEndpointSlices = newEndpointSliceMap()
+	MetaDataCache = New()
+	useEndpointSlice = true
+	t.Cleanup(func() {
+		useEndpointSlice = false
+		MetaDataCache = New()
+	})
+}
+
+func createEndpointSlice(name string, addressType discoveryv1.AddressType, addresses []string, podName string) *discoveryv1.EndpointSlice {
+	port := int32(3306)
+	endpoint := discoveryv1.Endpoint{Addresses: addresses}
+	if podName != "" {
+		endpoint.TargetRef = &corev1.ObjectReference{Kind: podKind, Namespace: "CustomNamespace", Name: podName}
+	}
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "CustomNamespace",
+			ResourceVersion: "1",
+			Labels:          map[string]string{discoveryv1.LabelServiceName: "CustomService"},
+		},
+		AddressType: addressType,
+		Endpoints:   []discoveryv1.Endpoint{endpoint},
+		Ports:       []discoveryv1.EndpointPort{{Port: &port}},
+	}
+}
+
+func TestEndpointSlice_SelectorlessService(t *testing.T) {
+	initEndpointSliceTest(t)
+	service := CreateService()
+	service.Spec.Selector = nil
+	// The EndpointSlices may be received before the service.
+	onAddEndpointSlice(createEndpointSlice("database-ipv4", discoveryv1.AddressTypeIPv4, []string{"10.0.5.5"}, ""))
+	onAddService(service)
+	onAddEndpointSlice(createEndpointSlice("database-ipv6", discoveryv1.AddressTypeIPv6, []string{"fd00::5"}, ""))
+	onAddEndpointSlice(createEndpointSlice("database-fqdn", discoveryv1.AddressTypeFQDN, []string{"db.example.com"}, ""))
+
+	for _, ip := range []string{"10.0.5.5", "fd00::5"} {
+		serviceInfo, ok := MetaDataCache.GetServiceByIpPort(ip, 3306)
+		if assert.True(t, ok, ip) {
+			assert.Equal(t, "CustomService", serviceInfo.ServiceName)
+		}
+	}
+	_, ok := MetaDataCache.GetServiceByIpPort("db.example.com", 3306)
+	assert.False(t, ok)
+
+	// The addresses are updated with the EndpointSlices.
+	newSlice := createEndpointSlice("database-ipv4", discoveryv1.AddressTypeIPv4, []string{"10.0.5.6"}, "")
+	newSlice.ResourceVersion = "2"
+	OnUpdateEndpointSlice(createEndpointSlice("database-ipv4", discoveryv1.AddressTypeIPv4, []string{"10.0.5.5"}, ""), newSlice)
+	_, ok = MetaDataCache.GetServiceByIpPort("10.0.5.5", 3306)
+	assert.False(t, ok)
+	_, ok = MetaDataCache.GetServiceByIpPort("10.0.5.6", 3306)
+	assert.True(t, ok)
+
+	// The addresses are removed with the service.
+	onDeleteService(service)
+	_, ok = MetaDataCache.GetServiceByIpPort("10.0.5.6", 3306)
+	assert.False(t, ok)
+	// And restored if the service is added again.
+	onAddService(service)
+	_, ok = MetaDataCache.GetServiceByIpPort("10.0.5.6", 3306)
+	assert.True(t, ok)
+}
+
+func TestEndpointSlice_PodService(t *testing.T) {
+	initEndpointSliceTest(t)
+	service := CreateService()
+	// The selector is not used when the EndpointSlices are enabled.
+	service.Spec.Selector = map[string]string{"not": "matched"}
+	onAddService(service)
+	pod := CreatePod(true)
+	isController := true
+	pod.OwnerReferences[0].Controller = &isController
+	onAdd(pod)
+	slice := createEndpointSlice("service-abcde", discoveryv1.AddressTypeIPv4, []string{pod.Status.PodIP}, pod.Name)
+	onAddEndpointSlice(slice)
+
+	podInfo, ok := globalPodInfo.get(pod.Namespace, pod.Name)
+	require.True(t, ok)
+	if assert.NotNil(t, podInfo.ServiceInfo) {
+		assert.Equal(t, "CustomService", podInfo.ServiceInfo.ServiceName)
+		assert.Equal(t, DeploymentKind, podInfo.ServiceInfo.WorkloadKind)
+	}
+
+	// The service is kept when the pod is updated.
+	onAdd(pod)
+	podInfo, ok = globalPodInfo.get(pod.Namespace, pod.Name)
+	require.True(t, ok)
+	if assert.NotNil(t, podInfo.ServiceInfo) {
+		assert.Equal(t, "CustomService", podInfo.ServiceInfo.ServiceName)
+	}
+
+	onDeleteEndpointSlice(slice)
+	assert.Nil(t, podInfo.ServiceInfo)
+	_, ok = globalEndpointSlices.getPodServiceName(pod.Namespace, pod.Name)
+	assert.False(t, ok)
+}
+
+func TestToEndpointSliceInfo(t *testing.T) {
+	port := int32(80)
+	slice := &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "service-abcde",
+			Namespace: "CustomNamespace",
+			Labels:    map[string]string{discoveryv1beta1.LabelServiceName: "CustomService"},
+		},
+		AddressType: discoveryv1beta1.AddressTypeIPv4,
+		Endpoints: []discoveryv1beta1.Endpoint{
+			{Addresses: []string{"172.10.1.2"}, TargetRef: &corev1.ObjectReference{Kind: podKind, Name: "pod-1"}},
+			{Addresses: []string{"172.10.1.3"}, TargetRef: &corev1.ObjectReference{Kind: "Node", Name: "node-1"}},
+		},
+		Ports: []discoveryv1beta1.EndpointPort{{Port: &port}, {}},
+	}
+	key, info, _, ok := toEndpointSliceInfo(slice)
+	require.True(t, ok)
+	assert.Equal(t, "CustomNamespace/service-abcde", key)
+	assert.Equal(t, &endpointSliceInfo{
+		Namespace:   "CustomNamespace",
+		ServiceName: "CustomService",
+		Addresses:   []string{"172.10.1.2", "172.10.1.3"},
+		Ports:       []uint32{80},
+		Pods:        []string{"pod-1"},
+	}, info)
+
+	// The EndpointSlices not managed for services are ignored.
+	delete(slice.Labels, discoveryv1beta1.LabelServiceName)
+	_, _, _, ok = toEndpointSliceInfo(slice)
+	assert.False(t, ok)
+}
+
+func TestEndpointSliceMap_ServiceSlices(t *testing.T) {
+	m := newEndpointSliceMap()
+	m.add("ns/db-1", &endpointSliceInfo{Namespace: "ns", ServiceName: "db"})
+	m.add("ns/db-2", &endpointSliceInfo{Namespace: "ns", ServiceName: "db"})
+	m.add("other/db-1", &endpointSliceInfo{Namespace: "other", ServiceName: "db"})
+	assert.Len(t, m.getServiceSlices("ns", "db"), 2)
+	assert.Len(t, m.getServiceSlices("other", "db"), 1)
+
+	// The EndpointSlice is moved to the new service if its label is changed.
+	m.add("ns/db-2", &endpointSliceInfo{Namespace: "ns", ServiceName: "cache"})
+	assert.Len(t, m.getServiceSlices("ns", "db"), 1)
+	assert.Len(t, m.getServiceSlices("ns", "cache"), 1)
+
+	m.delete("ns/db-1")
+	m.delete("ns/db-2")
+	assert.Empty(t, m.getServiceSlices("ns", "db"))
+	assert.NotContains(t, m.serviceSlices, "ns/db")
+	assert.Len(t, m.getServiceSlices("other", "db"), 1)
+}
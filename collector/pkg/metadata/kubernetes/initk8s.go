@@ -9,6 +9,7 @@ import (
 	"time"
 
 	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -69,6 +70,7 @@ func InitK8sHandler(options ...Option) error {
 			KubeConfigDir:         DefaultKubeConfigPath,
 			GraceDeletePeriod:     DefaultGraceDeletePeriod,
 			EnableFetchReplicaSet: false,
+			OwnerChainStopKinds:   DefaultOwnerChainStopKinds,
 		}
 		for _, option := range options {
 			option(&k8sConfig)
@@ -79,7 +81,19 @@ func InitK8sHandler(options ...Option) error {
 			retErr = fmt.Errorf("cannot connect to kubernetes: %w", err)
 			return
 		}
+		if k8sConfig.EnableResolveOwnerChain {
+			metadataClient, err := initMetadataClient(string(k8sConfig.KubeAuthType), k8sConfig.KubeConfigDir)
+			if err != nil {
+				retErr = fmt.Errorf("cannot connect to kubernetes: %w", err)
+				return
+			}
+			lookup := newMetadataOwnerLookup(clientSet.Discovery(), metadataClient)
+			lookup.onOwnerAdded = resolvePodsOfOwner
+			ownerChain = newOwnerChainResolver(lookup, k8sConfig.OwnerChainStopKinds)
+		}
 		IsInitSuccess = true
+		podDimensions = newPodDimensionFilter(k8sConfig.PodLabels, k8sConfig.PodAnnotations)
+		useEndpointSlice = k8sConfig.EnableEndpointSlice
 		go NodeWatch(clientSet)
 		time.Sleep(1 * time.Second)
 		if k8sConfig.EnableFetchReplicaSet {
@@ -88,6 +102,10 @@ func InitK8sHandler(options ...Option) error {
 		}
 		go ServiceWatch(clientSet)
 		time.Sleep(1 * time.Second)
+		if k8sConfig.EnableEndpointSlice {
+			go EndpointSliceWatch(clientSet)
+			time.Sleep(1 * time.Second)
+		}
 		go PodWatch(clientSet, k8sConfig.GraceDeletePeriod)
 		time.Sleep(1 * time.Second)
 	})
@@ -101,6 +119,21 @@ func initClientSet(authType string, dir string) (*k8s.Clientset, error) {
 	})
 }
 
+func initMetadataClient(authType string, dir string) (metadata.Interface, error) {
+	apiConf := APIConfig{
+		AuthType:     AuthType(authType),
+		AuthFilePath: dir,
+	}
+	if err := apiConf.Validate(); err != nil {
+		return nil, err
+	}
+	authConf, err := createRestConfig(apiConf)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.NewForConfig(authConf)
+}
+
 // MakeClient can take configuration if needed for other types of auth
 func makeClient(apiConf APIConfig) (*k8s.Clientset, error) {
 	if err := apiConf.Validate(); err != nil {
@@ -9,6 +9,8 @@ import (
 type K8sContainerInfo struct {
 	ContainerId string
 	Name        string
+	// Image is only known for the containers found by the ContainerProvider.
+	Image       string
 	HostPortMap map[int32]int32
 	RefPodInfo  *K8sPodInfo
 }
@@ -21,6 +23,9 @@ type K8sPodInfo struct {
 	HostPorts    []int32
 	ContainerIds []string
 	Labels       map[string]string
+	// Dimensions contains the whitelisted labels and annotations of the pod.
+	// The keys are converted by constlabels.PodDimensionName.
+	Dimensions map[string]string
 	// TODO: There may be multiple kinds of workload or services for the same pod
 	WorkloadKind  string
 	WorkloadName  string
@@ -75,6 +80,16 @@ type K8sMetaDataCache struct {
 	ipServiceInfo map[string]map[uint32]*K8sServiceInfo
 
 	hostPortInfo *HostPortMap
+
+	// containerProvider is used to find the containers that are not managed by Kubernetes.
+	containerProvider ContainerProvider
+}
+
+// ContainerProvider provides the metadata of the containers that are not found in the
+// Kubernetes metadata, e.g. the containers running on the plain Docker or containerd hosts.
+type ContainerProvider interface {
+	// GetByContainerId must not block, as it is called when processing each data.
+	GetByContainerId(containerId string) (*K8sContainerInfo, bool)
 }
 
 func New() *K8sMetaDataCache {
@@ -94,6 +109,12 @@ func (c *K8sMetaDataCache) AddByContainerId(containerId string, resource *K8sCon
 	c.cMut.Unlock()
 }
 
+// SetContainerProvider sets the provider used when the container is not found in the cache.
+// It should be called before the cache is used.
+func (c *K8sMetaDataCache) SetContainerProvider(provider ContainerProvider) {
+	c.containerProvider = provider
+}
+
 func (c *K8sMetaDataCache) GetByContainerId(containerId string) (*K8sContainerInfo, bool) {
 	c.cMut.RLock()
 	res, ok := c.containerIdInfo[containerId]
@@ -101,13 +122,14 @@ func (c *K8sMetaDataCache) GetByContainerId(containerId string) (*K8sContainerIn
 	if ok {
 		return res, ok
 	}
+	if c.containerProvider != nil {
+		return c.containerProvider.GetByContainerId(containerId)
+	}
 	return nil, false
 }
 
 func (c *K8sMetaDataCache) GetPodByContainerId(containerId string) (*K8sPodInfo, bool) {
-	c.cMut.RLock()
-	containerInfo, ok := c.containerIdInfo[containerId]
-	c.cMut.RUnlock()
+	containerInfo, ok := c.GetByContainerId(containerId)
 	if ok {
 		return containerInfo.RefPodInfo, ok
 	}
@@ -90,3 +90,32 @@ func TestK8sMetaDataCache_DeleteServiceByIpPort(t *testing.T) {
 		t.Fatalf("cache is not empty after deleting service")
 	}
 }
+
+type mapContainerProvider map[string]*K8sContainerInfo
+
+func (m mapContainerProvider) GetByContainerId(containerId string) (*K8sContainerInfo, bool) {
+	info, ok := m[containerId]
+	return info, ok
+}
+
+func TestK8sMetaDataCache_ContainerProvider(t *testing.T) {
+	k8sContainer := &K8sContainerInfo{ContainerId: "123abc456def", RefPodInfo: &K8sPodInfo{PodName: "pod1-xxx-123"}}
+	dockerContainer := &K8sContainerInfo{ContainerId: "789abc012def", RefPodInfo: &K8sPodInfo{WorkloadName: "web"}}
+	cache := New()
+	cache.AddByContainerId(k8sContainer.ContainerId, k8sContainer)
+	cache.SetContainerProvider(mapContainerProvider{
+		k8sContainer.ContainerId:    dockerContainer,
+		dockerContainer.ContainerId: dockerContainer,
+	})
+
+	// The containers of Kubernetes take precedence.
+	if info, ok := cache.GetByContainerId(k8sContainer.ContainerId); !ok || info != k8sContainer {
+		t.Errorf("GetByContainerId() = %v, want %v", info, k8sContainer)
+	}
+	if podInfo, ok := cache.GetPodByContainerId(dockerContainer.ContainerId); !ok || podInfo != dockerContainer.RefPodInfo {
+		t.Errorf("GetPodByContainerId() = %v, want %v", podInfo, dockerContainer.RefPodInfo)
+	}
+	if _, ok := cache.GetByContainerId("not-exist"); ok {
+		t.Errorf("GetByContainerId() found the container not existing")
+	}
+}
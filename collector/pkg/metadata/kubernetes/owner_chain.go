@@ -0,0 +1,247 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// maxOwnerChainDepth prevents the resolver from looping forever on broken owner references.
+	maxOwnerChainDepth = 10
+	// ownerInformerSyncTimeout is how long to wait for the informer of a new kind to sync.
+	ownerInformerSyncTimeout = 10 * time.Second
+	// ownerLookupRetryInterval is how long to wait before retrying a kind that can't be watched.
+	ownerLookupRetryInterval = 5 * time.Minute
+)
+
+// DefaultOwnerChainStopKinds keeps the workloads of the pods created by these kinds
+// the same as they are without resolving the owner chain, even if the kinds are
+// managed by other controllers like operators.
+var DefaultOwnerChainStopKinds = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// ownerChain is nil if resolving the owner chain is disabled.
+// It is set when initializing the kubernetes handler and is read-only afterwards.
+var ownerChain *ownerChainResolver
+
+// ownerLookup finds the controller of the kubernetes objects.
+type ownerLookup interface {
+	// getController returns the controller of the object, which is nil if the object
+	// has no controller. ok is false if the object can't be found.
+	getController(apiVersion string, kind string, namespace string, name string) (controller *metav1.OwnerReference, ok bool)
+}
+
+// ownerChainResolver walks the owner references up to the top-level controller.
+type ownerChainResolver struct {
+	lookup ownerLookup
+	// The kinds are in lowercase.
+	stopKinds map[string]bool
+}
+
+func newOwnerChainResolver(lookup ownerLookup, stopKinds []string) *ownerChainResolver {
+	kinds := make(map[string]bool, len(stopKinds))
+	for _, kind := range stopKinds {
+		kinds[strings.ToLower(kind)] = true
+	}
+	return &ownerChainResolver{
+		lookup:    lookup,
+		stopKinds: kinds,
+	}
+}
+
+// resolve returns the top-level controller of the object owned by the given controller.
+// The walk stops at the stopping kinds, the objects having no controller, or the objects
+// that can't be found. ok is false if not even the given controller can be found, in
+// which case the caller should fall back to other ways.
+func (r *ownerChainResolver) resolve(namespace string, owner *metav1.OwnerReference) (controller Controller, ok bool) {
+	controller = Controller{
+		Name:       owner.Name,
+		Kind:       owner.Kind,
+		APIVersion: owner.APIVersion,
+	}
+	for i := 0; i < maxOwnerChainDepth; i++ {
+		if r.stopKinds[strings.ToLower(controller.Kind)] {
+			return controller, true
+		}
+		next, found := r.lookup.getController(controller.APIVersion, controller.Kind, namespace, controller.Name)
+		if !found {
+			return controller, i > 0
+		}
+		if next == nil {
+			return controller, true
+		}
+		controller = Controller{
+			Name:       next.Name,
+			Kind:       next.Kind,
+			APIVersion: next.APIVersion,
+		}
+	}
+	return controller, true
+}
+
+type ownerLister struct {
+	lister     cache.GenericLister
+	namespaced bool
+	// starting is true while the informer is being started, in which case lister is nil.
+	starting bool
+	// failedAt is set if the kind can't be watched, in which case lister is nil.
+	failedAt time.Time
+}
+
+// metadataOwnerLookup finds the owners from the caches of the metadata informers, which are
+// started on demand for each kind met in the owner chains. The informers are started in the
+// background, so the owners of a new kind are not found until its cache is synced. onOwnerAdded
+// is called for each owner added to the caches afterwards, so the pods resolved without the owner
+// could be resolved again.
+type metadataOwnerLookup struct {
+	client  metadata.Interface
+	mapper  *restmapper.DeferredDiscoveryRESTMapper
+	factory metadatainformer.SharedInformerFactory
+	stopper chan struct{}
+	// onOwnerAdded is nil if no one cares about the owners added. It is set before the lookup is used.
+	onOwnerAdded func(namespace string, apiVersion string, kind string, name string)
+
+	mutex   sync.Mutex
+	listers map[schema.GroupVersionKind]*ownerLister
+}
+
+func newMetadataOwnerLookup(discoveryClient discovery.DiscoveryInterface, client metadata.Interface) *metadataOwnerLookup {
+	return &metadataOwnerLookup{
+		client:  client,
+		mapper:  restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		factory: metadatainformer.NewSharedInformerFactory(client, 0),
+		stopper: make(chan struct{}),
+		listers: make(map[schema.GroupVersionKind]*ownerLister),
+	}
+}
+
+func (l *metadataOwnerLookup) getController(apiVersion string, kind string, namespace string, name string) (*metav1.OwnerReference, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false
+	}
+	lister := l.getLister(gv.WithKind(kind))
+	if lister == nil {
+		return nil, false
+	}
+	var obj interface{}
+	if lister.namespaced {
+		obj, err = lister.lister.ByNamespace(namespace).Get(name)
+	} else {
+		obj, err = lister.lister.Get(name)
+	}
+	if err != nil {
+		return nil, false
+	}
+	objMeta, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return nil, false
+	}
+	return metav1.GetControllerOfNoCopy(objMeta), true
+}
+
+// getLister returns the lister of the kind, or nil if its informer is not ready. The informer is
+// started in the background if it is the first time the kind is met, and the kinds that can't be
+// watched are retried after ownerLookupRetryInterval.
+func (l *metadataOwnerLookup) getLister(gvk schema.GroupVersionKind) *ownerLister {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if lister, ok := l.listers[gvk]; ok {
+		if lister.lister != nil {
+			return lister
+		}
+		if lister.starting || time.Since(lister.failedAt) < ownerLookupRetryInterval {
+			return nil
+		}
+	}
+	l.listers[gvk] = &ownerLister{starting: true}
+	go l.start(gvk)
+	return nil
+}
+
+func (l *metadataOwnerLookup) start(gvk schema.GroupVersionKind) {
+	// ready is closed once the lister is returned by getLister. The owners added before are
+	// notified together afterwards, so they are found when the pods are resolved again.
+	ready := make(chan struct{})
+	lister, err := l.startInformer(gvk, ready)
+	l.mutex.Lock()
+	if err != nil {
+		l.listers[gvk] = &ownerLister{failedAt: time.Now()}
+		l.mutex.Unlock()
+		runtime.HandleError(fmt.Errorf("failed to watch %s for resolving the owner chain: %w", gvk.String(), err))
+		return
+	}
+	l.listers[gvk] = lister
+	l.mutex.Unlock()
+	close(ready)
+	if l.onOwnerAdded == nil {
+		return
+	}
+	objs, err := lister.lister.List(labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, obj := range objs {
+		l.notifyOwnerAdded(gvk, obj)
+	}
+}
+
+// startInformer must be called without the lock held because it waits for the cache to sync.
+// The owners added are notified after ready is closed.
+func (l *metadataOwnerLookup) startInformer(gvk schema.GroupVersionKind, ready <-chan struct{}) (*ownerLister, error) {
+	mapping, err := l.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		// The kind may be installed after the mapping is cached.
+		l.mapper.Reset()
+		return nil, err
+	}
+	// Check the permission before starting the informer, otherwise it keeps retrying.
+	_, err = l.client.Resource(mapping.Resource).List(context.Background(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	informer := l.factory.ForResource(mapping.Resource)
+	if l.onOwnerAdded != nil {
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				select {
+				case <-ready:
+					l.notifyOwnerAdded(gvk, obj)
+				default:
+				}
+			},
+		})
+	}
+	l.factory.Start(l.stopper)
+	ctx, cancel := context.WithTimeout(context.Background(), ownerInformerSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		return nil, fmt.Errorf("timed out waiting for caches to sync")
+	}
+	return &ownerLister{
+		lister:     informer.Lister(),
+		namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+	}, nil
+}
+
+func (l *metadataOwnerLookup) notifyOwnerAdded(gvk schema.GroupVersionKind, obj interface{}) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	l.onOwnerAdded(objMeta.GetNamespace(), gvk.GroupVersion().String(), gvk.Kind, objMeta.GetName())
+}
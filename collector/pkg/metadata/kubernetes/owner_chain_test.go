@@ -0,0 +1,201 @@
+package kubernetes
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// mapOwnerLookup is keyed by ${kind}/${name}.
+type mapOwnerLookup map[string]*metav1.OwnerReference
+
+func (m mapOwnerLookup) getController(_ string, kind string, _ string, name string) (*metav1.OwnerReference, bool) {
+	controller, ok := m[kind+"/"+name]
+	return controller, ok
+}
+
+func controllerRef(apiVersion string, kind string, name string) *metav1.OwnerReference {
+	isController := true
+	return &metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Controller: &isController,
+	}
+}
+
+func TestOwnerChainResolver_Resolve(t *testing.T) {
+	lookup := mapOwnerLookup{
+		"Job/backup-27812345":        controllerRef("batch/v1", "CronJob", "backup"),
+		"CronJob/backup":             nil,
+		"ReplicaSet/rollout-5d8f9c":  controllerRef("argoproj.io/v1alpha1", "Rollout", "rollout"),
+		"ReplicaSet/deploy-1a2b3c4d": controllerRef("apps/v1", "Deployment", "deploy"),
+		"Deployment/deploy":          controllerRef("example.com/v1", "Operator", "op"),
+		"Loop/a":                     controllerRef("example.com/v1", "Loop", "a"),
+	}
+	resolver := newOwnerChainResolver(lookup, DefaultOwnerChainStopKinds)
+	testCases := []struct {
+		name     string
+		owner    *metav1.OwnerReference
+		expected Controller
+		ok       bool
+	}{
+		{"cronjob", controllerRef("batch/v1", "Job", "backup-27812345"), Controller{"backup", "CronJob", "batch/v1"}, true},
+		{"not found owner of crd", controllerRef("apps/v1", "ReplicaSet", "rollout-5d8f9c"), Controller{"rollout", "Rollout", "argoproj.io/v1alpha1"}, true},
+		{"stop kind", controllerRef("apps/v1", "ReplicaSet", "deploy-1a2b3c4d"), Controller{"deploy", "Deployment", "apps/v1"}, true},
+		{"not found", controllerRef("apps/v1", "ReplicaSet", "unknown"), Controller{"unknown", "ReplicaSet", "apps/v1"}, false},
+		{"loop", controllerRef("example.com/v1", "Loop", "a"), Controller{"a", "Loop", "example.com/v1"}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controller, ok := resolver.resolve("default", tc.owner)
+			assert.Equal(t, tc.expected, controller)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+
+	// Walk up to the top-level controller without stopping kinds.
+	resolver = newOwnerChainResolver(lookup, nil)
+	controller, ok := resolver.resolve("default", controllerRef("apps/v1", "ReplicaSet", "deploy-1a2b3c4d"))
+	assert.True(t, ok)
+	assert.Equal(t, Controller{"op", "Operator", "example.com/v1"}, controller)
+}
+
+func TestMetadataOwnerLookup(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "batch/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "jobs", Kind: "Job", Namespaced: true, Verbs: []string{"list", "watch"}},
+			},
+		},
+	}
+	job := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "backup-27812345",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*controllerRef("batch/v1", "CronJob", "backup")},
+		},
+	}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, &metav1.PartialObjectMetadata{})
+	scheme.AddKnownTypeWithName(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "JobList"}, &metav1.PartialObjectMetadataList{})
+	client := fakemetadata.NewSimpleMetadataClient(scheme, job)
+	lookup := newMetadataOwnerLookup(discovery, client)
+	added := make(chan string, 1)
+	lookup.onOwnerAdded = func(namespace string, apiVersion string, kind string, name string) {
+		added <- namespace + "/" + apiVersion + "/" + kind + "/" + name
+	}
+	defer close(lookup.stopper)
+
+	// The informer is started in the background, and the owners are not found until it is synced.
+	_, ok := lookup.getController("batch/v1", "Job", "default", "backup-27812345")
+	assert.False(t, ok)
+	select {
+	case owner := <-added:
+		assert.Equal(t, "default/batch/v1/Job/backup-27812345", owner)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the owner added is not notified")
+	}
+	controller, ok := lookup.getController("batch/v1", "Job", "default", "backup-27812345")
+	if assert.True(t, ok) {
+		assert.Equal(t, "CronJob", controller.Kind)
+		assert.Equal(t, "backup", controller.Name)
+	}
+	_, ok = lookup.getController("batch/v1", "Job", "default", "not-exist")
+	assert.False(t, ok)
+	// The kind unknown to the api-server is not retried immediately.
+	unknown := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Unknown"}
+	_, ok = lookup.getController("example.com/v1", "Unknown", "default", "a")
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		lookup.mutex.Lock()
+		defer lookup.mutex.Unlock()
+		return !lookup.listers[unknown].starting
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok = lookup.getController("example.com/v1", "Unknown", "default", "a")
+	assert.False(t, ok)
+	lookup.mutex.Lock()
+	assert.Nil(t, lookup.listers[unknown].lister)
+	assert.False(t, lookup.listers[unknown].failedAt.IsZero())
+	lookup.mutex.Unlock()
+}
+
+func TestGetControllerKindNameWithOwnerChain(t *testing.T) {
+	ownerChain = newOwnerChainResolver(mapOwnerLookup{
+		"Job/backup-27812345": controllerRef("batch/v1", "CronJob", "backup"),
+		"CronJob/backup":      nil,
+	}, DefaultOwnerChainStopKinds)
+	defer func() {
+		ownerChain = nil
+	}()
+	globalRsInfo = newReplicaSetMap()
+
+	pod := CreatePod(true)
+	pod.OwnerReferences = []metav1.OwnerReference{*controllerRef("batch/v1", "Job", "backup-27812345")}
+	kind, name := getControllerKindName(pod)
+	assert.Equal(t, "cronjob", kind)
+	assert.Equal(t, "backup", name)
+
+	// Fall back to the ReplicaSet's name if the owner chain can't be resolved.
+	pod.OwnerReferences = []metav1.OwnerReference{*controllerRef("apps/v1", ReplicaSetKind, "deploy-1a2b3c4d")}
+	kind, name = getControllerKindName(pod)
+	assert.Equal(t, DeploymentKind, kind)
+	assert.Equal(t, "deploy", name)
+}
+
+func TestResolvePodsOfOwner(t *testing.T) {
+	lookup := mapOwnerLookup{}
+	ownerChain = newOwnerChainResolver(lookup, DefaultOwnerChainStopKinds)
+	globalPodInfo = newPodMap()
+	globalWorkload = newWorkloadMap()
+	MetaDataCache = New()
+	defer func() {
+		ownerChain = nil
+		globalPodInfo = newPodMap()
+		globalWorkload = newWorkloadMap()
+		MetaDataCache = New()
+		podLister = atomic.Value{}
+	}()
+
+	pod := CreatePod(true)
+	pod.Namespace = "default"
+	pod.OwnerReferences = []metav1.OwnerReference{*controllerRef("batch/v1", "Job", "backup-27812345")}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(pod))
+	podLister.Store(corelisters.NewPodLister(indexer))
+
+	// The Job is not in the caches yet, so the pod is attributed to the Job.
+	onAdd(pod)
+	podInfo, _ := globalPodInfo.get(pod.Namespace, pod.Name)
+	assert.Equal(t, "backup-27812345", podInfo.WorkloadName)
+
+	// The pods are resolved again after the Job is added.
+	lookup["Job/backup-27812345"] = controllerRef("batch/v1", "CronJob", "backup")
+	resolvePodsOfOwner("default", "batch/v1", "Job", "backup-27812345")
+	podInfo, _ = globalPodInfo.get(pod.Namespace, pod.Name)
+	assert.Equal(t, "cronjob", podInfo.WorkloadKind)
+	assert.Equal(t, "backup", podInfo.WorkloadName)
+	_, ok := globalWorkload.Info["default"]["backup-27812345"]
+	assert.False(t, ok)
+	assert.Contains(t, globalWorkload.Info["default"], "backup")
+
+	// The pods whose owner chains stopped at the CronJob are resolved again after it is added.
+	lookup["CronJob/backup"] = controllerRef("example.com/v1", "Operator", "op")
+	resolvePodsOfOwner("default", "batch/v1", "CronJob", "backup")
+	podInfo, _ = globalPodInfo.get(pod.Namespace, pod.Name)
+	assert.Equal(t, "example.com/v1/operator", podInfo.WorkloadKind)
+	assert.Equal(t, "op", podInfo.WorkloadName)
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// podDimensions is set when initializing the kubernetes handler and is read-only afterwards.
+var podDimensions = newPodDimensionFilter(nil, nil)
+
+var (
+	registeredDimensionsMutex sync.RWMutex
+	registeredDimensions      []string
+)
+
+// SetPodDimensions registers the keys of the pod labels and annotations attached by the
+// k8sprocessor. The aggregateprocessor and the exporters read them by PodDimensions when
+// they are created, so it must be called before building them.
+func SetPodDimensions(labels []string, annotations []string) {
+	keys := make([]string, 0, len(labels)+len(annotations))
+	seen := make(map[string]bool, len(labels)+len(annotations))
+	for _, key := range append(append([]string{}, labels...), annotations...) {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	registeredDimensionsMutex.Lock()
+	registeredDimensions = keys
+	registeredDimensionsMutex.Unlock()
+}
+
+// PodDimensions returns the keys registered by SetPodDimensions in order without duplicates.
+func PodDimensions() []string {
+	registeredDimensionsMutex.RLock()
+	defer registeredDimensionsMutex.RUnlock()
+	return append([]string(nil), registeredDimensions...)
+}
+
+// podDimensionFilter picks the whitelisted labels and annotations of pods.
+type podDimensionFilter struct {
+	// The keys are the original keys and the values are the names of the dimensions.
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func newPodDimensionFilter(labels []string, annotations []string) *podDimensionFilter {
+	return &podDimensionFilter{
+		labels:      dimensionNames(labels),
+		annotations: dimensionNames(annotations),
+	}
+}
+
+func dimensionNames(keys []string) map[string]string {
+	names := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		names[key] = constlabels.PodDimensionName(key)
+	}
+	return names
+}
+
+// extract returns the whitelisted labels and annotations of the pod, or nil if
+// there is none. A label wins if a label and an annotation have the same name.
+func (f *podDimensionFilter) extract(pod *corev1.Pod) map[string]string {
+	if len(f.labels) == 0 && len(f.annotations) == 0 {
+		return nil
+	}
+	var dimensions map[string]string
+	add := func(values map[string]string, names map[string]string) {
+		for key, name := range names {
+			value, ok := values[key]
+			if !ok {
+				continue
+			}
+			if dimensions == nil {
+				dimensions = make(map[string]string, len(f.labels)+len(f.annotations))
+			}
+			dimensions[name] = value
+		}
+	}
+	add(pod.Annotations, f.annotations)
+	add(pod.Labels, f.labels)
+	return dimensions
+}
@@ -6,13 +6,16 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	_ "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	_ "k8s.io/client-go/tools/clientcmd"
 	_ "k8s.io/client-go/util/homedir"
@@ -44,6 +47,10 @@ type WorkloadInfo struct {
 var globalPodInfo = newPodMap()
 var globalWorkload = newWorkloadMap()
 
+// podLister stores the corelisters.PodLister of the pods watched, which is used to resolve the
+// workloads of the pods again.
+var podLister atomic.Value
+
 func GetWorkloadDataGroup() []*model.DataGroup {
 	globalWorkload.mutex.RLock()
 	dataGroups := make([]*model.DataGroup, 0)
@@ -176,6 +183,7 @@ func PodWatch(clientSet *kubernetes.Clientset, graceDeletePeriod time.Duration)
 		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
 		return
 	}
+	podLister.Store(podInformer.Lister())
 	go podDeleteLoop(10*time.Second, graceDeletePeriod, stopper)
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    onAdd,
@@ -195,7 +203,14 @@ func onAdd(obj interface{}) {
 	rsUpdateMutex.RUnlock()
 
 	// Find one of the services of the pod
-	serviceInfoSlice := globalServiceInfo.GetServiceMatchLabels(pod.Namespace, pod.Labels)
+	var serviceInfoSlice []*K8sServiceInfo
+	if useEndpointSlice {
+		if serviceInfo := getServiceOfPod(pod.Namespace, pod.Name); serviceInfo != nil {
+			serviceInfoSlice = []*K8sServiceInfo{serviceInfo}
+		}
+	} else {
+		serviceInfoSlice = globalServiceInfo.GetServiceMatchLabels(pod.Namespace, pod.Labels)
+	}
 	var serviceInfo *K8sServiceInfo
 	if len(serviceInfoSlice) == 0 {
 		serviceInfo = nil
@@ -220,6 +235,7 @@ func onAdd(obj interface{}) {
 		HostPorts:     make([]int32, 0),
 		ContainerIds:  make([]string, 0, 2),
 		Labels:        pod.Labels,
+		Dimensions:    podDimensions.extract(pod),
 		WorkloadKind:  workloadTypeTmp,
 		WorkloadName:  workloadNameTmp,
 		NodeName:      pod.Spec.NodeName,
@@ -291,6 +307,13 @@ func getControllerKindName(pod *corev1.Pod) (workloadKind string, workloadName s
 		if owner.Controller == nil || *owner.Controller != true {
 			continue
 		}
+		if ownerChain != nil {
+			if controller, ok := ownerChain.resolve(pod.Namespace, &owner); ok {
+				workloadKind = CompleteGVK(controller.APIVersion, strings.ToLower(controller.Kind))
+				workloadName = controller.Name
+				return
+			}
+		}
 		if owner.Kind == ReplicaSetKind {
 			// The owner of Pod is ReplicaSet, and it is Workload such as Deployment for ReplicaSet.
 			// Therefore, find ReplicaSet's name in 'globalRsInfo' to find which kind of workload
@@ -313,6 +336,42 @@ func getControllerKindName(pod *corev1.Pod) (workloadKind string, workloadName s
 	return
 }
 
+// resolvePodsOfOwner resolves the workloads of the pods again after their owner is added to the
+// caches of the owner chain. The pods affected are those directly controlled by the owner, and
+// those whose owner chains stopped at the owner because its own controller was not found.
+func resolvePodsOfOwner(namespace string, apiVersion string, kind string, name string) {
+	lister, ok := podLister.Load().(corelisters.PodLister)
+	if !ok {
+		return
+	}
+	// All the pods are listed if the owner is not namespaced.
+	pods, err := lister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return
+	}
+	ownerWorkloadKind := CompleteGVK(apiVersion, strings.ToLower(kind))
+	for _, pod := range pods {
+		cachePodInfo, ok := globalPodInfo.get(pod.Namespace, pod.Name)
+		if !ok {
+			continue
+		}
+		controller := metav1.GetControllerOfNoCopy(pod)
+		ownedByController := controller != nil && controller.Kind == kind && controller.Name == name
+		stoppedAtOwner := cachePodInfo.WorkloadKind == ownerWorkloadKind && cachePodInfo.WorkloadName == name
+		if !ownedByController && !stoppedAtOwner {
+			continue
+		}
+		rsUpdateMutex.RLock()
+		workloadKind, workloadName := getControllerKindName(pod)
+		rsUpdateMutex.RUnlock()
+		if workloadKind == cachePodInfo.WorkloadKind && workloadName == cachePodInfo.WorkloadName {
+			continue
+		}
+		globalWorkload.delete(cachePodInfo.Namespace, cachePodInfo.WorkloadName)
+		onAdd(pod)
+	}
+}
+
 var rRegex = regexp.MustCompile(`^(.*)-[0-9a-z]+$`)
 
 func extractDeploymentName(replicaSetName string) string {
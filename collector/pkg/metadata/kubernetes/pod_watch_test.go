@@ -54,6 +54,53 @@ func TestOnAdd(t *testing.T) {
 	t.Log(MetaDataCache)
 }
 
+func TestOnAddPodDimensions(t *testing.T) {
+	globalPodInfo = &podMap{
+		Info: make(map[string]map[string]*K8sPodInfo),
+	}
+	globalServiceInfo = &ServiceMap{
+		ServiceMap: make(map[string]map[string]*K8sServiceInfo),
+	}
+	podDimensions = newPodDimensionFilter([]string{"app.kubernetes.io/version", "team", "tier"}, []string{"team", "owner"})
+	defer func() {
+		podDimensions = newPodDimensionFilter(nil, nil)
+	}()
+	pod := CreatePod(true)
+	pod.Labels["app.kubernetes.io/version"] = "v1.2.0"
+	pod.Labels["team"] = "payment"
+	pod.Annotations = map[string]string{
+		"team":    "ignored",
+		"owner":   "alice",
+		"ignored": "true",
+	}
+	onAdd(pod)
+	defer onDelete(pod)
+
+	podInfo, ok := globalPodInfo.get(pod.Namespace, pod.Name)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{
+		"app_kubernetes_io_version": "v1.2.0",
+		"team":                      "payment",
+		"owner":                     "alice",
+	}, podInfo.Dimensions)
+
+	// Nothing is captured by default.
+	podDimensions = newPodDimensionFilter(nil, nil)
+	onAdd(pod)
+	podInfo, ok = globalPodInfo.get(pod.Namespace, pod.Name)
+	require.True(t, ok)
+	assert.Nil(t, podInfo.Dimensions)
+}
+
+func TestSetPodDimensions(t *testing.T) {
+	defer SetPodDimensions(nil, nil)
+	SetPodDimensions([]string{"app.kubernetes.io/version", "team", ""}, []string{"team", "owner"})
+	assert.Equal(t, []string{"app.kubernetes.io/version", "team", "owner"}, PodDimensions())
+
+	SetPodDimensions(nil, nil)
+	assert.Empty(t, PodDimensions())
+}
+
 // ISSUE https://github.com/KindlingProject/kindling/issues/229
 func TestOnAddPodWhileReplicaSetUpdating(t *testing.T) {
 	globalPodInfo = &podMap{
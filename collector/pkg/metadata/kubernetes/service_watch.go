@@ -60,6 +60,17 @@ func (s *ServiceMap) GetServiceMatchLabels(namespace string, labels map[string]s
 	return retServiceInfoSlice
 }
 
+func (s *ServiceMap) get(namespace string, serviceName string) (*K8sServiceInfo, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	serviceNameMap, ok := s.ServiceMap[namespace]
+	if !ok {
+		return nil, false
+	}
+	serviceInfo, ok := serviceNameMap[serviceName]
+	return serviceInfo, ok
+}
+
 func (s *ServiceMap) add(info *K8sServiceInfo) {
 	s.mut.Lock()
 	serviceNameMap, ok := s.ServiceMap[info.Namespace]
@@ -84,6 +95,7 @@ func (s *ServiceMap) delete(namespace string, serviceName string) {
 			// - K8sMetaDataCache.ipContainerInfo
 			// - K8sMetaDataCache.ipServiceInfo
 			serviceInfo.emptySelf()
+			delete(serviceNameMap, serviceName)
 		}
 	}
 	s.mut.Unlock()
@@ -125,21 +137,27 @@ func onAddService(obj interface{}) {
 	}
 	globalServiceInfo.add(sI)
 	// When new service is added, podInfo should be updated
-	podInfoSlice := globalPodInfo.getPodsMatchSelectors(sI.Namespace, sI.Selector)
-	for _, podInfo := range podInfoSlice {
-		for _, containerId := range podInfo.ContainerIds {
-			if podInfo, ok := MetaDataCache.GetPodByContainerId(containerId); ok {
-				sI.WorkloadName = podInfo.WorkloadName
-				sI.WorkloadKind = podInfo.WorkloadKind
-				podInfo.ServiceInfo = sI
-			}
+	if useEndpointSlice {
+		for _, slice := range globalEndpointSlices.getServiceSlices(sI.Namespace, sI.ServiceName) {
+			applyEndpointSlice(slice, sI)
 		}
-		// update Ip-Pod Map
-		for _, port := range podInfo.Ports {
-			if podInfo, ok := MetaDataCache.GetPodByIpPort(podInfo.Ip, uint32(port)); ok {
-				sI.WorkloadName = podInfo.WorkloadName
-				sI.WorkloadKind = podInfo.WorkloadKind
-				podInfo.ServiceInfo = sI
+	} else {
+		podInfoSlice := globalPodInfo.getPodsMatchSelectors(sI.Namespace, sI.Selector)
+		for _, podInfo := range podInfoSlice {
+			for _, containerId := range podInfo.ContainerIds {
+				if podInfo, ok := MetaDataCache.GetPodByContainerId(containerId); ok {
+					sI.WorkloadName = podInfo.WorkloadName
+					sI.WorkloadKind = podInfo.WorkloadKind
+					podInfo.ServiceInfo = sI
+				}
+			}
+			// update Ip-Pod Map
+			for _, port := range podInfo.Ports {
+				if podInfo, ok := MetaDataCache.GetPodByIpPort(podInfo.Ip, uint32(port)); ok {
+					sI.WorkloadName = podInfo.WorkloadName
+					sI.WorkloadKind = podInfo.WorkloadKind
+					podInfo.ServiceInfo = sI
+				}
 			}
 		}
 	}
@@ -148,7 +166,9 @@ func onAddService(obj interface{}) {
 		return
 	}
 	for _, port := range service.Spec.Ports {
-		MetaDataCache.AddServiceByIpPort(service.Spec.ClusterIP, uint32(port.Port), sI)
+		for _, clusterIp := range getClusterIps(service) {
+			MetaDataCache.AddServiceByIpPort(clusterIp, uint32(port.Port), sI)
+		}
 		if sI.isNodePort {
 			nodeAddresses := globalNodeInfo.getAllNodeAddresses()
 			for _, nodeAddress := range nodeAddresses {
@@ -158,6 +178,15 @@ func onAddService(obj interface{}) {
 	}
 }
 
+// getClusterIps returns all the cluster IPs of the service, which contain both the IPv4
+// and IPv6 addresses if the service is dual-stack.
+func getClusterIps(service *corev1.Service) []string {
+	if len(service.Spec.ClusterIPs) == 0 {
+		return []string{service.Spec.ClusterIP}
+	}
+	return service.Spec.ClusterIPs
+}
+
 func OnUpdateService(objOld interface{}, objNew interface{}) {
 	oldSvc := objOld.(*corev1.Service)
 	newSvc := objNew.(*corev1.Service)
@@ -185,6 +214,13 @@ func onDeleteService(obj interface{}) {
 			return
 		}
 	}
+	if useEndpointSlice {
+		if serviceInfo, ok := globalServiceInfo.get(service.Namespace, service.Name); ok {
+			for _, slice := range globalEndpointSlices.getServiceSlices(service.Namespace, service.Name) {
+				unapplyEndpointSlice(slice, serviceInfo)
+			}
+		}
+	}
 	// 'delete' will delete all such service in MetaDataCache
 	globalServiceInfo.delete(service.Namespace, service.Name)
 	ip := service.Spec.ClusterIP
@@ -192,7 +228,9 @@ func onDeleteService(obj interface{}) {
 		return
 	}
 	for _, port := range service.Spec.Ports {
-		MetaDataCache.DeleteServiceByIpPort(ip, uint32(port.Port))
+		for _, clusterIp := range getClusterIps(service) {
+			MetaDataCache.DeleteServiceByIpPort(clusterIp, uint32(port.Port))
+		}
 		if service.Spec.Type == "NodePort" {
 			nodeAddresses := globalNodeInfo.getAllNodeAddresses()
 			for _, nodeAddress := range nodeAddresses {
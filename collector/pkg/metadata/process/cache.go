@@ -0,0 +1,96 @@
+package process
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	DefaultProcRoot  = "/proc"
+	DefaultCacheSize = 10000
+	// DefaultExitDelay keeps the exited processes for a while, because their data may
+	// still be in the pipelines when the procexit events are received.
+	DefaultExitDelay = 10 * time.Second
+)
+
+var (
+	// globalCache is shared by the processors of all the pipelines, and is expired by the
+	// procexit events. It is set when building the pipelines and is read-only afterwards.
+	globalCache *Cache
+	initOnce    sync.Once
+	initErr     error
+)
+
+// InitCache initializes the cache shared by all the pipelines. Only the first call takes effect.
+func InitCache(procRoot string, size int, exitDelay time.Duration) (*Cache, error) {
+	initOnce.Do(func() {
+		globalCache, initErr = NewCache(procRoot, size, exitDelay)
+	})
+	return globalCache, initErr
+}
+
+// ExpireProcess removes the process from the shared cache after the exit delay.
+// It does nothing if the cache is not initialized.
+func ExpireProcess(pid int) {
+	if globalCache != nil {
+		globalCache.Expire(pid)
+	}
+}
+
+// Cache caches the processes by pid. The processes not found are also cached, so that
+// they are not read again until they are evicted.
+type Cache struct {
+	reader    *reader
+	processes *lru.Cache
+	exitDelay time.Duration
+}
+
+type cacheEntry struct {
+	// info is nil if the process is not found.
+	info *Info
+}
+
+func NewCache(procRoot string, size int, exitDelay time.Duration) (*Cache, error) {
+	processes, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		reader:    newReader(procRoot),
+		processes: processes,
+		exitDelay: exitDelay,
+	}, nil
+}
+
+// Get returns the process, reading it from the proc filesystem if it is not cached.
+func (c *Cache) Get(pid int) (*Info, bool) {
+	if pid <= 0 {
+		return nil, false
+	}
+	if value, ok := c.processes.Get(pid); ok {
+		entry := value.(*cacheEntry)
+		return entry.info, entry.info != nil
+	}
+	info, err := c.reader.read(pid)
+	if err != nil {
+		info = nil
+	}
+	c.processes.Add(pid, &cacheEntry{info: info})
+	return info, info != nil
+}
+
+// Expire removes the process after the exit delay if it is cached.
+func (c *Cache) Expire(pid int) {
+	if !c.processes.Contains(pid) {
+		return
+	}
+	if c.exitDelay <= 0 {
+		c.processes.Remove(pid)
+		return
+	}
+	time.AfterFunc(c.exitDelay, func() {
+		c.processes.Remove(pid)
+	})
+}
@@ -0,0 +1,200 @@
+// Package process provides the metadata of the processes read from the proc filesystem,
+// which is used to identify the processes running outside the containers.
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxCmdlineLength limits the length of the command line kept for each process.
+	maxCmdlineLength = 256
+	// userHz is the unit of the start time in /proc/<pid>/stat, which is 100 on almost all systems.
+	userHz = 100
+)
+
+// Info is the metadata of a process.
+type Info struct {
+	Pid int
+	// Cmdline is the arguments joined by spaces. It is truncated if it is too long.
+	Cmdline string
+	// Exe is the path of the executable. It is empty if the link can't be read.
+	Exe string
+	// CgroupPath is the path of the cgroup v2, or the one of the systemd hierarchy of cgroup v1.
+	CgroupPath string
+	// SystemdUnit is the service or scope the process belongs to, which is parsed from CgroupPath.
+	SystemdUnit string
+	// User is the name of the real user, or the uid if the name can't be found.
+	User      string
+	StartTime time.Time
+}
+
+// reader reads the processes from the proc filesystem mounted at procRoot.
+type reader struct {
+	procRoot string
+	bootTime time.Time
+	// users maps the uids to the names, which is read from the /etc/passwd of the host.
+	users map[string]string
+}
+
+func newReader(procRoot string) *reader {
+	r := &reader{procRoot: procRoot}
+	r.bootTime, _ = readBootTime(filepath.Join(procRoot, "stat"))
+	// The root of the pid 1 is the root of the host even if the agent runs in a container.
+	r.users = readUsers(filepath.Join(procRoot, "1", "root", "etc", "passwd"))
+	return r
+}
+
+// read returns an error if the process doesn't exist. The fields that can't be read are left empty.
+func (r *reader) read(pid int) (*Info, error) {
+	pidDir := filepath.Join(r.procRoot, strconv.Itoa(pid))
+	cmdline, err := os.ReadFile(filepath.Join(pidDir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		Pid:     pid,
+		Cmdline: formatCmdline(cmdline),
+	}
+	info.Exe, _ = os.Readlink(filepath.Join(pidDir, "exe"))
+	if cgroup, err := os.ReadFile(filepath.Join(pidDir, "cgroup")); err == nil {
+		info.CgroupPath = parseCgroupPath(cgroup)
+		info.SystemdUnit = parseSystemdUnit(info.CgroupPath)
+	}
+	if status, err := os.ReadFile(filepath.Join(pidDir, "status")); err == nil {
+		if uid := parseUid(status); uid != "" {
+			info.User = uid
+			if name, ok := r.users[uid]; ok {
+				info.User = name
+			}
+		}
+	}
+	if stat, err := os.ReadFile(filepath.Join(pidDir, "stat")); err == nil && !r.bootTime.IsZero() {
+		if ticks, err := parseStartTicks(stat); err == nil {
+			info.StartTime = r.bootTime.Add(time.Duration(ticks) * time.Second / userHz)
+		}
+	}
+	return info, nil
+}
+
+func formatCmdline(cmdline []byte) string {
+	cmdline = bytes.TrimRight(cmdline, "\x00")
+	cmdline = bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})
+	if len(cmdline) > maxCmdlineLength {
+		cmdline = cmdline[:maxCmdlineLength]
+	}
+	return string(cmdline)
+}
+
+// parseCgroupPath parses the lines like "hierarchy-ID:controller-list:cgroup-path".
+// The path of cgroup v2 is preferred, and the one of the systemd hierarchy is used for cgroup v1.
+func parseCgroupPath(cgroup []byte) string {
+	var systemdPath string
+	scanner := bufio.NewScanner(bytes.NewReader(cgroup))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			return fields[2]
+		}
+		if fields[1] == "name=systemd" {
+			systemdPath = fields[2]
+		}
+	}
+	return systemdPath
+}
+
+// parseSystemdUnit returns the innermost service in the cgroup path, or the innermost scope
+// if there is no service, e.g. "nginx.service" for "/system.slice/nginx.service".
+func parseSystemdUnit(cgroupPath string) string {
+	var scope string
+	elements := strings.Split(cgroupPath, "/")
+	for i := len(elements) - 1; i >= 0; i-- {
+		if strings.HasSuffix(elements[i], ".service") {
+			return elements[i]
+		}
+		if scope == "" && strings.HasSuffix(elements[i], ".scope") {
+			scope = elements[i]
+		}
+	}
+	return scope
+}
+
+// parseUid returns the real uid in the line like "Uid:	1000	1000	1000	1000".
+func parseUid(status []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Uid:"))
+		if len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// parseStartTicks returns the 22nd field of /proc/<pid>/stat, which is the time the process
+// started after the system boot in clock ticks. The fields are counted after the command in
+// parentheses as it may contain spaces.
+func parseStartTicks(stat []byte) (uint64, error) {
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("invalid stat: %q", stat)
+	}
+	// The fields after the command start from the 3rd one.
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid stat: %q", stat)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// readBootTime reads the line like "btime 1697600000" in /proc/stat.
+func readBootTime(path string) (time.Time, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			seconds, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("btime not found in %s", path)
+}
+
+// readUsers reads the lines like "nginx:x:101:101::/var/lib/nginx:/sbin/nologin".
+func readUsers(path string) map[string]string {
+	users := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return users
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		users[fields[2]] = fields[0]
+	}
+	return users
+}
@@ -0,0 +1,104 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createProcRoot creates a proc filesystem containing the process 1234 started 10.5 seconds
+// after the boot time 1697600000.
+func createProcRoot(t *testing.T) string {
+	procRoot := t.TempDir()
+	writeFile(t, filepath.Join(procRoot, "stat"), "cpu  1 2 3 4\nbtime 1697600000\nprocesses 100\n")
+	writeFile(t, filepath.Join(procRoot, "1", "root", "etc", "passwd"),
+		"root:x:0:0:root:/root:/bin/bash\npostgres:x:999:999::/var/lib/postgresql:/bin/sh\n")
+	createProcess(t, procRoot, 1234)
+	return procRoot
+}
+
+func createProcess(t *testing.T, procRoot string, pid int) {
+	pidDir := filepath.Join(procRoot, strconv.Itoa(pid))
+	writeFile(t, filepath.Join(pidDir, "cmdline"), "/usr/lib/postgresql/14/bin/postgres\x00-D\x00/var/lib/postgresql/14/main\x00")
+	writeFile(t, filepath.Join(pidDir, "cgroup"), "0::/system.slice/postgresql@14-main.service\n")
+	writeFile(t, filepath.Join(pidDir, "status"), "Name:\tpostgres\nUid:\t999\t999\t999\t999\nGid:\t999\t999\t999\t999\n")
+	// The command contains spaces and parentheses.
+	writeFile(t, filepath.Join(pidDir, "stat"),
+		strconv.Itoa(pid)+" (postgres: (main)) S 1 1234 1234 0 -1 4194560 1 0 0 0 0 0 0 0 20 0 1 0 1050 221184 1 18446744073709551615")
+	require.NoError(t, os.Symlink("/usr/lib/postgresql/14/bin/postgres", filepath.Join(pidDir, "exe")))
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestReader_Read(t *testing.T) {
+	r := newReader(createProcRoot(t))
+	info, err := r.read(1234)
+	require.NoError(t, err)
+	assert.Equal(t, &Info{
+		Pid:         1234,
+		Cmdline:     "/usr/lib/postgresql/14/bin/postgres -D /var/lib/postgresql/14/main",
+		Exe:         "/usr/lib/postgresql/14/bin/postgres",
+		CgroupPath:  "/system.slice/postgresql@14-main.service",
+		SystemdUnit: "postgresql@14-main.service",
+		User:        "postgres",
+		StartTime:   time.Unix(1697600010, 500000000),
+	}, info)
+
+	_, err = r.read(5678)
+	assert.Error(t, err)
+}
+
+func TestParseCgroupPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cgroup   string
+		path     string
+		unitName string
+	}{
+		{"cgroup v2", "0::/system.slice/nginx.service\n", "/system.slice/nginx.service", "nginx.service"},
+		{"cgroup v1", "12:cpu,cpuacct:/system.slice/sshd.service\n1:name=systemd:/system.slice/sshd.service\n", "/system.slice/sshd.service", "sshd.service"},
+		{"session scope", "0::/user.slice/user-1000.slice/session-3.scope\n", "/user.slice/user-1000.slice/session-3.scope", "session-3.scope"},
+		{"service in scope", "0::/system.slice/app.service/worker.scope\n", "/system.slice/app.service/worker.scope", "app.service"},
+		{"root", "0::/\n", "/", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := parseCgroupPath([]byte(tc.cgroup))
+			assert.Equal(t, tc.path, path)
+			assert.Equal(t, tc.unitName, parseSystemdUnit(path))
+		})
+	}
+}
+
+func TestCache_Expire(t *testing.T) {
+	procRoot := createProcRoot(t)
+	cache, err := NewCache(procRoot, 10, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	info, ok := cache.Get(1234)
+	require.True(t, ok)
+	assert.Equal(t, "postgresql@14-main.service", info.SystemdUnit)
+
+	// The process not found is cached until it is expired.
+	_, ok = cache.Get(5678)
+	assert.False(t, ok)
+	createProcess(t, procRoot, 5678)
+	_, ok = cache.Get(5678)
+	assert.False(t, ok)
+	cache.Expire(5678)
+	// The process is kept until the exit delay passes.
+	_, ok = cache.Get(5678)
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		_, ok = cache.Get(5678)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
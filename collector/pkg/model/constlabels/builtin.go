@@ -0,0 +1,199 @@
+package constlabels
+
+import "strings"
+
+// builtinLabels are the values of the string constants of this package. builtin_test.go checks
+// that no constant is missing.
+var builtinLabels = map[string]struct{}{
+	AmqpChannel:               {},
+	AmqpErrMsg:                {},
+	AmqpExchange:              {},
+	AmqpMethod:                {},
+	AmqpQueue:                 {},
+	AmqpReplyCode:             {},
+	AmqpRoutingKey:            {},
+	Comm:                      {},
+	ConsumerId:                {},
+	Container:                 {},
+	ContainerId:               {},
+	ContainerImage:            {},
+	ContentDownloadNs:         {},
+	ContentKey:                {},
+	CustomRequestId:           {},
+	CustomStatus:              {},
+	DnatIp:                    {},
+	DnatPort:                  {},
+	DnsDomain:                 {},
+	DnsId:                     {},
+	DnsIp:                     {},
+	DnsRcode:                  {},
+	DstContainer:              {},
+	DstContainerId:            {},
+	DstContainerImage:         {},
+	DstIp:                     {},
+	DstNamespace:              {},
+	DstNode:                   {},
+	DstNodeIp:                 {},
+	DstPod:                    {},
+	DstPort:                   {},
+	DstService:                {},
+	DstWorkloadKind:           {},
+	DstWorkloadName:           {},
+	DubboErrorCode:            {},
+	EndTime:                   {},
+	EndTimestamp:              {},
+	Errno:                     {},
+	ErrorType:                 {},
+	ExternalClusterNamespace:  {},
+	GrpcStatus:                {},
+	HttpApmParentSpanId:       {},
+	HttpApmTraceId:            {},
+	HttpApmTraceType:          {},
+	HttpContinue:              {},
+	HttpMethod:                {},
+	HttpStatusCode:            {},
+	HttpUrl:                   {},
+	Ignored:                   {},
+	InternalClusterNamespace:  {},
+	Ip:                        {},
+	IsConvergent:              {},
+	IsError:                   {},
+	IsSent:                    {},
+	IsServer:                  {},
+	IsSlow:                    {},
+	KafkaApi:                  {},
+	KafkaCorrelationId:        {},
+	KafkaErrorCode:            {},
+	KafkaTopic:                {},
+	KafkaVersion:              {},
+	MemcachedCommand:          {},
+	MemcachedErrMsg:           {},
+	MemcachedHits:             {},
+	MemcachedKeyCount:         {},
+	MemcachedMisses:           {},
+	MongodbCollection:         {},
+	MongodbCommand:            {},
+	MongodbDatabase:           {},
+	MongodbErrCode:            {},
+	MongodbErrMsg:             {},
+	MongodbRequestId:          {},
+	Namespace:                 {},
+	NetWorkAnalyzeMetricGroup: {},
+	Node:                      {},
+	Oneway:                    {},
+	Operation:                 {},
+	Pid:                       {},
+	Pod:                       {},
+	Port:                      {},
+	ProcessCgroup:             {},
+	ProcessCmdline:            {},
+	ProcessExe:                {},
+	ProcessStartTime:          {},
+	ProcessSystemdUnit:        {},
+	ProcessUser:               {},
+	Protocol:                  {},
+	RedisCommand:              {},
+	RedisErrMsg:               {},
+	RequestContent:            {},
+	RequestDurationStatus:     {},
+	RequestIoBytes:            {},
+	RequestPayload:            {},
+	RequestProcessingStatus:   {},
+	RequestReqxferStatus:      {},
+	RequestSentNs:             {},
+	RequestTid:                {},
+	RequestTotalNs:            {},
+	ResponseContent:           {},
+	ResponseIoBytes:           {},
+	ResponsePayload:           {},
+	ResponseRspxferStatus:     {},
+	ResponseTid:               {},
+	RocketMQErrCode:           {},
+	RocketMQErrMsg:            {},
+	RocketMQOpaque:            {},
+	RocketMQRequestMsg:        {},
+	Service:                   {},
+	SpanAmqpErrorMsg:          {},
+	SpanAmqpExchange:          {},
+	SpanAmqpMethod:            {},
+	SpanAmqpQueue:             {},
+	SpanAmqpReplyCode:         {},
+	SpanAmqpRoutingKey:        {},
+	SpanDnsDomain:             {},
+	SpanDnsRCode:              {},
+	SpanDstContainerId:        {},
+	SpanDstContainerName:      {},
+	SpanDubboErrorCode:        {},
+	SpanDubboRequestBody:      {},
+	SpanDubboResponseBody:     {},
+	SpanGrpcPath:              {},
+	SpanGrpcStatusCode:        {},
+	SpanHttpEndpoint:          {},
+	SpanHttpMethod:            {},
+	SpanHttpRequestBody:       {},
+	SpanHttpRequestHeaders:    {},
+	SpanHttpResponseBody:      {},
+	SpanHttpResponseHeaders:   {},
+	SpanHttpStatusCode:        {},
+	SpanHttpTraceId:           {},
+	SpanHttpTraceType:         {},
+	SpanMemcachedCommand:      {},
+	SpanMemcachedErrorMsg:     {},
+	SpanMemcachedHits:         {},
+	SpanMemcachedMisses:       {},
+	SpanMongodbCollection:     {},
+	SpanMongodbCommand:        {},
+	SpanMongodbDatabase:       {},
+	SpanMongodbErrorCode:      {},
+	SpanMongodbErrorMsg:       {},
+	SpanMysqlErrorCode:        {},
+	SpanMysqlErrorMsg:         {},
+	SpanMysqlSql:              {},
+	SpanPostgresqlErrorMsg:    {},
+	SpanPostgresqlSql:         {},
+	SpanPostgresqlSqlState:    {},
+	SpanRedisCommand:          {},
+	SpanRedisErrorMsg:         {},
+	SpanRedisRequestPayload:   {},
+	SpanRocketMQErrMsg:        {},
+	SpanRocketMQRequestMsg:    {},
+	SpanSrcContainerId:        {},
+	SpanSrcContainerName:      {},
+	Sql:                       {},
+	SqlErrCode:                {},
+	SqlErrMsg:                 {},
+	SqlState:                  {},
+	SrcContainer:              {},
+	SrcContainerId:            {},
+	SrcContainerImage:         {},
+	SrcIp:                     {},
+	SrcNamespace:              {},
+	SrcNode:                   {},
+	SrcNodeIp:                 {},
+	SrcPod:                    {},
+	SrcPort:                   {},
+	SrcService:                {},
+	SrcWorkloadKind:           {},
+	SrcWorkloadName:           {},
+	StartTime:                 {},
+	StatusCode:                {},
+	Success:                   {},
+	ThreadName:                {},
+	Tid:                       {},
+	Timestamp:                 {},
+	Topic:                     {},
+	WaitingTTfbNs:             {},
+	WorkloadKind:              {},
+	WorkloadName:              {},
+}
+
+// IsBuiltin returns true if the label is one of the labels Kindling generates itself, including the
+// pod labels and annotations. The labels defined in the configuration, e.g. the fields of the custom
+// protocols, must not use these names, otherwise they overwrite the built-in labels.
+func IsBuiltin(name string) bool {
+	if _, ok := builtinLabels[name]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, SrcLabelPrefix) || strings.HasPrefix(name, DstLabelPrefix) ||
+		strings.HasPrefix(name, LabelPrefix)
+}
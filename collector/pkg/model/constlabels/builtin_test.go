@@ -0,0 +1,58 @@
+package constlabels
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIsBuiltin_AllConstants(t *testing.T) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.ValueSpec)
+				if !ok {
+					return true
+				}
+				for _, value := range spec.Values {
+					lit, ok := value.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					label, _ := strconv.Unquote(lit.Value)
+					if label != "" && !IsBuiltin(label) {
+						t.Errorf("%s is not a builtin label, add it to builtinLabels", label)
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+func TestIsBuiltin(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{RequestContent, true},
+		{CustomStatus, true},
+		{SrcLabelPrefix + "app", true},
+		{"myrpc_method", false},
+	}
+	for _, tt := range tests {
+		if got := IsBuiltin(tt.name); got != tt.want {
+			t.Errorf("IsBuiltin(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
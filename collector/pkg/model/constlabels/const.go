@@ -1,5 +1,7 @@
 package constlabels
 
+import "strings"
+
 const (
 	NoError = iota
 	ConnectFail
@@ -53,6 +55,28 @@ const (
 	Ip              = "ip"
 	Port            = "port"
 
+	// SrcLabelPrefix, DstLabelPrefix and LabelPrefix are prepended to the names
+	// returned by PodDimensionName to build the labels of whitelisted pod labels
+	// and annotations.
+	SrcLabelPrefix = "src_label_"
+	DstLabelPrefix = "dst_label_"
+	LabelPrefix    = "label_"
+
+	// SrcContainerImage, DstContainerImage and ContainerImage are only known for the
+	// containers found by querying the container runtime.
+	SrcContainerImage = "src_container_image"
+	DstContainerImage = "dst_container_image"
+	ContainerImage    = "container_image"
+
+	// The labels of the process where the event happens, which are read from the proc filesystem.
+	ProcessCmdline     = "process_cmdline"
+	ProcessExe         = "process_exe"
+	ProcessCgroup      = "process_cgroup"
+	ProcessSystemdUnit = "process_systemd_unit"
+	ProcessUser        = "process_user"
+	// ProcessStartTime is the unix timestamp in seconds.
+	ProcessStartTime = "process_start_time"
+
 	// EndTimestamp is the end timestamp of a trace
 	EndTimestamp = "end_timestamp"
 
@@ -98,6 +122,9 @@ const (
 	SpanHttpResponseHeaders = "http.response_headers"
 	SpanHttpResponseBody    = "http.response_body"
 
+	SpanGrpcPath       = "grpc.path"
+	SpanGrpcStatusCode = "grpc.status_code"
+
 	SpanDnsDomain = "dns.domain"
 	SpanDnsRCode  = "dns.rcode"
 
@@ -105,6 +132,16 @@ const (
 	SpanMysqlErrorCode = "mysql.error_code"
 	SpanMysqlErrorMsg  = "mysql.error_msg"
 
+	SpanPostgresqlSql      = "postgresql.sql"
+	SpanPostgresqlSqlState = "postgresql.sql_state"
+	SpanPostgresqlErrorMsg = "postgresql.error_msg"
+
+	SpanMongodbCommand    = "mongodb.command"
+	SpanMongodbDatabase   = "mongodb.database"
+	SpanMongodbCollection = "mongodb.collection"
+	SpanMongodbErrorCode  = "mongodb.error_code"
+	SpanMongodbErrorMsg   = "mongodb.error_msg"
+
 	SpanDubboErrorCode    = "dubbo.error_code"
 	SpanDubboRequestBody  = "dubbo.request_body"
 	SpanDubboResponseBody = "dubbo.response_body"
@@ -114,9 +151,21 @@ const (
 	SpanRedisRequestPayload  = "redis.request_payload"
 	SpanRedisResponsePayload = "redis.request_payload"
 
+	SpanMemcachedCommand  = "memcached.command"
+	SpanMemcachedHits     = "memcached.hits"
+	SpanMemcachedMisses   = "memcached.misses"
+	SpanMemcachedErrorMsg = "memcached.error_msg"
+
 	SpanRocketMQRequestMsg = "rocketmq.request_msg"
 	SpanRocketMQErrMsg     = "rocketmq.error_msg"
 
+	SpanAmqpMethod     = "amqp.method"
+	SpanAmqpExchange   = "amqp.exchange"
+	SpanAmqpRoutingKey = "amqp.routing_key"
+	SpanAmqpQueue      = "amqp.queue"
+	SpanAmqpReplyCode  = "amqp.reply_code"
+	SpanAmqpErrorMsg   = "amqp.error_msg"
+
 	SpanRequestPayload  = "request_payload"
 	SpanResponsePayload = "response_payload"
 
@@ -135,3 +184,15 @@ const (
 func IsNamespaceNotFound(namespace string) bool {
 	return namespace == ExternalClusterNamespace || namespace == InternalClusterNamespace
 }
+
+// PodDimensionName converts the key of a pod label or annotation into a label name
+// by replacing every character that is not a letter, a digit or an underscore with
+// an underscore, e.g. "app.kubernetes.io/version" becomes "app_kubernetes_io_version".
+func PodDimensionName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
@@ -9,23 +9,35 @@ const (
 	HttpUrl          = "http_url"
 	HttpApmTraceType = "trace_type"
 	HttpApmTraceId   = "trace_id"
-	HttpStatusCode   = "http_status_code"
-	HttpContinue     = "http_continue"
+	// HttpApmParentSpanId is the span id propagated in the trace headers of the request.
+	HttpApmParentSpanId = "parent_span_id"
+	HttpStatusCode      = "http_status_code"
+	HttpContinue        = "http_continue"
+
+	GrpcStatus = "grpc_status"
 
 	DnsId     = "dns_id"
 	DnsDomain = "dns_domain"
 	DnsRcode  = "dns_rcode"
 	DnsIp     = "dns_ip"
 
-	Oneway = "one_way"
+	Oneway  = "one_way"
+	Ignored = "ignored"
 
 	Sql        = "sql"
 	SqlErrCode = "sql_error_code"
 	SqlErrMsg  = "sql_error_msg"
+	SqlState   = "sql_state"
 
 	RedisCommand = "redis_command"
 	RedisErrMsg  = "redis_error_msg"
 
+	MemcachedCommand  = "memcached_command"
+	MemcachedKeyCount = "memcached_key_count"
+	MemcachedHits     = "memcached_hits"
+	MemcachedMisses   = "memcached_misses"
+	MemcachedErrMsg   = "memcached_error_msg"
+
 	KafkaApi           = "kafka_api"
 	KafkaVersion       = "kafka_version"
 	KafkaCorrelationId = "kafka_id"
@@ -38,4 +50,22 @@ const (
 	RocketMQRequestMsg = "rocketmq_request_msg"
 	RocketMQErrMsg     = "rocketmq_error_msg"
 	RocketMQErrCode    = "rocketmq_error_code"
+
+	AmqpMethod     = "amqp_method"
+	AmqpChannel    = "amqp_channel"
+	AmqpExchange   = "amqp_exchange"
+	AmqpRoutingKey = "amqp_routing_key"
+	AmqpQueue      = "amqp_queue"
+	AmqpReplyCode  = "amqp_reply_code"
+	AmqpErrMsg     = "amqp_error_msg"
+
+	MongodbRequestId  = "mongodb_request_id"
+	MongodbCommand    = "mongodb_command"
+	MongodbDatabase   = "mongodb_database"
+	MongodbCollection = "mongodb_collection"
+	MongodbErrCode    = "mongodb_error_code"
+	MongodbErrMsg     = "mongodb_error_msg"
+
+	CustomRequestId = "custom_request_id"
+	CustomStatus    = "custom_status"
 )
@@ -10,6 +10,7 @@ var metricNameDictionary = map[string]map[bool]string{
 	constvalues.RequestCount:              {true: EntityRequestCountMetric, false: TopologyRequestCountMetric},
 	constvalues.RequestTotalTime + "_avg": {true: EntityRequestLatencyAverageMetric, false: TopologyRequestLatencyAverageMetric},
 	constvalues.RequestTimeHistogram:      {true: EntityRequestTimeHistogramMetric, false: TopologyRequestTimeHistogramMetric},
+	constvalues.RequestTimeQuantile:       {true: EntityRequestLatencyQuantileMetric, false: TopologyRequestLatencyQuantileMetric},
 }
 
 const (
@@ -21,6 +22,8 @@ const (
 	TopologyRequestCountMetric          = "total"
 	// TopologyRequestTimeHistogramMetric is a histogram
 	TopologyRequestTimeHistogramMetric = "request_time_histogram"
+	// TopologyRequestLatencyQuantileMetric is a summary
+	TopologyRequestLatencyQuantileMetric = "duration_nanoseconds"
 
 	EntityRequestIoMetric  = "receive_bytes_total"
 	EntityResponseIoMetric = "send_bytes_total"
@@ -29,6 +32,8 @@ const (
 	EntityRequestLatencyTotalMetric   = "duration_nanoseconds_total"
 	EntityRequestCountMetric          = "total"
 	EntityRequestTimeHistogramMetric  = "request_time_histogram"
+	// EntityRequestLatencyQuantileMetric is a summary
+	EntityRequestLatencyQuantileMetric = "duration_nanoseconds"
 
 	TraceAsMetric           = NPMPrefixKindling + "_trace_request_duration_nanoseconds"
 	TcpRttMetricName        = "kindling_tcp_srtt_microseconds"
@@ -8,6 +8,7 @@ const (
 	WaitingTtfbTime      = "waiting_ttfb_time"
 	ContentDownloadTime  = "content_download_time"
 	RequestTimeHistogram = "request_time_histogram"
+	RequestTimeQuantile  = "request_time_quantile"
 
 	RequestIo  = "request_io"
 	ResponseIo = "response_io"
@@ -22,13 +23,17 @@ const (
 )
 
 const (
-	ProtocolHttp     = "http"
-	ProtocolHttp2    = "http2"
-	ProtocolGrpc     = "grpc"
-	ProtocolDubbo    = "dubbo"
-	ProtocolDns      = "dns"
-	ProtocolKafka    = "kafka"
-	ProtocolMysql    = "mysql"
-	ProtocolRedis    = "redis"
-	ProtocolRocketMQ = "rocketmq"
+	ProtocolHttp       = "http"
+	ProtocolHttp2      = "http2"
+	ProtocolGrpc       = "grpc"
+	ProtocolDubbo      = "dubbo"
+	ProtocolDns        = "dns"
+	ProtocolKafka      = "kafka"
+	ProtocolMysql      = "mysql"
+	ProtocolRedis      = "redis"
+	ProtocolRocketMQ   = "rocketmq"
+	ProtocolPostgresql = "postgresql"
+	ProtocolMongodb    = "mongodb"
+	ProtocolMemcached  = "memcached"
+	ProtocolAmqp       = "amqp"
 )
@@ -76,6 +76,9 @@ func (g DataGroup) String() string {
 		case HistogramMetricType:
 			histogram := v.GetHistogram()
 			str.WriteString(fmt.Sprintf("\t\t\"%s\": \n\t\t\tSum: %d\n\t\t\tCount: %d\n\t\t\tExplicitBoundaries: %v\n\t\t\tBucketCount: %v\n", v.Name, histogram.Sum, histogram.Count, histogram.ExplicitBoundaries, histogram.BucketCounts))
+		case SummaryMetricType:
+			summary := v.GetSummary()
+			str.WriteString(fmt.Sprintf("\t\t\"%s\": \n\t\t\tSum: %d\n\t\t\tCount: %d\n\t\t\tP50: %.0f\n\t\t\tP90: %.0f\n\t\t\tP99: %.0f\n", v.Name, summary.Sum, summary.Count, summary.Sketch.Quantile(0.5), summary.Sketch.Quantile(0.9), summary.Sketch.Quantile(0.99)))
 		}
 	}
 	if labelsStr, err := json.MarshalIndent(g.Labels, "\t", "\t"); err == nil {
@@ -1,8 +1,15 @@
 package model
 
+import (
+	"encoding/json"
+
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
+)
+
 const (
 	IntMetricType MetricType = iota
 	HistogramMetricType
+	SummaryMetricType
 	NoneMetricType
 )
 
@@ -13,6 +20,7 @@ type Metric struct {
 	//	Data can be assigned by:
 	//	Int
 	//	Histogram
+	//	Summary
 	Data isMetricData
 }
 
@@ -37,12 +45,21 @@ func (i *Metric) GetHistogram() *Histogram {
 	return nil
 }
 
+func (i *Metric) GetSummary() *Summary {
+	if x, ok := i.GetData().(*Summary); ok {
+		return x
+	}
+	return nil
+}
+
 func (i *Metric) DataType() MetricType {
 	switch i.GetData().(type) {
 	case *Int:
 		return IntMetricType
 	case *Histogram:
 		return HistogramMetricType
+	case *Summary:
+		return SummaryMetricType
 	default:
 		return NoneMetricType
 	}
@@ -58,6 +75,11 @@ func (i *Metric) Clear() {
 		histogram.Count = 0
 		histogram.Sum = 0
 		histogram.ExplicitBoundaries = nil
+	case SummaryMetricType:
+		summary := i.GetSummary()
+		summary.Sum = 0
+		summary.Count = 0
+		summary.Sketch = nil
 	}
 }
 
@@ -77,6 +99,14 @@ func (i *Metric) Clone() *Metric {
 			ExplicitBoundaries: histogram.ExplicitBoundaries,
 			BucketCounts:       histogram.BucketCounts,
 		}
+	case SummaryMetricType:
+		summary := i.GetSummary()
+		ret.Data = &Summary{
+			Sum:       summary.Sum,
+			Count:     summary.Count,
+			Sketch:    summary.Sketch.Copy(),
+			Quantiles: summary.Quantiles,
+		}
 	}
 	return ret
 }
@@ -104,9 +134,77 @@ func NewHistogramMetric(name string, histogram *Histogram) *Metric {
 	return &Metric{Name: name, Data: histogram}
 }
 
+// Summary describes the distribution of the values with a quantile sketch, so the quantiles like
+// p99 could be queried, and the summaries could be merged without losing the accuracy.
+type Summary struct {
+	Sum    int64
+	Count  uint64
+	Sketch *ddsketch.DDSketch
+	// Quantiles are the quantiles the exporters export, which are configured where the summary
+	// is aggregated. ddsketch.DefaultQuantiles is used if it is empty.
+	Quantiles []float64
+}
+
+// GetQuantiles returns the quantiles to be exported for the summary.
+func (s *Summary) GetQuantiles() []float64 {
+	if len(s.Quantiles) == 0 {
+		return ddsketch.DefaultQuantiles
+	}
+	return s.Quantiles
+}
+
+// QuantileValue is a quantile and its value estimated by the sketch of a summary.
+type QuantileValue struct {
+	Quantile float64
+	Value    float64
+}
+
+// summaryJSON is how a summary is marshaled. The quantiles are estimated for the readers of the
+// JSON, and the sketch is kept so the summary could be unmarshaled and merged again.
+type summaryJSON struct {
+	Sum       int64
+	Count     uint64
+	Quantiles []QuantileValue
+	Sketch    *ddsketch.DDSketch `json:",omitempty"`
+}
+
+func (s Summary) MarshalJSON() ([]byte, error) {
+	quantiles := s.GetQuantiles()
+	ret := summaryJSON{
+		Sum:       s.Sum,
+		Count:     s.Count,
+		Quantiles: make([]QuantileValue, len(quantiles)),
+		Sketch:    s.Sketch,
+	}
+	for i, q := range quantiles {
+		ret.Quantiles[i] = QuantileValue{Quantile: q, Value: s.Sketch.Quantile(q)}
+	}
+	return json.Marshal(&ret)
+}
+
+func (s *Summary) UnmarshalJSON(data []byte) error {
+	var value summaryJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	s.Sum = value.Sum
+	s.Count = value.Count
+	s.Sketch = value.Sketch
+	s.Quantiles = make([]float64, len(value.Quantiles))
+	for i, q := range value.Quantiles {
+		s.Quantiles[i] = q.Quantile
+	}
+	return nil
+}
+
+func NewSummaryMetric(name string, summary *Summary) *Metric {
+	return &Metric{Name: name, Data: summary}
+}
+
 type isMetricData interface {
 	isMetricData()
 }
 
 func (*Int) isMetricData()       {}
 func (*Histogram) isMetricData() {}
+func (*Summary) isMetricData()   {}
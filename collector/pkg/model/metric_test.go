@@ -3,6 +3,10 @@ package model
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/ddsketch"
 )
 
 func TestMetric_Marshal(t *testing.T) {
@@ -17,3 +21,41 @@ func TestMetric_Marshal(t *testing.T) {
 	}
 	t.Log(string(jsonString))
 }
+
+func TestSummary_JSON(t *testing.T) {
+	sketch := ddsketch.New(ddsketch.DefaultRelativeAccuracy)
+	for i := 1; i <= 100; i++ {
+		sketch.Add(float64(i * 1000))
+	}
+	metric := NewSummaryMetric("request_total_time", &Summary{
+		Sum:       5050000,
+		Count:     100,
+		Sketch:    sketch,
+		Quantiles: []float64{0.5, 0.99},
+	})
+	data, err := json.Marshal(metric)
+	assert.NoError(t, err)
+	var value struct {
+		Data struct {
+			Sum       int64
+			Count     uint64
+			Quantiles []QuantileValue
+		}
+	}
+	assert.NoError(t, json.Unmarshal(data, &value))
+	summary := value.Data
+	assert.Equal(t, int64(5050000), summary.Sum)
+	assert.Equal(t, uint64(100), summary.Count)
+	if assert.Len(t, summary.Quantiles, 2) {
+		assert.Equal(t, 0.5, summary.Quantiles[0].Quantile)
+		assert.InEpsilon(t, 50000, summary.Quantiles[0].Value, 0.01)
+		assert.Equal(t, 0.99, summary.Quantiles[1].Quantile)
+		assert.InEpsilon(t, 99000, summary.Quantiles[1].Value, 0.01)
+	}
+
+	data, err = json.Marshal(metric.GetSummary())
+	assert.NoError(t, err)
+	decoded := &Summary{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, metric.GetSummary(), decoded)
+}
@@ -0,0 +1,77 @@
+package tap
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// Filter selects the data to tap. The empty fields match all the data.
+type Filter struct {
+	Pid uint32
+	// ContainerId matches the container ids starting with it, so the short ids work too.
+	ContainerId string
+	// Port matches either the source port or the destination port.
+	Port uint32
+	// Protocol matches the L4 protocol of the events, e.g. "tcp", or the application protocol of
+	// the data groups, e.g. "http". It is case-insensitive.
+	Protocol string
+	// Name matches the name of the events, e.g. "syscall_exit-read", or the data groups.
+	Name string
+	// SampleRate is the ratio of the matched data to keep. All of them are kept if it is not in (0, 1).
+	SampleRate float64
+}
+
+func (f *Filter) matchEvent(evt *model.KindlingEvent) bool {
+	if f.Name != "" && evt.Name != f.Name {
+		return false
+	}
+	if f.Pid != 0 && evt.GetPid() != f.Pid {
+		return false
+	}
+	if f.ContainerId != "" && !matchContainerId(f.ContainerId, evt.GetContainerId()) {
+		return false
+	}
+	if f.Port != 0 && evt.GetSport() != f.Port && evt.GetDport() != f.Port {
+		return false
+	}
+	if f.Protocol != "" {
+		protocol := model.L4Proto_name[int32(evt.GetCtx().GetFdInfo().GetProtocol())]
+		if !strings.EqualFold(protocol, f.Protocol) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Filter) matchDataGroup(dataGroup *model.DataGroup) bool {
+	if f.Name != "" && dataGroup.Name != f.Name {
+		return false
+	}
+	labels := dataGroup.Labels
+	if labels == nil {
+		return f.Pid == 0 && f.ContainerId == "" && f.Port == 0 && f.Protocol == ""
+	}
+	if f.Pid != 0 && labels.GetIntValue(constlabels.Pid) != int64(f.Pid) {
+		return false
+	}
+	if f.ContainerId != "" &&
+		!matchContainerId(f.ContainerId, labels.GetStringValue(constlabels.ContainerId)) &&
+		!matchContainerId(f.ContainerId, labels.GetStringValue(constlabels.SrcContainerId)) &&
+		!matchContainerId(f.ContainerId, labels.GetStringValue(constlabels.DstContainerId)) {
+		return false
+	}
+	if f.Port != 0 && labels.GetIntValue(constlabels.SrcPort) != int64(f.Port) &&
+		labels.GetIntValue(constlabels.DstPort) != int64(f.Port) {
+		return false
+	}
+	if f.Protocol != "" && !strings.EqualFold(labels.GetStringValue(constlabels.Protocol), f.Protocol) {
+		return false
+	}
+	return true
+}
+
+func matchContainerId(prefix string, id string) bool {
+	return id != "" && strings.HasPrefix(id, prefix)
+}
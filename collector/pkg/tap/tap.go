@@ -0,0 +1,176 @@
+// Package tap streams the raw events received and the data groups produced by the analyzers to
+// the subscribers, so it is possible to see what the analyzers actually receive in production.
+package tap
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// Kind is the kind of the data tapped.
+type Kind string
+
+const (
+	// EventKind is the raw KindlingEvents before they are consumed by the analyzers.
+	EventKind Kind = "event"
+	// DataGroupKind is the DataGroups produced by the analyzers before they are processed.
+	DataGroupKind Kind = "datagroup"
+)
+
+var (
+	hub     *Hub
+	hubOnce sync.Once
+)
+
+// GetHub returns the Hub shared by the receivers, the analyzers and the HTTP controller.
+func GetHub() *Hub {
+	hubOnce.Do(func() {
+		hub = NewHub()
+	})
+	return hub
+}
+
+// Hub dispatches the data to the subscriptions matching them. Publishing costs only an atomic load
+// if there are no subscriptions of the kind, so it can be called for each event.
+type Hub struct {
+	eventSubscribers     int32
+	dataGroupSubscribers int32
+
+	mutex         sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscriptions: make(map[*Subscription]struct{})}
+}
+
+// Subscribe returns a subscription receiving the data of the kind matching the filter. At most
+// bufferSize data are buffered, and the data are dropped if the subscriber can't keep up with them.
+func (h *Hub) Subscribe(kind Kind, filter *Filter, bufferSize int) *Subscription {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	s := &Subscription{
+		kind:   kind,
+		filter: filter,
+		ch:     make(chan []byte, bufferSize),
+	}
+	h.mutex.Lock()
+	h.subscriptions[s] = struct{}{}
+	h.mutex.Unlock()
+	atomic.AddInt32(h.counter(kind), 1)
+	return s
+}
+
+// Unsubscribe stops sending data to the subscription.
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.subscriptions[s]; !ok {
+		return
+	}
+	delete(h.subscriptions, s)
+	atomic.AddInt32(h.counter(s.kind), -1)
+}
+
+func (h *Hub) counter(kind Kind) *int32 {
+	if kind == DataGroupKind {
+		return &h.dataGroupSubscribers
+	}
+	return &h.eventSubscribers
+}
+
+// PublishEvent sends the event to the subscriptions in the format of filereceiver, so the events
+// tapped can be replayed by it.
+func (h *Hub) PublishEvent(evt *model.KindlingEvent) {
+	if evt == nil || atomic.LoadInt32(&h.eventSubscribers) == 0 {
+		return
+	}
+	h.publish(EventKind, func(f *Filter) bool { return f.matchEvent(evt) }, func() ([]byte, error) {
+		return json.Marshal(evt)
+	})
+}
+
+func (h *Hub) PublishDataGroup(dataGroup *model.DataGroup) {
+	if dataGroup == nil || atomic.LoadInt32(&h.dataGroupSubscribers) == 0 {
+		return
+	}
+	h.publish(DataGroupKind, func(f *Filter) bool { return f.matchDataGroup(dataGroup) }, func() ([]byte, error) {
+		return json.Marshal(dataGroup)
+	})
+}
+
+// publish encodes the data at most once no matter how many subscriptions match it.
+func (h *Hub) publish(kind Kind, match func(f *Filter) bool, encode func() ([]byte, error)) {
+	var data []byte
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for s := range h.subscriptions {
+		if s.kind != kind || !match(s.filter) || !s.sample() {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = encode(); err != nil {
+				return
+			}
+		}
+		select {
+		case s.ch <- data:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	}
+}
+
+// DataGroupConsumer publishes the data groups it consumes. It is added to the consumers of
+// each analyzer.
+type DataGroupConsumer struct {
+	hub *Hub
+}
+
+func NewDataGroupConsumer(hub *Hub) *DataGroupConsumer {
+	return &DataGroupConsumer{hub: hub}
+}
+
+func (c *DataGroupConsumer) Consume(dataGroup *model.DataGroup) error {
+	c.hub.PublishDataGroup(dataGroup)
+	return nil
+}
+
+// Subscription receives the data encoded in JSON.
+type Subscription struct {
+	kind   Kind
+	filter *Filter
+	ch     chan []byte
+
+	matched uint64
+	dropped int64
+}
+
+func (s *Subscription) Kind() Kind {
+	return s.kind
+}
+
+// C returns the channel of the data. It is never closed.
+func (s *Subscription) C() <-chan []byte {
+	return s.ch
+}
+
+// Dropped returns the count of the data dropped because the buffer is full.
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// sample keeps the matched data evenly by Filter.SampleRate, e.g. one of every four if it is 0.25.
+func (s *Subscription) sample() bool {
+	rate := s.filter.SampleRate
+	if rate <= 0 || rate >= 1 {
+		return true
+	}
+	n := atomic.AddUint64(&s.matched, 1)
+	return uint64(float64(n)*rate) > uint64(float64(n-1)*rate)
+}
@@ -0,0 +1,96 @@
+package tap
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newEvent(name string, pid uint32, containerId string, sport, dport uint32) *model.KindlingEvent {
+	return &model.KindlingEvent{
+		Name: name,
+		Ctx: model.Context{
+			ThreadInfo: model.Thread{Pid: pid, Tid: pid, ContainerId: containerId},
+			FdInfo:     model.Fd{Protocol: model.L4Proto_TCP, Sport: sport, Dport: dport},
+		},
+	}
+}
+
+func received(s *Subscription) []string {
+	ret := make([]string, 0)
+	for {
+		select {
+		case data := <-s.C():
+			ret = append(ret, string(data))
+		default:
+			return ret
+		}
+	}
+}
+
+func TestFilterEvents(t *testing.T) {
+	hub := NewHub()
+	// Nothing is encoded without subscriptions.
+	hub.PublishEvent(newEvent("syscall_exit-read", 1, "", 80, 1234))
+
+	all := hub.Subscribe(EventKind, nil, 10)
+	byPid := hub.Subscribe(EventKind, &Filter{Pid: 2}, 10)
+	byContainer := hub.Subscribe(EventKind, &Filter{ContainerId: "abcdef"}, 10)
+	byPortAndProtocol := hub.Subscribe(EventKind, &Filter{Port: 8080, Protocol: "tcp"}, 10)
+	byName := hub.Subscribe(EventKind, &Filter{Name: "syscall_exit-write"}, 10)
+	dataGroups := hub.Subscribe(DataGroupKind, nil, 10)
+
+	hub.PublishEvent(newEvent("syscall_exit-read", 1, "abcdef123456", 80, 1234))
+	hub.PublishEvent(newEvent("syscall_exit-write", 2, "", 1234, 8080))
+
+	assert.Len(t, received(all), 2)
+	assert.Len(t, received(byPid), 1)
+	assert.Len(t, received(byContainer), 1)
+	assert.Len(t, received(byPortAndProtocol), 1)
+	events := received(byName)
+	if assert.Len(t, events, 1) {
+		// The events are encoded like the files of filereceiver.
+		evt := new(model.KindlingEvent)
+		assert.NoError(t, json.Unmarshal([]byte(events[0]), evt))
+		assert.Equal(t, uint32(2), evt.GetPid())
+	}
+	assert.Empty(t, received(dataGroups))
+
+	hub.Unsubscribe(all)
+	hub.PublishEvent(newEvent("syscall_exit-read", 1, "", 80, 1234))
+	assert.Empty(t, received(all))
+}
+
+func TestFilterDataGroups(t *testing.T) {
+	hub := NewHub()
+	byProtocol := hub.Subscribe(DataGroupKind, &Filter{Protocol: "HTTP", Port: 80}, 10)
+	byContainer := hub.Subscribe(DataGroupKind, &Filter{ContainerId: "dst"}, 10)
+
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.Protocol, "http")
+	labels.AddIntValue(constlabels.DstPort, 80)
+	labels.AddStringValue(constlabels.DstContainerId, "dst123")
+	consumer := NewDataGroupConsumer(hub)
+	assert.NoError(t, consumer.Consume(model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 0)))
+	assert.NoError(t, consumer.Consume(model.NewDataGroup(constnames.SingleNetRequestMetricGroup, model.NewAttributeMap(), 0)))
+
+	assert.Len(t, received(byProtocol), 1)
+	assert.Len(t, received(byContainer), 1)
+}
+
+func TestSampleAndDrop(t *testing.T) {
+	hub := NewHub()
+	sampled := hub.Subscribe(EventKind, &Filter{SampleRate: 0.25}, 100)
+	full := hub.Subscribe(EventKind, nil, 2)
+	for i := 0; i < 8; i++ {
+		hub.PublishEvent(newEvent("syscall_exit-read", 1, "", 80, 1234))
+	}
+	assert.Len(t, received(sampled), 2)
+	assert.Len(t, received(full), 2)
+	assert.Equal(t, int64(6), full.Dropped())
+}
@@ -0,0 +1,247 @@
+package urlclustering
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	VariableSegment = "{var}"
+
+	defaultMaxChildren = 20
+	defaultMaxNodes    = 10000
+	maxSegmentLength   = 25
+)
+
+// DrainClusteringMethod learns the templates of endpoints online like the log parser Drain does.
+// The segments of the observed endpoints are stored in a prefix tree. The segments that obviously
+// are variables, like numbers and UUIDs, are masked as "{var}" before being stored. When a node has
+// more than maxChildren different children, the children are merged into one "{var}" child, so that
+// /users/john/orders and /users/jane/orders are clustered into /users/{var}/orders after enough
+// users are seen, while readable segments with low cardinality are kept.
+//
+// The memory is bounded by maxNodes. The new segments are clustered as "{var}" once the tree is full.
+// This method is thread-safe.
+type DrainClusteringMethod struct {
+	maxChildren int
+	maxNodes    int
+
+	mutex     sync.Mutex
+	root      *drainNode
+	nodeCount int
+}
+
+type drainNode struct {
+	children map[string]*drainNode
+	// saturated means the literal children have been merged into the variable child.
+	saturated bool
+	// count is the number of the endpoints which end at this node.
+	count int64
+}
+
+func newDrainNode() *drainNode {
+	return &drainNode{children: make(map[string]*drainNode)}
+}
+
+// Template is a learned template and the number of the endpoints clustered into it.
+type Template struct {
+	Template string `json:"template"`
+	Count    int64  `json:"count"`
+}
+
+var (
+	drainMethod     *DrainClusteringMethod
+	drainMethodOnce sync.Once
+)
+
+// GetDrainClusteringMethod returns the DrainClusteringMethod shared by all HTTP parsers, so that
+// the templates are learned from all the endpoints and could be exposed in one place.
+func GetDrainClusteringMethod() *DrainClusteringMethod {
+	drainMethodOnce.Do(func() {
+		drainMethod = NewDrainClusteringMethod(defaultMaxChildren, defaultMaxNodes)
+	})
+	return drainMethod
+}
+
+func NewDrainClusteringMethod(maxChildren int, maxNodes int) *DrainClusteringMethod {
+	if maxChildren <= 0 {
+		maxChildren = defaultMaxChildren
+	}
+	if maxNodes <= 0 {
+		maxNodes = defaultMaxNodes
+	}
+	return &DrainClusteringMethod{
+		maxChildren: maxChildren,
+		maxNodes:    maxNodes,
+		root:        newDrainNode(),
+		nodeCount:   1,
+	}
+}
+
+func (m *DrainClusteringMethod) Clustering(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+	endpoint = strings.TrimSpace(endpoint)
+	if index := strings.Index(endpoint, "?"); index != -1 {
+		endpoint = endpoint[:index]
+	}
+	segments := strings.Split(endpoint, "/")
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	node := m.root
+	for i, segment := range segments {
+		if isVariableSegment(segment) {
+			segment = VariableSegment
+		}
+		node, segment = m.child(node, segment)
+		segments[i] = segment
+		if node == nil {
+			// The tree is full, so the rest segments are not learned.
+			for j := i + 1; j < len(segments); j++ {
+				segments[j] = VariableSegment
+			}
+			return strings.Join(segments, "/")
+		}
+	}
+	node.count++
+	return strings.Join(segments, "/")
+}
+
+// child returns the child which the segment is clustered into, creating it if necessary.
+// The returned node is nil if the tree is full.
+func (m *DrainClusteringMethod) child(node *drainNode, segment string) (*drainNode, string) {
+	if child, ok := node.children[segment]; ok {
+		return child, segment
+	}
+	if node.saturated {
+		return node.children[VariableSegment], VariableSegment
+	}
+	if m.nodeCount >= m.maxNodes {
+		if child, ok := node.children[VariableSegment]; ok {
+			return child, VariableSegment
+		}
+		return nil, VariableSegment
+	}
+	child := newDrainNode()
+	node.children[segment] = child
+	m.nodeCount++
+	if segment != VariableSegment && m.literalChildren(node) > m.maxChildren {
+		m.saturate(node)
+		return node.children[VariableSegment], VariableSegment
+	}
+	return child, segment
+}
+
+func (m *DrainClusteringMethod) literalChildren(node *drainNode) int {
+	count := len(node.children)
+	if _, ok := node.children[VariableSegment]; ok {
+		count--
+	}
+	return count
+}
+
+// saturate merges all the children of the node into its variable child.
+func (m *DrainClusteringMethod) saturate(node *drainNode) {
+	variable, ok := node.children[VariableSegment]
+	if !ok {
+		variable = newDrainNode()
+		m.nodeCount++
+	}
+	for segment, child := range node.children {
+		if segment != VariableSegment {
+			m.merge(variable, child)
+		}
+	}
+	node.children = map[string]*drainNode{VariableSegment: variable}
+	node.saturated = true
+}
+
+// merge moves the subtree of src into dst.
+func (m *DrainClusteringMethod) merge(dst *drainNode, src *drainNode) {
+	// src itself is dropped.
+	m.nodeCount--
+	dst.count += src.count
+	for segment, srcChild := range src.children {
+		if dst.saturated && segment != VariableSegment {
+			segment = VariableSegment
+		}
+		dstChild, ok := dst.children[segment]
+		if !ok {
+			dst.children[segment] = srcChild
+			continue
+		}
+		m.merge(dstChild, srcChild)
+	}
+	if !dst.saturated && m.literalChildren(dst) > m.maxChildren {
+		m.saturate(dst)
+	}
+}
+
+// Templates returns the learned templates sorted by their counts.
+func (m *DrainClusteringMethod) Templates() []Template {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	templates := make([]Template, 0)
+	var walk func(node *drainNode, segments []string)
+	walk = func(node *drainNode, segments []string) {
+		if node.count > 0 && len(segments) > 0 {
+			templates = append(templates, Template{Template: strings.Join(segments, "/"), Count: node.count})
+		}
+		for segment, child := range node.children {
+			walk(child, append(segments, segment))
+		}
+	}
+	walk(m.root, make([]string, 0))
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Count != templates[j].Count {
+			return templates[i].Count > templates[j].Count
+		}
+		return templates[i].Template < templates[j].Template
+	})
+	return templates
+}
+
+// Reset forgets all the learned templates.
+func (m *DrainClusteringMethod) Reset() {
+	m.mutex.Lock()
+	m.root = newDrainNode()
+	m.nodeCount = 1
+	m.mutex.Unlock()
+}
+
+// isVariableSegment returns true if the segment is a variable obviously, like the numbers, UUIDs,
+// hex strings and the segments which are too long.
+func isVariableSegment(segment string) bool {
+	if segment == VariableSegment || len(segment) > maxSegmentLength {
+		return true
+	}
+	digits, hexLetters, others := 0, 0, 0
+	for i := 0; i < len(segment); i++ {
+		b := segment[i]
+		switch {
+		case b >= '0' && b <= '9':
+			digits++
+		case (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F'):
+			hexLetters++
+		case b == '-' || b == '_' || b == '.' || b == ':':
+		default:
+			others++
+		}
+	}
+	if digits == 0 {
+		return false
+	}
+	// Numbers like 123, 1.5 and 2023-06-30
+	if hexLetters == 0 && others == 0 {
+		return true
+	}
+	// Hex strings and UUIDs
+	if others == 0 && digits+hexLetters >= 8 {
+		return true
+	}
+	// Mixed segments like "a1b2c3d4e5". Short ones like "v1" and "oauth2" are kept.
+	return digits*2 >= len(segment) && len(segment) > 4
+}
@@ -0,0 +1,56 @@
+package urlclustering
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainClusteringMethod_Clustering(t *testing.T) {
+	method := NewDrainClusteringMethod(0, 0)
+	testCases := []testCase{
+		{"", ""},
+		{"/", "/"},
+		{" /users/john/orders?page=2", "/users/john/orders"},
+		{"/users/1234/orders", "/users/{var}/orders"},
+		{"/orders/2f1c8a9e-6b4d-4c2a-9f3e-1a2b3c4d5e6f", "/orders/{var}"},
+		{"/api/v1/items", "/api/v1/items"},
+		{"/a//b/c?d=2&e=3", "/a//b/c"},
+	}
+	for _, c := range testCases {
+		assert.Equal(t, c.want, method.Clustering(c.endpoint))
+	}
+}
+
+func TestDrainClusteringMethod_Learning(t *testing.T) {
+	method := NewDrainClusteringMethod(3, 100)
+	for _, user := range []string{"john", "jane", "bob"} {
+		assert.Equal(t, "/users/"+user+"/orders", method.Clustering("/users/"+user+"/orders"))
+	}
+	// The readable segments with low cardinality are kept.
+	assert.Equal(t, "/users/john/profile", method.Clustering("/users/john/profile"))
+	// The 4th user exceeds maxChildren, so the users are merged.
+	assert.Equal(t, "/users/{var}/orders", method.Clustering("/users/alice/orders"))
+	assert.Equal(t, "/users/{var}/orders", method.Clustering("/users/john/orders"))
+	assert.Equal(t, "/users/{var}/profile", method.Clustering("/users/tom/profile"))
+
+	assert.Equal(t, []Template{
+		{Template: "/users/{var}/orders", Count: 5},
+		{Template: "/users/{var}/profile", Count: 2},
+	}, method.Templates())
+
+	method.Reset()
+	assert.Empty(t, method.Templates())
+}
+
+func TestDrainClusteringMethod_MaxNodes(t *testing.T) {
+	method := NewDrainClusteringMethod(1000, 10)
+	for i := 0; i < 20; i++ {
+		method.Clustering(fmt.Sprintf("/path%c/leaf", 'a'+i))
+	}
+	assert.LessOrEqual(t, method.nodeCount, 10)
+	// The new segments are clustered as variables once the tree is full.
+	assert.Equal(t, "/{var}/{var}", method.Clustering("/other/leaf"))
+	assert.Equal(t, "/patha/leaf", method.Clustering("/patha/leaf"))
+}
@@ -8,6 +8,8 @@ func NewMethod(urlClusteringMethod string) ClusteringMethod {
 		return NewNoParamClusteringMethod()
 	case "blank":
 		return NewBlankClusteringMethod()
+	case "drain":
+		return GetDrainClusteringMethod()
 	default:
 		return NewAlphabeticalClusteringMethod()
 	}
@@ -0,0 +1,56 @@
+package urlclustering
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// openAPIDocument contains the fields used to build the route templates. Both Swagger 2.0 and
+// OpenAPI 3.x documents are supported.
+type openAPIDocument struct {
+	// BasePath is the prefix of all the paths in Swagger 2.0.
+	BasePath string `yaml:"basePath"`
+	// Servers are the base URLs of all the paths in OpenAPI 3.x. Only the path of the first one is used.
+	Servers []struct {
+		Url string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]interface{} `yaml:"paths"`
+}
+
+// LoadOpenAPITemplates reads the OpenAPI or Swagger document in YAML or JSON format and returns
+// its paths as the route templates, like /v1/orders/{orderId}.
+func LoadOpenAPITemplates(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OpenAPI document: %w", err)
+	}
+	var document openAPIDocument
+	// JSON is a subset of YAML, so both formats are decoded in the same way.
+	if err = yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode the OpenAPI document %s: %w", path, err)
+	}
+	if len(document.Paths) == 0 {
+		return nil, fmt.Errorf("no paths are found in the OpenAPI document %s", path)
+	}
+
+	prefix := document.BasePath
+	if prefix == "" && len(document.Servers) > 0 {
+		if serverUrl, err := url.Parse(document.Servers[0].Url); err == nil {
+			prefix = serverUrl.Path
+		}
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	templates := make([]string, 0, len(document.Paths))
+	for p := range document.Paths {
+		templates = append(templates, prefix+p)
+	}
+	// Keep the order stable for the routes with the same number of literal segments.
+	sort.Strings(templates)
+	return templates, nil
+}
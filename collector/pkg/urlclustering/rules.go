@@ -0,0 +1,114 @@
+package urlclustering
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RewriteRule rewrites the endpoints matching Pattern into Replacement, which could refer to the
+// groups of the pattern like "$1" or "${name}".
+type RewriteRule struct {
+	Pattern     string `mapstructure:"pattern"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+// RuleSet clusters the endpoints with the user-defined rewrite rules and route templates, like
+// /v1/orders/{orderId}. The rewrite rules are tried in order before the route templates. The route
+// templates with more literal segments are preferred, so /v1/orders/search is not clustered into
+// /v1/orders/{orderId} if both are declared.
+// Only the path of the endpoint is matched, the query string is removed first.
+type RuleSet struct {
+	rewrites []*rewrite
+	routes   []*route
+}
+
+type rewrite struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+type route struct {
+	template string
+	segments []string
+	literals int
+}
+
+func NewRuleSet(rules []RewriteRule, templates []string) (*RuleSet, error) {
+	ruleSet := &RuleSet{
+		rewrites: make([]*rewrite, 0, len(rules)),
+		routes:   make([]*route, 0, len(templates)),
+	}
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite pattern %q: %w", rule.Pattern, err)
+		}
+		ruleSet.rewrites = append(ruleSet.rewrites, &rewrite{pattern: pattern, replacement: rule.Replacement})
+	}
+	for _, template := range templates {
+		ruleSet.routes = append(ruleSet.routes, newRoute(template))
+	}
+	sort.SliceStable(ruleSet.routes, func(i, j int) bool {
+		return ruleSet.routes[i].literals > ruleSet.routes[j].literals
+	})
+	return ruleSet, nil
+}
+
+func newRoute(template string) *route {
+	r := &route{template: template, segments: strings.Split(template, "/")}
+	for _, segment := range r.segments {
+		if !isTemplateParameter(segment) {
+			r.literals++
+		}
+	}
+	return r
+}
+
+// isTemplateParameter returns true if the segment is a path parameter like {orderId}.
+func isTemplateParameter(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
+
+func (r *route) match(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if isTemplateParameter(segment) {
+			if segments[i] == "" {
+				return false
+			}
+		} else if segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the clustering result of the endpoint and true, or false if no rule matches it.
+func (s *RuleSet) Match(endpoint string) (string, bool) {
+	endpoint = strings.TrimSpace(endpoint)
+	if index := strings.Index(endpoint, "?"); index != -1 {
+		endpoint = endpoint[:index]
+	}
+	if endpoint == "" {
+		return "", false
+	}
+	for _, r := range s.rewrites {
+		if r.pattern.MatchString(endpoint) {
+			return r.pattern.ReplaceAllString(endpoint, r.replacement), true
+		}
+	}
+	if len(s.routes) == 0 {
+		return "", false
+	}
+	segments := strings.Split(endpoint, "/")
+	for _, r := range s.routes {
+		if r.match(segments) {
+			return r.template, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,76 @@
+package urlclustering
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleSet_Match(t *testing.T) {
+	ruleSet, err := NewRuleSet([]RewriteRule{
+		{Pattern: `^/static/.*`, Replacement: "/static/*"},
+		{Pattern: `^/v2/users/[^/]+/(?P<action>\w+)$`, Replacement: "/v2/users/{userId}/${action}"},
+	}, []string{
+		"/v1/orders/{orderId}",
+		"/v1/orders/search",
+		"/v1/orders/{orderId}/items/{itemId}",
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		endpoint string
+		want     string
+		matched  bool
+	}{
+		{"/static/js/app.js", "/static/*", true},
+		{"/v2/users/john/orders?page=1", "/v2/users/{userId}/orders", true},
+		{"/v1/orders/1234", "/v1/orders/{orderId}", true},
+		{"/v1/orders/search?q=book", "/v1/orders/search", true},
+		{"/v1/orders/1234/items/5", "/v1/orders/{orderId}/items/{itemId}", true},
+		{"/v1/orders//items/5", "", false},
+		{"/v1/orders", "", false},
+		{"", "", false},
+	}
+	for _, c := range testCases {
+		got, matched := ruleSet.Match(c.endpoint)
+		assert.Equal(t, c.matched, matched, c.endpoint)
+		assert.Equal(t, c.want, got, c.endpoint)
+	}
+
+	_, err = NewRuleSet([]RewriteRule{{Pattern: "("}}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoadOpenAPITemplates(t *testing.T) {
+	dir := t.TempDir()
+	swagger := filepath.Join(dir, "swagger.json")
+	assert.NoError(t, os.WriteFile(swagger, []byte(`{
+  "swagger": "2.0",
+  "basePath": "/api/",
+  "paths": {
+    "/pets/{petId}": {"get": {}},
+    "/pets": {"get": {}, "post": {}}
+  }
+}`), 0644))
+	templates, err := LoadOpenAPITemplates(swagger)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/api/pets", "/api/pets/{petId}"}, templates)
+
+	openapi := filepath.Join(dir, "openapi.yaml")
+	assert.NoError(t, os.WriteFile(openapi, []byte(`openapi: 3.0.0
+servers:
+  - url: https://shop.example.com/v1
+paths:
+  /orders/{orderId}:
+    get:
+      summary: Get an order
+`), 0644))
+	templates, err = LoadOpenAPITemplates(openapi)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/v1/orders/{orderId}"}, templates)
+
+	_, err = LoadOpenAPITemplates(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}